@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Budget periods, in UTC.
+const (
+	BudgetPeriodDay   = "day"
+	BudgetPeriodMonth = "month"
+)
+
+// Budget caps what one token may use per calendar period (UTC), in tokens,
+// in USD under the gateway's price list, or both. A token past a soft limit
+// is still served, with a warning header; past a hard limit it gets 429
+// insufficient_quota until the period rolls over or an admin resets its
+// spend. A zero limit is not enforced.
+type Budget struct {
+	Period          string  `json:"period,omitempty"` // "day" or "month"; empty means month
+	SoftLimitTokens int64   `json:"soft_limit_tokens,omitempty"`
+	HardLimitTokens int64   `json:"hard_limit_tokens,omitempty"`
+	SoftLimitUSD    float64 `json:"soft_limit_usd,omitempty"`
+	HardLimitUSD    float64 `json:"hard_limit_usd,omitempty"`
+}
+
+func (b Budget) Validate() error {
+	switch b.Period {
+	case "", BudgetPeriodDay, BudgetPeriodMonth:
+	default:
+		return fmt.Errorf("period must be %q or %q, got %q", BudgetPeriodDay, BudgetPeriodMonth, b.Period)
+	}
+	if b.SoftLimitTokens < 0 || b.HardLimitTokens < 0 || b.SoftLimitUSD < 0 || b.HardLimitUSD < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if b.SoftLimitTokens == 0 && b.HardLimitTokens == 0 && b.SoftLimitUSD == 0 && b.HardLimitUSD == 0 {
+		return errors.New("at least one soft or hard limit is required")
+	}
+	if b.HardLimitTokens > 0 && b.SoftLimitTokens > b.HardLimitTokens {
+		return errors.New("soft_limit_tokens must not exceed hard_limit_tokens")
+	}
+	if b.HardLimitUSD > 0 && b.SoftLimitUSD > b.HardLimitUSD {
+		return errors.New("soft_limit_usd must not exceed hard_limit_usd")
+	}
+	return nil
+}
+
+// HasUSDLimit reports whether b limits spend in USD.
+func (b Budget) HasUSDLimit() bool {
+	return b.SoftLimitUSD > 0 || b.HardLimitUSD > 0
+}
+
+// PeriodOrDefault returns b's period, month when unset.
+func (b Budget) PeriodOrDefault() string {
+	if b.Period == "" {
+		return BudgetPeriodMonth
+	}
+	return b.Period
+}
@@ -3,7 +3,9 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"llm_gateway/auth"
 	pb "llm_gateway/auth/proto"
 	"llm_gateway/internal/discovery"
 
@@ -27,8 +29,8 @@ func NewClient(address string) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) Create(ctx context.Context, alias string) (string, error) {
-	resp, err := c.client.Create(ctx, &pb.CreateRequest{Alias: alias})
+func (c *Client) Create(ctx context.Context, info auth.TokenInfo) (string, error) {
+	resp, err := c.client.Create(ctx, &pb.CreateRequest{Alias: info.Alias, Info: toProtoTokenInfo(info)})
 	if err != nil {
 		return "", fmt.Errorf("auth service Create: %w", err)
 	}
@@ -38,15 +40,17 @@ func (c *Client) Create(ctx context.Context, alias string) (string, error) {
 	return resp.Token, nil
 }
 
-func (c *Client) Get(ctx context.Context, token string) (bool, string, error) {
+func (c *Client) Get(ctx context.Context, token string) (bool, auth.TokenInfo, error) {
 	resp, err := c.client.Get(ctx, &pb.GetRequest{Token: token})
 	if err != nil {
-		return false, "", fmt.Errorf("auth service Get: %w", err)
+		return false, auth.TokenInfo{}, fmt.Errorf("auth service Get: %w", err)
 	}
 	if resp.Error != "" {
-		return false, "", fmt.Errorf("auth service Get: %s", resp.Error)
+		return false, auth.TokenInfo{}, fmt.Errorf("auth service Get: %s", resp.Error)
 	}
-	return resp.Valid, resp.Alias, nil
+	info := fromProtoTokenInfo(resp.Info)
+	info.Alias = resp.Alias // servers that predate TokenInfo send only the alias
+	return resp.Valid, info, nil
 }
 
 func (c *Client) Delete(ctx context.Context, token string) error {
@@ -60,6 +64,102 @@ func (c *Client) Delete(ctx context.Context, token string) error {
 	return nil
 }
 
+func (c *Client) List(ctx context.Context, f auth.ListFilter) (auth.TokenPage, error) {
+	resp, err := c.client.List(ctx, &pb.ListRequest{
+		Alias:  f.Alias,
+		Labels: f.Labels,
+		Cursor: f.Cursor,
+		Limit:  int32(f.Limit),
+	})
+	if err != nil {
+		return auth.TokenPage{}, fmt.Errorf("auth service List: %w", err)
+	}
+	if resp.Error != "" {
+		return auth.TokenPage{}, fmt.Errorf("auth service List: %s", resp.Error)
+	}
+	page := auth.TokenPage{Tokens: make([]auth.TokenInfo, len(resp.Tokens)), NextCursor: resp.NextCursor}
+	for i, info := range resp.Tokens {
+		page.Tokens[i] = fromProtoTokenInfo(info)
+	}
+	return page, nil
+}
+
+func (c *Client) Rotate(ctx context.Context, id string, grace time.Duration) (string, auth.TokenInfo, error) {
+	// The wire carries whole seconds; truncating would turn "500ms" into an
+	// immediate revoke.
+	if grace%time.Second != 0 {
+		return "", auth.TokenInfo{}, fmt.Errorf("rotation grace must be whole seconds, got %s", grace)
+	}
+	resp, err := c.client.Rotate(ctx, &pb.RotateRequest{Id: id, GraceSeconds: int64(grace / time.Second)})
+	if err != nil {
+		return "", auth.TokenInfo{}, fmt.Errorf("auth service Rotate: %w", err)
+	}
+	if resp.NotFound {
+		return "", auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	if resp.Expired {
+		return "", auth.TokenInfo{}, auth.ErrTokenExpired
+	}
+	if resp.Error != "" {
+		// A replacement may exist even on error; pass it on.
+		return resp.Token, fromProtoTokenInfo(resp.Info), fmt.Errorf("auth service Rotate: %s", resp.Error)
+	}
+	return resp.Token, fromProtoTokenInfo(resp.Info), nil
+}
+
+func (c *Client) Revoke(ctx context.Context, id string) error {
+	resp, err := c.client.Revoke(ctx, &pb.RevokeRequest{Id: id})
+	if err != nil {
+		return fmt.Errorf("auth service Revoke: %w", err)
+	}
+	if resp.NotFound {
+		return auth.ErrTokenNotFound
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("auth service Revoke: %s", resp.Error)
+	}
+	return nil
+}
+
+func (c *Client) RevokeByAlias(ctx context.Context, alias string) (int, error) {
+	resp, err := c.client.RevokeByAlias(ctx, &pb.RevokeByAliasRequest{Alias: alias})
+	if err != nil {
+		return 0, fmt.Errorf("auth service RevokeByAlias: %w", err)
+	}
+	if resp.Error != "" {
+		return int(resp.Revoked), fmt.Errorf("auth service RevokeByAlias: %s", resp.Error)
+	}
+	return int(resp.Revoked), nil
+}
+
+func (c *Client) Lookup(ctx context.Context, id string) (auth.TokenInfo, error) {
+	resp, err := c.client.Lookup(ctx, &pb.LookupRequest{Id: id})
+	if err != nil {
+		return auth.TokenInfo{}, fmt.Errorf("auth service Lookup: %w", err)
+	}
+	if resp.NotFound {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	if resp.Error != "" {
+		return auth.TokenInfo{}, fmt.Errorf("auth service Lookup: %s", resp.Error)
+	}
+	return fromProtoTokenInfo(resp.Info), nil
+}
+
+func (c *Client) SetBudget(ctx context.Context, id string, budget *auth.Budget) (auth.TokenInfo, error) {
+	resp, err := c.client.SetBudget(ctx, &pb.SetBudgetRequest{Id: id, Budget: toProtoBudget(budget)})
+	if err != nil {
+		return auth.TokenInfo{}, fmt.Errorf("auth service SetBudget: %w", err)
+	}
+	if resp.NotFound {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	if resp.Error != "" {
+		return auth.TokenInfo{}, fmt.Errorf("auth service SetBudget: %s", resp.Error)
+	}
+	return fromProtoTokenInfo(resp.Info), nil
+}
+
 func (c *Client) Close() error {
 	return c.conn.Close()
 }
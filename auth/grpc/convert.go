@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"time"
+
+	"llm_gateway/auth"
+	pb "llm_gateway/auth/proto"
+)
+
+func toProtoTokenInfo(info auth.TokenInfo) *pb.TokenInfo {
+	return &pb.TokenInfo{
+		Alias:          info.Alias,
+		CreatedAtUnix:  unixOrZero(info.CreatedAt),
+		ExpiresAtUnix:  unixOrZero(info.ExpiresAt),
+		Scopes:         info.Scopes,
+		Models:         info.Models,
+		RagCollections: info.RAGCollections,
+		Labels:         info.Labels,
+		Prefix:         info.Prefix,
+		Id:             info.ID,
+		LastUsedAtUnix: unixOrZero(info.LastUsedAt),
+		Budget:         toProtoBudget(info.Budget),
+	}
+}
+
+func fromProtoTokenInfo(info *pb.TokenInfo) auth.TokenInfo {
+	if info == nil {
+		return auth.TokenInfo{}
+	}
+	return auth.TokenInfo{
+		Alias:          info.Alias,
+		CreatedAt:      timeOrZero(info.CreatedAtUnix),
+		ExpiresAt:      timeOrZero(info.ExpiresAtUnix),
+		Scopes:         info.Scopes,
+		Models:         info.Models,
+		RAGCollections: info.RagCollections,
+		Labels:         info.Labels,
+		Prefix:         info.Prefix,
+		ID:             info.Id,
+		LastUsedAt:     timeOrZero(info.LastUsedAtUnix),
+		Budget:         fromProtoBudget(info.Budget),
+	}
+}
+
+func toProtoBudget(b *auth.Budget) *pb.Budget {
+	if b == nil {
+		return nil
+	}
+	return &pb.Budget{
+		Period:          b.Period,
+		SoftLimitTokens: b.SoftLimitTokens,
+		HardLimitTokens: b.HardLimitTokens,
+		SoftLimitUsd:    b.SoftLimitUSD,
+		HardLimitUsd:    b.HardLimitUSD,
+	}
+}
+
+func fromProtoBudget(b *pb.Budget) *auth.Budget {
+	if b == nil {
+		return nil
+	}
+	return &auth.Budget{
+		Period:          b.Period,
+		SoftLimitTokens: b.SoftLimitTokens,
+		HardLimitTokens: b.HardLimitTokens,
+		SoftLimitUSD:    b.SoftLimitUsd,
+		HardLimitUSD:    b.HardLimitUsd,
+	}
+}
+
+// unixOrZero keeps the zero time as 0 on the wire rather than year 1.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(unix int64) time.Time {
+	if unix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0).UTC()
+}
@@ -2,6 +2,8 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"llm_gateway/auth"
 	pb "llm_gateway/auth/proto"
@@ -19,7 +21,9 @@ func NewServer(authSerice auth.Service) *Server {
 }
 
 func (s *Server) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
-	token, err := s.authService.Create(ctx, req.Alias)
+	info := fromProtoTokenInfo(req.Info)
+	info.Alias = req.Alias
+	token, err := s.authService.Create(ctx, info)
 	if err != nil {
 		return &pb.CreateResponse{Error: err.Error()}, nil
 	}
@@ -27,11 +31,14 @@ func (s *Server) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateR
 }
 
 func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
-	valid, alias, err := s.authService.Get(ctx, req.Token)
+	valid, info, err := s.authService.Get(ctx, req.Token)
 	if err != nil {
 		return &pb.GetResponse{Error: err.Error()}, nil
 	}
-	return &pb.GetResponse{Valid: valid, Alias: alias}, nil
+	if !valid {
+		return &pb.GetResponse{}, nil
+	}
+	return &pb.GetResponse{Valid: true, Alias: info.Alias, Info: toProtoTokenInfo(info)}, nil
 }
 
 func (s *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
@@ -40,3 +47,65 @@ func (s *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteR
 	}
 	return &pb.DeleteResponse{}, nil
 }
+
+func (s *Server) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	page, err := s.authService.List(ctx, auth.ListFilter{
+		Alias:  req.Alias,
+		Labels: req.Labels,
+		Cursor: req.Cursor,
+		Limit:  int(req.Limit),
+	})
+	if err != nil {
+		return &pb.ListResponse{Error: err.Error()}, nil
+	}
+	tokens := make([]*pb.TokenInfo, len(page.Tokens))
+	for i, info := range page.Tokens {
+		tokens[i] = toProtoTokenInfo(info)
+	}
+	return &pb.ListResponse{Tokens: tokens, NextCursor: page.NextCursor}, nil
+}
+
+func (s *Server) Rotate(ctx context.Context, req *pb.RotateRequest) (*pb.RotateResponse, error) {
+	token, info, err := s.authService.Rotate(ctx, req.Id, time.Duration(req.GraceSeconds)*time.Second)
+	resp := &pb.RotateResponse{Token: token}
+	if token != "" {
+		resp.Info = toProtoTokenInfo(info)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		resp.NotFound = errors.Is(err, auth.ErrTokenNotFound)
+		resp.Expired = errors.Is(err, auth.ErrTokenExpired)
+	}
+	return resp, nil
+}
+
+func (s *Server) Revoke(ctx context.Context, req *pb.RevokeRequest) (*pb.RevokeResponse, error) {
+	if err := s.authService.Revoke(ctx, req.Id); err != nil {
+		return &pb.RevokeResponse{Error: err.Error(), NotFound: errors.Is(err, auth.ErrTokenNotFound)}, nil
+	}
+	return &pb.RevokeResponse{}, nil
+}
+
+func (s *Server) RevokeByAlias(ctx context.Context, req *pb.RevokeByAliasRequest) (*pb.RevokeByAliasResponse, error) {
+	n, err := s.authService.RevokeByAlias(ctx, req.Alias)
+	if err != nil {
+		return &pb.RevokeByAliasResponse{Revoked: int64(n), Error: err.Error()}, nil
+	}
+	return &pb.RevokeByAliasResponse{Revoked: int64(n)}, nil
+}
+
+func (s *Server) Lookup(ctx context.Context, req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	info, err := s.authService.Lookup(ctx, req.Id)
+	if err != nil {
+		return &pb.LookupResponse{Error: err.Error(), NotFound: errors.Is(err, auth.ErrTokenNotFound)}, nil
+	}
+	return &pb.LookupResponse{Info: toProtoTokenInfo(info)}, nil
+}
+
+func (s *Server) SetBudget(ctx context.Context, req *pb.SetBudgetRequest) (*pb.SetBudgetResponse, error) {
+	info, err := s.authService.SetBudget(ctx, req.Id, fromProtoBudget(req.Budget))
+	if err != nil {
+		return &pb.SetBudgetResponse{Error: err.Error(), NotFound: errors.Is(err, auth.ErrTokenNotFound)}, nil
+	}
+	return &pb.SetBudgetResponse{Info: toProtoTokenInfo(info)}, nil
+}
@@ -1,9 +1,167 @@
 package auth
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Scopes a token can hold.
+const (
+	ScopeChat       = "chat"       // /v1/chat/completions
+	ScopeEmbeddings = "embeddings" // /v1/embeddings
+	ScopeAdminRead  = "admin-read" // read-only admin routes
+)
+
+// DefaultScopes apply to a token created without any, including every token
+// minted before scopes existed.
+var DefaultScopes = []string{ScopeChat, ScopeEmbeddings}
+
+// TokenInfo is what a token grants. Empty Models and RAGCollections mean
+// unrestricted; "*" in either list allows anything.
+type TokenInfo struct {
+	Alias          string            `json:"alias"`
+	CreatedAt      time.Time         `json:"created_at,omitzero"`
+	ExpiresAt      time.Time         `json:"expires_at,omitzero"` // zero = never
+	Scopes         []string          `json:"scopes,omitempty"`    // empty = DefaultScopes
+	Models         []string          `json:"models,omitempty"`
+	RAGCollections []string          `json:"rag_collections,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Budget         *Budget           `json:"budget,omitempty"` // nil = no spend limit
+	// Prefix is the start of the token, kept in clear so a token can be
+	// recognised in listings without storing it. Set by the service.
+	Prefix string `json:"prefix,omitempty"`
+	// ID names the token in List, Rotate and Revoke without revealing it.
+	// Set by the service.
+	ID string `json:"id,omitempty"`
+	// LastUsedAt is when the token last authenticated a request, to within
+	// a minute. Zero if never. Set by the service.
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+}
+
+// Validate rejects unknown scopes, a missing alias and an invalid budget.
+func (t TokenInfo) Validate() error {
+	if t.Alias == "" {
+		return errors.New("alias required")
+	}
+	for _, s := range t.Scopes {
+		switch s {
+		case ScopeChat, ScopeEmbeddings, ScopeAdminRead:
+		default:
+			return fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	if t.Budget != nil {
+		if err := t.Budget.Validate(); err != nil {
+			return fmt.Errorf("budget: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t TokenInfo) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+func (t TokenInfo) HasScope(scope string) bool {
+	if len(t.Scopes) == 0 {
+		return slices.Contains(DefaultScopes, scope)
+	}
+	return slices.Contains(t.Scopes, scope)
+}
+
+func (t TokenInfo) AllowsModel(model string) bool {
+	return allows(t.Models, model)
+}
+
+func (t TokenInfo) AllowsRAGCollection(collection string) bool {
+	return allows(t.RAGCollections, collection)
+}
+
+func allows(list []string, v string) bool {
+	return len(list) == 0 || slices.Contains(list, "*") || slices.Contains(list, v)
+}
+
+// ErrTokenNotFound is returned by Rotate, Revoke, Lookup and SetBudget for an
+// unknown token ID.
+var ErrTokenNotFound = errors.New("token not found")
+
+// ErrTokenExpired is returned by Rotate for a token already past its expiry;
+// there is nothing left to hand over, so create a new token instead.
+var ErrTokenExpired = errors.New("token expired")
+
+// DefaultRotationGrace is how long a rotated token keeps working when the
+// caller does not say.
+const DefaultRotationGrace = 24 * time.Hour
+
+// ListFilter selects tokens for List. Empty Alias and Labels match every
+// token; each label must match exactly. Cursor is the NextCursor of the
+// previous page, empty for the first.
+type ListFilter struct {
+	Alias  string
+	Labels map[string]string
+	Cursor string
+	Limit  int
+}
+
+func (f ListFilter) Matches(t TokenInfo) bool {
+	if f.Alias != "" && t.Alias != f.Alias {
+		return false
+	}
+	for k, v := range f.Labels {
+		if t.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// TokenPage is one page of List, oldest token first. NextCursor is empty on
+// the last page.
+type TokenPage struct {
+	Tokens     []TokenInfo `json:"tokens"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Errors a Provider wraps when it turns a credential away; any other error
+// means the provider itself failed.
+var (
+	ErrInvalidCredential = errors.New("invalid credential")
+	ErrCredentialExpired = errors.New("credential expired")
+)
+
+// Provider authenticates bearer credentials minted outside this service,
+// such as OIDC access tokens, alongside API tokens.
+type Provider interface {
+	// Name identifies the provider in logs.
+	Name() string
+	// Recognizes reports, without verifying it, whether token is in the
+	// provider's format.
+	Recognizes(token string) bool
+	// Authenticate verifies token and returns what it grants.
+	Authenticate(ctx context.Context, token string) (TokenInfo, error)
+}
 
 type Service interface {
-	Create(ctx context.Context, alias string) (token string, err error)
-	Get(ctx context.Context, token string) (valide bool, alias string, err error)
+	// Create mints a token granting info; CreatedAt is set by the service.
+	Create(ctx context.Context, info TokenInfo) (token string, err error)
+	// Get returns valid=false for unknown tokens. Expired tokens are still
+	// returned, valid, so callers can tell expiry from revocation.
+	Get(ctx context.Context, token string) (valid bool, info TokenInfo, err error)
 	Delete(ctx context.Context, token string) error
+	List(ctx context.Context, f ListFilter) (TokenPage, error)
+	// Rotate mints a replacement for the token with id, granting the same,
+	// and lets the old token work for grace more (immediately revoked when
+	// grace <= 0). The old token's own expiry is kept if sooner.
+	Rotate(ctx context.Context, id string, grace time.Duration) (token string, info TokenInfo, err error)
+	Revoke(ctx context.Context, id string) error
+	// Lookup returns the token with id.
+	Lookup(ctx context.Context, id string) (TokenInfo, error)
+	// SetBudget replaces the budget of the token with id, or removes it when
+	// budget is nil, and returns the token as updated.
+	SetBudget(ctx context.Context, id string, budget *Budget) (TokenInfo, error)
+	// RevokeByAlias revokes every token of alias and returns how many.
+	RevokeByAlias(ctx context.Context, alias string) (int, error)
 }
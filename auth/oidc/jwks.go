@@ -0,0 +1,267 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefetchInterval bounds how often a token with an unknown key ID can
+	// make the key set be fetched again.
+	minRefetchInterval = time.Minute
+	jwksFetchTimeout   = 10 * time.Second
+	maxJWKSBytes       = 1 << 20
+)
+
+// errKeysUnavailable marks a key set that could not be loaded at all, as
+// opposed to a token naming a key the set does not hold.
+var errKeysUnavailable = errors.New("jwks unavailable")
+
+// keySet holds the signing keys of a JWKS by key ID. Keys are refetched when
+// older than refresh, and early when a token names a key the set lacks, as
+// happens right after the IdP rotates its keys.
+type keySet struct {
+	load    func(context.Context) ([]byte, error)
+	refresh time.Duration // 0 = load once
+	now     func() time.Time
+
+	fetchMu sync.Mutex // held while fetching
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newFileKeySet(path string) *keySet {
+	return &keySet{
+		load: func(context.Context) ([]byte, error) { return os.ReadFile(path) },
+		now:  time.Now,
+	}
+}
+
+func newURLKeySet(url string, refresh time.Duration) *keySet {
+	client := &http.Client{Timeout: jwksFetchTimeout}
+	return &keySet{
+		load: func(ctx context.Context) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+			}
+			return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+		},
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+// fetch replaces the keys with a fresh copy of the set.
+func (s *keySet) fetch(ctx context.Context) error {
+	raw, err := s.load(ctx)
+	if err != nil {
+		return fmt.Errorf("load jwks: %w", err)
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys, s.fetched = keys, s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// key returns the key named kid. An empty kid matches a set of one key.
+func (s *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k, found, age := s.lookup(kid)
+	stale := s.refresh > 0 && age >= s.refresh
+	if found && !stale {
+		return k, nil
+	}
+	if found {
+		// Serve the current key at once; the first request to see it stale
+		// starts a refresh in the background.
+		if s.fetchMu.TryLock() {
+			go s.refreshStale(context.WithoutCancel(ctx))
+		}
+		return k, nil
+	}
+
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	// Another request may have fetched while this one waited.
+	if k, found, age = s.lookup(kid); found {
+		return k, nil
+	}
+	if loaded := s.loaded(); !loaded || age >= minRefetchInterval {
+		if err := s.fetch(ctx); err != nil {
+			if !loaded {
+				return nil, fmt.Errorf("%w: %w", errKeysUnavailable, err)
+			}
+			slog.WarnContext(ctx, "jwks refetch for unknown key failed", "kid", kid, "err", err)
+		}
+		if k, found, _ = s.lookup(kid); found {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refreshStale refetches the set for key, which holds fetchMu for it. ctx is
+// detached from the request, so a client disconnecting does not abort the
+// refresh, and bounded by jwksFetchTimeout.
+func (s *keySet) refreshStale(ctx context.Context) {
+	defer s.fetchMu.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	if err := s.fetch(ctx); err != nil {
+		slog.WarnContext(ctx, "jwks refresh failed, keeping previous keys", "err", err)
+	}
+}
+
+func (s *keySet) loaded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys != nil
+}
+
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	age := s.now().Sub(s.fetched)
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true, age
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok, age
+}
+
+// jwk is one JSON Web Key (RFC 7517); only the members needed to build a
+// public key are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signature keys of a JWKS document. Encryption keys
+// and key types or curves it cannot use are skipped; a malformed key or a
+// set with no usable key is an error.
+func parseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse jwks: key %q: %w", k.Kid, err)
+		}
+		if pub != nil {
+			keys[k.Kid] = pub
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("parse jwks: no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey returns nil, nil for a key type or curve it does not handle.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("e out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("x: bad Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
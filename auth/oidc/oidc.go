@@ -0,0 +1,262 @@
+// Package oidc authenticates JWT bearer tokens, such as OIDC access tokens,
+// against an identity provider's published keys (JWKS).
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm_gateway/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultJWKSRefresh   = time.Hour
+	defaultLeeway        = 30 * time.Second
+	defaultSubjectClaim  = "sub"
+	defaultGroupsClaim   = "groups"
+	defaultSubjectPrefix = "oidc:"
+	groupsLabel          = "groups"
+	providerName         = "oidc"
+	compactJWTHeaderStem = "eyJ" // base64url of `{"`
+)
+
+// defaultAlgorithms are the asymmetric JWS algorithms. Shared-secret (HS*)
+// and "none" tokens are never accepted.
+var defaultAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Config says which tokens to trust and how their claims map onto an
+// auth.TokenInfo. Exactly one of JWKSFile and JWKSURL is required.
+type Config struct {
+	Issuer    string   `json:"issuer"`
+	Audiences []string `json:"audiences"` // the token's aud must name one of them
+	JWKSFile  string   `json:"jwks_file,omitempty"`
+	JWKSURL   string   `json:"jwks_url,omitempty"`
+	// JWKSRefresh is how often a JWKS URL is refetched, e.g. "1h" (the
+	// default). The file is read once at startup.
+	JWKSRefresh string `json:"jwks_refresh,omitempty"`
+	// Algorithms narrows the accepted signing algorithms; default every
+	// asymmetric one.
+	Algorithms []string `json:"algorithms,omitempty"`
+	// Leeway absorbs clock skew in exp, nbf and iat, e.g. "30s" (the default).
+	Leeway string       `json:"leeway,omitempty"`
+	Claims ClaimMapping `json:"claims"`
+}
+
+// ClaimMapping names the claims read from a verified token. A claim name is
+// looked up as-is first, then as a dotted path into nested objects, so both
+// "https://example.com/tier" and "realm_access.roles" work.
+type ClaimMapping struct {
+	// Subject becomes the alias that rate limits and usage are keyed by.
+	// Default "sub".
+	Subject string `json:"subject,omitempty"`
+	// SubjectPrefix is prepended to the subject, keeping IdP subjects apart
+	// from API-token aliases: without it a subject equal to an alias would
+	// share that alias's rate limits and usage. Default "oidc:";
+	// it cannot be empty.
+	SubjectPrefix string `json:"subject_prefix,omitempty"`
+	// Groups fills the "groups" label, comma-joined. Default "groups".
+	Groups string `json:"groups,omitempty"`
+	// Labels maps a label name to the claim holding its value, e.g.
+	// {"quota_tier": "tier"}. Lists are comma-joined.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Provider is an auth.Provider for JWTs signed by the configured issuer.
+// Verified tokens get auth.DefaultScopes and no model or RAG limits.
+type Provider struct {
+	cfg    Config
+	keys   *keySet
+	parser *jwt.Parser
+}
+
+// NewProvider checks cfg and loads the JWKS, failing if it cannot be read:
+// a gateway that cannot verify any token should not start.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oidc: issuer required")
+	}
+	if len(cfg.Audiences) == 0 {
+		return nil, errors.New("oidc: at least one audience required")
+	}
+	refresh, err := parseDuration("jwks_refresh", cfg.JWKSRefresh, defaultJWKSRefresh)
+	if err != nil {
+		return nil, err
+	}
+	leeway, err := parseDuration("leeway", cfg.Leeway, defaultLeeway)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = defaultAlgorithms
+	}
+	for _, alg := range cfg.Algorithms {
+		if !slices.Contains(defaultAlgorithms, alg) {
+			return nil, fmt.Errorf("oidc: algorithm %q not supported", alg)
+		}
+	}
+	if cfg.Claims.Subject == "" {
+		cfg.Claims.Subject = defaultSubjectClaim
+	}
+	if cfg.Claims.SubjectPrefix == "" {
+		cfg.Claims.SubjectPrefix = defaultSubjectPrefix
+	}
+	if cfg.Claims.Groups == "" {
+		cfg.Claims.Groups = defaultGroupsClaim
+	}
+
+	var keys *keySet
+	switch {
+	case cfg.JWKSFile != "" && cfg.JWKSURL != "":
+		return nil, errors.New("oidc: set only one of jwks_file and jwks_url")
+	case cfg.JWKSFile != "":
+		keys = newFileKeySet(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		keys = newURLKeySet(cfg.JWKSURL, refresh)
+	default:
+		return nil, errors.New("oidc: jwks_file or jwks_url required")
+	}
+	if err := keys.fetch(ctx); err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+
+	return &Provider{
+		cfg:  cfg,
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(cfg.Algorithms),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audiences...),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(leeway),
+		),
+	}, nil
+}
+
+func parseDuration(field, raw string, def time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("oidc: %s must be a non-negative duration, got %q", field, raw)
+	}
+	return d, nil
+}
+
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// Recognizes matches the compact JWS form: three dot-separated parts, the
+// first a base64url JSON object.
+func (p *Provider) Recognizes(token string) bool {
+	return strings.Count(token, ".") == 2 && strings.HasPrefix(token, compactJWTHeaderStem)
+}
+
+func (p *Provider) Authenticate(ctx context.Context, token string) (auth.TokenInfo, error) {
+	claims := jwt.MapClaims{}
+	_, err := p.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	})
+	switch {
+	case errors.Is(err, errKeysUnavailable):
+		return auth.TokenInfo{}, fmt.Errorf("oidc: %w", err)
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return auth.TokenInfo{}, fmt.Errorf("%w: %w", auth.ErrCredentialExpired, err)
+	case err != nil:
+		return auth.TokenInfo{}, fmt.Errorf("%w: %w", auth.ErrInvalidCredential, err)
+	}
+	return p.tokenInfo(claims)
+}
+
+// tokenInfo maps verified claims onto what the token grants.
+func (p *Provider) tokenInfo(claims jwt.MapClaims) (auth.TokenInfo, error) {
+	m := p.cfg.Claims
+	subject := claimString(claims, m.Subject)
+	if subject == "" {
+		return auth.TokenInfo{}, fmt.Errorf("%w: claim %q missing or empty", auth.ErrInvalidCredential, m.Subject)
+	}
+	info := auth.TokenInfo{Alias: m.SubjectPrefix + subject}
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		info.ExpiresAt = exp.Time
+	}
+	if iat, _ := claims.GetIssuedAt(); iat != nil {
+		info.CreatedAt = iat.Time
+	}
+
+	labels := make(map[string]string, len(m.Labels)+1)
+	if groups := claimString(claims, m.Groups); groups != "" {
+		labels[groupsLabel] = groups
+	}
+	for label, claim := range m.Labels {
+		if v := claimString(claims, claim); v != "" {
+			labels[label] = v
+		}
+	}
+	if len(labels) > 0 {
+		info.Labels = labels
+	}
+	return info, nil
+}
+
+// claimString renders a claim as a string, lists comma-joined. Objects and
+// missing claims give "".
+func claimString(claims jwt.MapClaims, name string) string {
+	v, ok := claims[name]
+	if !ok {
+		v, ok = claimPath(claims, name)
+	}
+	if !ok {
+		return ""
+	}
+	if list, ok := v.([]any); ok {
+		parts := make([]string, 0, len(list))
+		for _, e := range list {
+			if s := scalarString(e); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ",")
+	}
+	return scalarString(v)
+}
+
+func claimPath(claims map[string]any, path string) (any, bool) {
+	var v any = claims
+	for part := range strings.SplitSeq(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
@@ -0,0 +1,294 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/"
+	testAudience = "llm-gateway"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// jwksJSON publishes keys by kid.
+func jwksJSON(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+	var doc struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, k := range keys {
+		doc.Keys = append(doc.Keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, keys), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": []string{"other", testAudience},
+		"sub": "alice",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestProvider(t *testing.T, cfg Config) *Provider {
+	t.Helper()
+	cfg.Issuer, cfg.Audiences = testIssuer, []string{testAudience}
+	p, err := NewProvider(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestProvider_MapsClaims(t *testing.T) {
+	key := newTestKey(t)
+	p := newTestProvider(t, Config{
+		JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key}),
+		Claims: ClaimMapping{
+			SubjectPrefix: "idp/",
+			Labels:        map[string]string{"quota_tier": "quota.tier", "tenant": "https://example.com/tenant"},
+		},
+	})
+	claims := validClaims()
+	claims["groups"] = []string{"ml", "infra"}
+	claims["quota"] = map[string]any{"tier": "gold"}
+	claims["https://example.com/tenant"] = 42
+
+	info, err := p.Authenticate(context.Background(), sign(t, key, "k1", claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Alias != "idp/alice" {
+		t.Errorf("alias: got %q", info.Alias)
+	}
+	want := map[string]string{"groups": "ml,infra", "quota_tier": "gold", "tenant": "42"}
+	for k, v := range want {
+		if info.Labels[k] != v {
+			t.Errorf("label %s: got %q, want %q", k, info.Labels[k], v)
+		}
+	}
+	if info.ExpiresAt.IsZero() || !info.HasScope(auth.ScopeChat) || info.HasScope(auth.ScopeAdminRead) {
+		t.Errorf("info: got %+v", info)
+	}
+}
+
+// A subject equal to an API-token alias must not share that alias's limits,
+// budgets and usage.
+func TestProvider_SubjectPrefixedByDefault(t *testing.T) {
+	key := newTestKey(t)
+	p := newTestProvider(t, Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key})})
+
+	info, err := p.Authenticate(context.Background(), sign(t, key, "k1", validClaims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Alias != "oidc:alice" {
+		t.Errorf("alias: got %q, want %q so it cannot collide with API-token alias %q", info.Alias, "oidc:alice", "alice")
+	}
+}
+
+func TestProvider_Rejects(t *testing.T) {
+	key, other := newTestKey(t), newTestKey(t)
+	p := newTestProvider(t, Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key})})
+
+	with := func(k string, v any) jwt.MapClaims {
+		c := validClaims()
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+		return c
+	}
+	hs256, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString(key.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"wrong issuer", sign(t, key, "k1", with("iss", "https://evil.example.com/")), auth.ErrInvalidCredential},
+		{"wrong audience", sign(t, key, "k1", with("aud", "someone-else")), auth.ErrInvalidCredential},
+		{"expired", sign(t, key, "k1", with("exp", time.Now().Add(-time.Hour).Unix())), auth.ErrCredentialExpired},
+		{"no expiry", sign(t, key, "k1", with("exp", nil)), auth.ErrInvalidCredential},
+		{"no subject", sign(t, key, "k1", with("sub", nil)), auth.ErrInvalidCredential},
+		{"wrong key", sign(t, other, "k1", validClaims()), auth.ErrInvalidCredential},
+		{"unknown kid", sign(t, other, "k2", validClaims()), auth.ErrInvalidCredential},
+		{"shared secret", hs256, auth.ErrInvalidCredential},
+	}
+	for _, c := range cases {
+		if _, err := p.Authenticate(context.Background(), c.token); !errors.Is(err, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestProvider_RefetchesURLForUnknownKey(t *testing.T) {
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+	var published atomic.Value
+	published.Store(jwksJSON(t, map[string]*rsa.PrivateKey{"old": oldKey}))
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(published.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t, Config{JWKSURL: srv.URL})
+	now := time.Now()
+	p.keys.now = func() time.Time { return now }
+
+	// The IdP rotates its key; a token signed with it arrives at once.
+	published.Store(jwksJSON(t, map[string]*rsa.PrivateKey{"old": oldKey, "new": newKey}))
+	token := sign(t, newKey, "new", validClaims())
+	if _, err := p.Authenticate(context.Background(), token); !errors.Is(err, auth.ErrInvalidCredential) {
+		t.Fatalf("refetch must be rate limited: got %v", err)
+	}
+
+	now = now.Add(minRefetchInterval)
+	if _, err := p.Authenticate(context.Background(), token); err != nil {
+		t.Fatalf("after refetch: %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches: got %d, want 2", n)
+	}
+}
+
+func TestProvider_StaleKeysRefreshInBackground(t *testing.T) {
+	key := newTestKey(t)
+	jwks := jwksJSON(t, map[string]*rsa.PrivateKey{"k1": key})
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release // the IdP is slow to answer the refresh
+		}
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	p := newTestProvider(t, Config{JWKSURL: srv.URL, JWKSRefresh: "1h"})
+	now := time.Now().Add(2 * time.Hour)
+	p.keys.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Authenticate(ctx, sign(t, key, "k1", validClaims()))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("stale key: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request waited for the JWKS refresh")
+	}
+	cancel() // the client goes away; the refresh must carry on
+
+	release <- struct{}{}
+	age := func() time.Duration {
+		_, _, age := p.keys.lookup("k1")
+		return age
+	}
+	deadline := time.Now().Add(time.Second)
+	for age() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if age := age(); age != 0 {
+		t.Errorf("keys not refreshed: age %v", age)
+	}
+}
+
+func TestProvider_Recognizes(t *testing.T) {
+	key := newTestKey(t)
+	p := newTestProvider(t, Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key})})
+
+	if !p.Recognizes(sign(t, key, "k1", validClaims())) {
+		t.Error("JWT not recognized")
+	}
+	if p.Recognizes("sk-0123456789abcdef") {
+		t.Error("API token recognized")
+	}
+}
+
+func TestNewProvider_ConfigErrors(t *testing.T) {
+	jwks := writeJWKS(t, map[string]*rsa.PrivateKey{"k1": newTestKey(t)})
+	cases := map[string]Config{
+		"no issuer":    {Audiences: []string{testAudience}, JWKSFile: jwks},
+		"no audience":  {Issuer: testIssuer, JWKSFile: jwks},
+		"no jwks":      {Issuer: testIssuer, Audiences: []string{testAudience}},
+		"both jwks":    {Issuer: testIssuer, Audiences: []string{testAudience}, JWKSFile: jwks, JWKSURL: "http://idp"},
+		"hmac":         {Issuer: testIssuer, Audiences: []string{testAudience}, JWKSFile: jwks, Algorithms: []string{"HS256"}},
+		"bad leeway":   {Issuer: testIssuer, Audiences: []string{testAudience}, JWKSFile: jwks, Leeway: "soon"},
+		"missing file": {Issuer: testIssuer, Audiences: []string{testAudience}, JWKSFile: jwks + ".gone"},
+	}
+	for name, cfg := range cases {
+		if _, err := NewProvider(context.Background(), cfg); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
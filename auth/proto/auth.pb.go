@@ -21,16 +21,221 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// TokenInfo is what a token grants. Empty lists mean unrestricted; empty
+// scopes mean the default scopes (chat, embeddings).
+type TokenInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Alias          string                 `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	CreatedAtUnix  int64                  `protobuf:"varint,2,opt,name=created_at_unix,json=createdAtUnix,proto3" json:"created_at_unix,omitempty"`
+	ExpiresAtUnix  int64                  `protobuf:"varint,3,opt,name=expires_at_unix,json=expiresAtUnix,proto3" json:"expires_at_unix,omitempty"` // 0 = never
+	Scopes         []string               `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Models         []string               `protobuf:"bytes,5,rep,name=models,proto3" json:"models,omitempty"`
+	RagCollections []string               `protobuf:"bytes,6,rep,name=rag_collections,json=ragCollections,proto3" json:"rag_collections,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Prefix         string                 `protobuf:"bytes,8,opt,name=prefix,proto3" json:"prefix,omitempty"`                                             // first characters of the token, for display; set by the service
+	Id             string                 `protobuf:"bytes,9,opt,name=id,proto3" json:"id,omitempty"`                                                     // names the token in List, Rotate and Revoke; set by the service
+	LastUsedAtUnix int64                  `protobuf:"varint,10,opt,name=last_used_at_unix,json=lastUsedAtUnix,proto3" json:"last_used_at_unix,omitempty"` // 0 = never; set by the service
+	Budget         *Budget                `protobuf:"bytes,11,opt,name=budget,proto3" json:"budget,omitempty"`                                            // absent = no spend limit
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TokenInfo) Reset() {
+	*x = TokenInfo{}
+	mi := &file_auth_proto_auth_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenInfo) ProtoMessage() {}
+
+func (x *TokenInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenInfo.ProtoReflect.Descriptor instead.
+func (*TokenInfo) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenInfo) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetCreatedAtUnix() int64 {
+	if x != nil {
+		return x.CreatedAtUnix
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetExpiresAtUnix() int64 {
+	if x != nil {
+		return x.ExpiresAtUnix
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *TokenInfo) GetModels() []string {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+func (x *TokenInfo) GetRagCollections() []string {
+	if x != nil {
+		return x.RagCollections
+	}
+	return nil
+}
+
+func (x *TokenInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TokenInfo) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetLastUsedAtUnix() int64 {
+	if x != nil {
+		return x.LastUsedAtUnix
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetBudget() *Budget {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+// Budget caps a token's spend per UTC day or month. A zero limit is not
+// enforced.
+type Budget struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Period          string                 `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"` // "day" or "month"; empty = month
+	SoftLimitTokens int64                  `protobuf:"varint,2,opt,name=soft_limit_tokens,json=softLimitTokens,proto3" json:"soft_limit_tokens,omitempty"`
+	HardLimitTokens int64                  `protobuf:"varint,3,opt,name=hard_limit_tokens,json=hardLimitTokens,proto3" json:"hard_limit_tokens,omitempty"`
+	SoftLimitUsd    float64                `protobuf:"fixed64,4,opt,name=soft_limit_usd,json=softLimitUsd,proto3" json:"soft_limit_usd,omitempty"`
+	HardLimitUsd    float64                `protobuf:"fixed64,5,opt,name=hard_limit_usd,json=hardLimitUsd,proto3" json:"hard_limit_usd,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Budget) Reset() {
+	*x = Budget{}
+	mi := &file_auth_proto_auth_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Budget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Budget) ProtoMessage() {}
+
+func (x *Budget) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Budget.ProtoReflect.Descriptor instead.
+func (*Budget) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Budget) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Budget) GetSoftLimitTokens() int64 {
+	if x != nil {
+		return x.SoftLimitTokens
+	}
+	return 0
+}
+
+func (x *Budget) GetHardLimitTokens() int64 {
+	if x != nil {
+		return x.HardLimitTokens
+	}
+	return 0
+}
+
+func (x *Budget) GetSoftLimitUsd() float64 {
+	if x != nil {
+		return x.SoftLimitUsd
+	}
+	return 0
+}
+
+func (x *Budget) GetHardLimitUsd() float64 {
+	if x != nil {
+		return x.HardLimitUsd
+	}
+	return 0
+}
+
 type CreateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Alias         string                 `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Info          *TokenInfo             `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"` // optional; its alias is ignored in favour of the field above
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateRequest) Reset() {
 	*x = CreateRequest{}
-	mi := &file_auth_proto_auth_proto_msgTypes[0]
+	mi := &file_auth_proto_auth_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -42,7 +247,7 @@ func (x *CreateRequest) String() string {
 func (*CreateRequest) ProtoMessage() {}
 
 func (x *CreateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[0]
+	mi := &file_auth_proto_auth_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,7 +260,7 @@ func (x *CreateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
 func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{0}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CreateRequest) GetAlias() string {
@@ -65,6 +270,13 @@ func (x *CreateRequest) GetAlias() string {
 	return ""
 }
 
+func (x *CreateRequest) GetInfo() *TokenInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
 type CreateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -75,7 +287,7 @@ type CreateResponse struct {
 
 func (x *CreateResponse) Reset() {
 	*x = CreateResponse{}
-	mi := &file_auth_proto_auth_proto_msgTypes[1]
+	mi := &file_auth_proto_auth_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -87,7 +299,7 @@ func (x *CreateResponse) String() string {
 func (*CreateResponse) ProtoMessage() {}
 
 func (x *CreateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[1]
+	mi := &file_auth_proto_auth_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -100,7 +312,7 @@ func (x *CreateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
 func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{1}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CreateResponse) GetToken() string {
@@ -126,7 +338,7 @@ type GetRequest struct {
 
 func (x *GetRequest) Reset() {
 	*x = GetRequest{}
-	mi := &file_auth_proto_auth_proto_msgTypes[2]
+	mi := &file_auth_proto_auth_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -138,7 +350,7 @@ func (x *GetRequest) String() string {
 func (*GetRequest) ProtoMessage() {}
 
 func (x *GetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[2]
+	mi := &file_auth_proto_auth_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -151,7 +363,7 @@ func (x *GetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
 func (*GetRequest) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{2}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetRequest) GetToken() string {
@@ -166,13 +378,14 @@ type GetResponse struct {
 	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
 	Alias         string                 `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
 	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Info          *TokenInfo             `protobuf:"bytes,4,opt,name=info,proto3" json:"info,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetResponse) Reset() {
 	*x = GetResponse{}
-	mi := &file_auth_proto_auth_proto_msgTypes[3]
+	mi := &file_auth_proto_auth_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -184,7 +397,7 @@ func (x *GetResponse) String() string {
 func (*GetResponse) ProtoMessage() {}
 
 func (x *GetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[3]
+	mi := &file_auth_proto_auth_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -197,7 +410,7 @@ func (x *GetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
 func (*GetResponse) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{3}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetResponse) GetValid() bool {
@@ -221,6 +434,13 @@ func (x *GetResponse) GetError() string {
 	return ""
 }
 
+func (x *GetResponse) GetInfo() *TokenInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
 type DeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -230,7 +450,7 @@ type DeleteRequest struct {
 
 func (x *DeleteRequest) Reset() {
 	*x = DeleteRequest{}
-	mi := &file_auth_proto_auth_proto_msgTypes[4]
+	mi := &file_auth_proto_auth_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -242,7 +462,7 @@ func (x *DeleteRequest) String() string {
 func (*DeleteRequest) ProtoMessage() {}
 
 func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[4]
+	mi := &file_auth_proto_auth_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -255,7 +475,7 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{4}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *DeleteRequest) GetToken() string {
@@ -274,7 +494,7 @@ type DeleteResponse struct {
 
 func (x *DeleteResponse) Reset() {
 	*x = DeleteResponse{}
-	mi := &file_auth_proto_auth_proto_msgTypes[5]
+	mi := &file_auth_proto_auth_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -286,7 +506,7 @@ func (x *DeleteResponse) String() string {
 func (*DeleteResponse) ProtoMessage() {}
 
 func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_auth_proto_auth_proto_msgTypes[5]
+	mi := &file_auth_proto_auth_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -299,7 +519,7 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
 func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return file_auth_proto_auth_proto_rawDescGZIP(), []int{5}
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DeleteResponse) GetError() string {
@@ -309,31 +529,770 @@ func (x *DeleteResponse) GetError() string {
 	return ""
 }
 
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alias         string                 `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*TokenInfo           `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListResponse) GetTokens() []*TokenInfo {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RotateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	GraceSeconds  int64                  `protobuf:"varint,2,opt,name=grace_seconds,json=graceSeconds,proto3" json:"grace_seconds,omitempty"` // <= 0 revokes the old token at once
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateRequest) Reset() {
+	*x = RotateRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateRequest) ProtoMessage() {}
+
+func (x *RotateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateRequest.ProtoReflect.Descriptor instead.
+func (*RotateRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RotateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RotateRequest) GetGraceSeconds() int64 {
+	if x != nil {
+		return x.GraceSeconds
+	}
+	return 0
+}
+
+type RotateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Info          *TokenInfo             `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	NotFound      bool                   `protobuf:"varint,4,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	Expired       bool                   `protobuf:"varint,5,opt,name=expired,proto3" json:"expired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateResponse) Reset() {
+	*x = RotateResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateResponse) ProtoMessage() {}
+
+func (x *RotateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateResponse.ProtoReflect.Descriptor instead.
+func (*RotateResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RotateResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RotateResponse) GetInfo() *TokenInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *RotateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RotateResponse) GetNotFound() bool {
+	if x != nil {
+		return x.NotFound
+	}
+	return false
+}
+
+func (x *RotateResponse) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+type RevokeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRequest) Reset() {
+	*x = RevokeRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRequest) ProtoMessage() {}
+
+func (x *RevokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRequest.ProtoReflect.Descriptor instead.
+func (*RevokeRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RevokeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	NotFound      bool                   `protobuf:"varint,2,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeResponse) Reset() {
+	*x = RevokeResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeResponse) ProtoMessage() {}
+
+func (x *RevokeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeResponse.ProtoReflect.Descriptor instead.
+func (*RevokeResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RevokeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RevokeResponse) GetNotFound() bool {
+	if x != nil {
+		return x.NotFound
+	}
+	return false
+}
+
+type RevokeByAliasRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alias         string                 `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeByAliasRequest) Reset() {
+	*x = RevokeByAliasRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeByAliasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeByAliasRequest) ProtoMessage() {}
+
+func (x *RevokeByAliasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeByAliasRequest.ProtoReflect.Descriptor instead.
+func (*RevokeByAliasRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RevokeByAliasRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+type RevokeByAliasResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revoked       int64                  `protobuf:"varint,1,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeByAliasResponse) Reset() {
+	*x = RevokeByAliasResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeByAliasResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeByAliasResponse) ProtoMessage() {}
+
+func (x *RevokeByAliasResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeByAliasResponse.ProtoReflect.Descriptor instead.
+func (*RevokeByAliasResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RevokeByAliasResponse) GetRevoked() int64 {
+	if x != nil {
+		return x.Revoked
+	}
+	return 0
+}
+
+func (x *RevokeByAliasResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type LookupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRequest) Reset() {
+	*x = LookupRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRequest) ProtoMessage() {}
+
+func (x *LookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRequest.ProtoReflect.Descriptor instead.
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LookupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type LookupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *TokenInfo             `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	NotFound      bool                   `protobuf:"varint,3,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LookupResponse) GetInfo() *TokenInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *LookupResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LookupResponse) GetNotFound() bool {
+	if x != nil {
+		return x.NotFound
+	}
+	return false
+}
+
+type SetBudgetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Budget        *Budget                `protobuf:"bytes,2,opt,name=budget,proto3" json:"budget,omitempty"` // absent removes the budget
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBudgetRequest) Reset() {
+	*x = SetBudgetRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBudgetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBudgetRequest) ProtoMessage() {}
+
+func (x *SetBudgetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBudgetRequest.ProtoReflect.Descriptor instead.
+func (*SetBudgetRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SetBudgetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetBudgetRequest) GetBudget() *Budget {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+type SetBudgetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *TokenInfo             `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	NotFound      bool                   `protobuf:"varint,3,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBudgetResponse) Reset() {
+	*x = SetBudgetResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBudgetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBudgetResponse) ProtoMessage() {}
+
+func (x *SetBudgetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBudgetResponse.ProtoReflect.Descriptor instead.
+func (*SetBudgetResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetBudgetResponse) GetInfo() *TokenInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *SetBudgetResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SetBudgetResponse) GetNotFound() bool {
+	if x != nil {
+		return x.NotFound
+	}
+	return false
+}
+
 var File_auth_proto_auth_proto protoreflect.FileDescriptor
 
 const file_auth_proto_auth_proto_rawDesc = "" +
 	"\n" +
-	"\x15auth/proto/auth.proto\x12\x04auth\"%\n" +
+	"\x15auth/proto/auth.proto\x12\x04auth\"\xb3\x03\n" +
+	"\tTokenInfo\x12\x14\n" +
+	"\x05alias\x18\x01 \x01(\tR\x05alias\x12&\n" +
+	"\x0fcreated_at_unix\x18\x02 \x01(\x03R\rcreatedAtUnix\x12&\n" +
+	"\x0fexpires_at_unix\x18\x03 \x01(\x03R\rexpiresAtUnix\x12\x16\n" +
+	"\x06scopes\x18\x04 \x03(\tR\x06scopes\x12\x16\n" +
+	"\x06models\x18\x05 \x03(\tR\x06models\x12'\n" +
+	"\x0frag_collections\x18\x06 \x03(\tR\x0eragCollections\x123\n" +
+	"\x06labels\x18\a \x03(\v2\x1b.auth.TokenInfo.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06prefix\x18\b \x01(\tR\x06prefix\x12\x0e\n" +
+	"\x02id\x18\t \x01(\tR\x02id\x12)\n" +
+	"\x11last_used_at_unix\x18\n" +
+	" \x01(\x03R\x0elastUsedAtUnix\x12$\n" +
+	"\x06budget\x18\v \x01(\v2\f.auth.BudgetR\x06budget\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc4\x01\n" +
+	"\x06Budget\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x12*\n" +
+	"\x11soft_limit_tokens\x18\x02 \x01(\x03R\x0fsoftLimitTokens\x12*\n" +
+	"\x11hard_limit_tokens\x18\x03 \x01(\x03R\x0fhardLimitTokens\x12$\n" +
+	"\x0esoft_limit_usd\x18\x04 \x01(\x01R\fsoftLimitUsd\x12$\n" +
+	"\x0ehard_limit_usd\x18\x05 \x01(\x01R\fhardLimitUsd\"J\n" +
 	"\rCreateRequest\x12\x14\n" +
-	"\x05alias\x18\x01 \x01(\tR\x05alias\"<\n" +
+	"\x05alias\x18\x01 \x01(\tR\x05alias\x12#\n" +
+	"\x04info\x18\x02 \x01(\v2\x0f.auth.TokenInfoR\x04info\"<\n" +
 	"\x0eCreateResponse\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"\"\n" +
 	"\n" +
 	"GetRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"O\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"t\n" +
 	"\vGetResponse\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x14\n" +
 	"\x05alias\x18\x02 \x01(\tR\x05alias\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"%\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12#\n" +
+	"\x04info\x18\x04 \x01(\v2\x0f.auth.TokenInfoR\x04info\"%\n" +
 	"\rDeleteRequest\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\"&\n" +
 	"\x0eDeleteResponse\x12\x14\n" +
-	"\x05error\x18\x01 \x01(\tR\x05error2\xa3\x01\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"\xc3\x01\n" +
+	"\vListRequest\x12\x14\n" +
+	"\x05alias\x18\x01 \x01(\tR\x05alias\x125\n" +
+	"\x06labels\x18\x02 \x03(\v2\x1d.auth.ListRequest.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\fListResponse\x12'\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x0f.auth.TokenInfoR\x06tokens\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"D\n" +
+	"\rRotateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rgrace_seconds\x18\x02 \x01(\x03R\fgraceSeconds\"\x98\x01\n" +
+	"\x0eRotateResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\x04info\x18\x02 \x01(\v2\x0f.auth.TokenInfoR\x04info\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1b\n" +
+	"\tnot_found\x18\x04 \x01(\bR\bnotFound\x12\x18\n" +
+	"\aexpired\x18\x05 \x01(\bR\aexpired\"\x1f\n" +
+	"\rRevokeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"C\n" +
+	"\x0eRevokeResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\x1b\n" +
+	"\tnot_found\x18\x02 \x01(\bR\bnotFound\",\n" +
+	"\x14RevokeByAliasRequest\x12\x14\n" +
+	"\x05alias\x18\x01 \x01(\tR\x05alias\"G\n" +
+	"\x15RevokeByAliasResponse\x12\x18\n" +
+	"\arevoked\x18\x01 \x01(\x03R\arevoked\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x1f\n" +
+	"\rLookupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"h\n" +
+	"\x0eLookupResponse\x12#\n" +
+	"\x04info\x18\x01 \x01(\v2\x0f.auth.TokenInfoR\x04info\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1b\n" +
+	"\tnot_found\x18\x03 \x01(\bR\bnotFound\"H\n" +
+	"\x10SetBudgetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
+	"\x06budget\x18\x02 \x01(\v2\f.auth.BudgetR\x06budget\"k\n" +
+	"\x11SetBudgetResponse\x12#\n" +
+	"\x04info\x18\x01 \x01(\v2\x0f.auth.TokenInfoR\x04info\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1b\n" +
+	"\tnot_found\x18\x03 \x01(\bR\bnotFound2\xf9\x03\n" +
 	"\vAuthService\x123\n" +
 	"\x06Create\x12\x13.auth.CreateRequest\x1a\x14.auth.CreateResponse\x12*\n" +
 	"\x03Get\x12\x10.auth.GetRequest\x1a\x11.auth.GetResponse\x123\n" +
-	"\x06Delete\x12\x13.auth.DeleteRequest\x1a\x14.auth.DeleteResponseB\x18Z\x16llm_gateway/auth/protob\x06proto3"
+	"\x06Delete\x12\x13.auth.DeleteRequest\x1a\x14.auth.DeleteResponse\x12-\n" +
+	"\x04List\x12\x11.auth.ListRequest\x1a\x12.auth.ListResponse\x123\n" +
+	"\x06Rotate\x12\x13.auth.RotateRequest\x1a\x14.auth.RotateResponse\x123\n" +
+	"\x06Revoke\x12\x13.auth.RevokeRequest\x1a\x14.auth.RevokeResponse\x12H\n" +
+	"\rRevokeByAlias\x12\x1a.auth.RevokeByAliasRequest\x1a\x1b.auth.RevokeByAliasResponse\x123\n" +
+	"\x06Lookup\x12\x13.auth.LookupRequest\x1a\x14.auth.LookupResponse\x12<\n" +
+	"\tSetBudget\x12\x16.auth.SetBudgetRequest\x1a\x17.auth.SetBudgetResponseB\x18Z\x16llm_gateway/auth/protob\x06proto3"
 
 var (
 	file_auth_proto_auth_proto_rawDescOnce sync.Once
@@ -347,27 +1306,65 @@ func file_auth_proto_auth_proto_rawDescGZIP() []byte {
 	return file_auth_proto_auth_proto_rawDescData
 }
 
-var file_auth_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_auth_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_auth_proto_auth_proto_goTypes = []any{
-	(*CreateRequest)(nil),  // 0: auth.CreateRequest
-	(*CreateResponse)(nil), // 1: auth.CreateResponse
-	(*GetRequest)(nil),     // 2: auth.GetRequest
-	(*GetResponse)(nil),    // 3: auth.GetResponse
-	(*DeleteRequest)(nil),  // 4: auth.DeleteRequest
-	(*DeleteResponse)(nil), // 5: auth.DeleteResponse
+	(*TokenInfo)(nil),             // 0: auth.TokenInfo
+	(*Budget)(nil),                // 1: auth.Budget
+	(*CreateRequest)(nil),         // 2: auth.CreateRequest
+	(*CreateResponse)(nil),        // 3: auth.CreateResponse
+	(*GetRequest)(nil),            // 4: auth.GetRequest
+	(*GetResponse)(nil),           // 5: auth.GetResponse
+	(*DeleteRequest)(nil),         // 6: auth.DeleteRequest
+	(*DeleteResponse)(nil),        // 7: auth.DeleteResponse
+	(*ListRequest)(nil),           // 8: auth.ListRequest
+	(*ListResponse)(nil),          // 9: auth.ListResponse
+	(*RotateRequest)(nil),         // 10: auth.RotateRequest
+	(*RotateResponse)(nil),        // 11: auth.RotateResponse
+	(*RevokeRequest)(nil),         // 12: auth.RevokeRequest
+	(*RevokeResponse)(nil),        // 13: auth.RevokeResponse
+	(*RevokeByAliasRequest)(nil),  // 14: auth.RevokeByAliasRequest
+	(*RevokeByAliasResponse)(nil), // 15: auth.RevokeByAliasResponse
+	(*LookupRequest)(nil),         // 16: auth.LookupRequest
+	(*LookupResponse)(nil),        // 17: auth.LookupResponse
+	(*SetBudgetRequest)(nil),      // 18: auth.SetBudgetRequest
+	(*SetBudgetResponse)(nil),     // 19: auth.SetBudgetResponse
+	nil,                           // 20: auth.TokenInfo.LabelsEntry
+	nil,                           // 21: auth.ListRequest.LabelsEntry
 }
 var file_auth_proto_auth_proto_depIdxs = []int32{
-	0, // 0: auth.AuthService.Create:input_type -> auth.CreateRequest
-	2, // 1: auth.AuthService.Get:input_type -> auth.GetRequest
-	4, // 2: auth.AuthService.Delete:input_type -> auth.DeleteRequest
-	1, // 3: auth.AuthService.Create:output_type -> auth.CreateResponse
-	3, // 4: auth.AuthService.Get:output_type -> auth.GetResponse
-	5, // 5: auth.AuthService.Delete:output_type -> auth.DeleteResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	20, // 0: auth.TokenInfo.labels:type_name -> auth.TokenInfo.LabelsEntry
+	1,  // 1: auth.TokenInfo.budget:type_name -> auth.Budget
+	0,  // 2: auth.CreateRequest.info:type_name -> auth.TokenInfo
+	0,  // 3: auth.GetResponse.info:type_name -> auth.TokenInfo
+	21, // 4: auth.ListRequest.labels:type_name -> auth.ListRequest.LabelsEntry
+	0,  // 5: auth.ListResponse.tokens:type_name -> auth.TokenInfo
+	0,  // 6: auth.RotateResponse.info:type_name -> auth.TokenInfo
+	0,  // 7: auth.LookupResponse.info:type_name -> auth.TokenInfo
+	1,  // 8: auth.SetBudgetRequest.budget:type_name -> auth.Budget
+	0,  // 9: auth.SetBudgetResponse.info:type_name -> auth.TokenInfo
+	2,  // 10: auth.AuthService.Create:input_type -> auth.CreateRequest
+	4,  // 11: auth.AuthService.Get:input_type -> auth.GetRequest
+	6,  // 12: auth.AuthService.Delete:input_type -> auth.DeleteRequest
+	8,  // 13: auth.AuthService.List:input_type -> auth.ListRequest
+	10, // 14: auth.AuthService.Rotate:input_type -> auth.RotateRequest
+	12, // 15: auth.AuthService.Revoke:input_type -> auth.RevokeRequest
+	14, // 16: auth.AuthService.RevokeByAlias:input_type -> auth.RevokeByAliasRequest
+	16, // 17: auth.AuthService.Lookup:input_type -> auth.LookupRequest
+	18, // 18: auth.AuthService.SetBudget:input_type -> auth.SetBudgetRequest
+	3,  // 19: auth.AuthService.Create:output_type -> auth.CreateResponse
+	5,  // 20: auth.AuthService.Get:output_type -> auth.GetResponse
+	7,  // 21: auth.AuthService.Delete:output_type -> auth.DeleteResponse
+	9,  // 22: auth.AuthService.List:output_type -> auth.ListResponse
+	11, // 23: auth.AuthService.Rotate:output_type -> auth.RotateResponse
+	13, // 24: auth.AuthService.Revoke:output_type -> auth.RevokeResponse
+	15, // 25: auth.AuthService.RevokeByAlias:output_type -> auth.RevokeByAliasResponse
+	17, // 26: auth.AuthService.Lookup:output_type -> auth.LookupResponse
+	19, // 27: auth.AuthService.SetBudget:output_type -> auth.SetBudgetResponse
+	19, // [19:28] is the sub-list for method output_type
+	10, // [10:19] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_auth_proto_auth_proto_init() }
@@ -381,7 +1378,7 @@ func file_auth_proto_auth_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_auth_proto_auth_proto_rawDesc), len(file_auth_proto_auth_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
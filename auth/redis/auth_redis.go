@@ -2,22 +2,56 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm_gateway/auth"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// InvalidationChannel is the Redis pub/sub channel on which the service
+// announces the ID of every token it revokes or changes, so gateways can drop
+// cached copies at once.
+const InvalidationChannel = "auth:invalidate"
+
 const (
 	tokenPrefix   = "sk"
 	entropyLength = 32
-	keyPrefix     = "auth:token:"
+	// keyPrefix is followed by the token ID, HashToken(token). Keys written
+	// before tokens were hashed hold the raw token instead; see MigrateTokens.
+	keyPrefix = "auth:token:"
+	// indexKey and aliasIndexPrefix+alias are sorted sets of token IDs scored
+	// by creation time, for List and RevokeByAlias.
+	indexKey         = "auth:tokens"
+	aliasIndexPrefix = "auth:alias:"
+	// lastUsedKey is a hash of token ID to the unix time it was last used.
+	lastUsedKey = "auth:last_used"
+
+	// expiredTokenRetention keeps an expired token's record around so the
+	// gateway can answer "expired" rather than "invalid" for a while.
+	expiredTokenRetention = 30 * 24 * time.Hour
+	// lastUsedResolution bounds last-used writes to one per token per minute.
+	lastUsedResolution = time.Minute
+
+	migrateScanCount = 500
+	defaultListLimit = 100
+	maxListLimit     = 1000
 )
 
 type RedisAuthService struct {
 	rdbClient *redis.Client
+	secret    []byte // HMAC key for HashToken
 }
 
-func NewRedisAuthService(addr, password string, db int) (*RedisAuthService, error) {
+func NewRedisAuthService(addr, password string, db int, secret []byte) (*RedisAuthService, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("redis auth: token secret must be at least %d bytes", MinSecretLength)
+	}
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
@@ -27,44 +61,491 @@ func NewRedisAuthService(addr, password string, db int) (*RedisAuthService, erro
 	if err := client.Ping(ctx).Err(); err != nil {
 		return nil, fmt.Errorf("redis auth: failed to connect: %w", err)
 	}
-	return &RedisAuthService{rdbClient: client}, nil
+	return &RedisAuthService{rdbClient: client, secret: secret}, nil
 }
 
-// Create generates a new sk-xxx token for the given alias and stores it in Redis.
-func (s *RedisAuthService) Create(ctx context.Context, alias string) (token string, err error) {
+// Create generates a new sk-xxx token and stores info as JSON under the
+// token's hash. The token itself is returned once and never stored.
+func (s *RedisAuthService) Create(ctx context.Context, info auth.TokenInfo) (token string, err error) {
+	token, _, err = s.create(ctx, info)
+	return token, err
+}
+
+func (s *RedisAuthService) create(ctx context.Context, info auth.TokenInfo) (string, auth.TokenInfo, error) {
+	if err := info.Validate(); err != nil {
+		return "", auth.TokenInfo{}, fmt.Errorf("invalid token info: %w", err)
+	}
+	if info.Expired(time.Now()) {
+		return "", auth.TokenInfo{}, fmt.Errorf("invalid token info: expires_at is in the past")
+	}
 	tokenString, err := GenerateToken(tokenPrefix, entropyLength)
 	if err != nil {
-		return "", fmt.Errorf("fail to create token: %w", err)
+		return "", auth.TokenInfo{}, fmt.Errorf("fail to create token: %w", err)
 	}
 
-	// SET keyPrefix+token alias
-	if err := s.rdbClient.Set(ctx, keyPrefix+tokenString, alias, 0).Err(); err != nil {
-		return "", fmt.Errorf("fail to store token: %w", err)
+	info.CreatedAt = time.Now().UTC()
+	info.Prefix = DisplayPrefix(tokenString)
+	info.ID = HashToken(s.secret, tokenString)
+	info.LastUsedAt = time.Time{}
+	if err := s.store(ctx, info); err != nil {
+		return "", auth.TokenInfo{}, fmt.Errorf("fail to store token: %w", err)
+	}
+	return tokenString, info, nil
+}
+
+// store writes info under its ID and indexes it.
+func (s *RedisAuthService) store(ctx context.Context, info auth.TokenInfo) error {
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if !info.ExpiresAt.IsZero() {
+		// Never 0, which Set reads as "no expiry".
+		ttl = max(time.Until(info.ExpiresAt)+expiredTokenRetention, time.Second)
+	}
+	z := indexEntry(info)
+	_, err = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		// SET keyPrefix+id info
+		tx.Set(ctx, keyPrefix+info.ID, raw, ttl)
+		tx.ZAdd(ctx, indexKey, z)
+		tx.ZAdd(ctx, aliasIndexPrefix+info.Alias, z)
+		return nil
+	})
+	return err
+}
+
+// indexEntry scores a token by creation time. Records that predate
+// CreatedAt sort first.
+func indexEntry(info auth.TokenInfo) redis.Z {
+	z := redis.Z{Member: info.ID}
+	if !info.CreatedAt.IsZero() {
+		z.Score = float64(info.CreatedAt.UnixMilli())
 	}
-	return tokenString, nil
+	return z
 }
 
-// Get validates the token format and looks up the alias in Redis.
-func (s *RedisAuthService) Get(ctx context.Context, token string) (valid bool, alias string, err error) {
+// Get validates the token format and looks up what it grants in Redis by the
+// token's hash, noting the use. A token still stored under its raw value is
+// rehashed on the way, so tokens keep working between a deploy and
+// MigrateTokens.
+func (s *RedisAuthService) Get(ctx context.Context, token string) (valid bool, info auth.TokenInfo, err error) {
 	if !CheckTokenFormat(tokenPrefix, entropyLength, token) {
-		return false, "", nil
+		return false, auth.TokenInfo{}, nil
 	}
 
-	// GET keyPrefix+token
-	alias, err = s.rdbClient.Get(ctx, keyPrefix+token).Result()
+	id := HashToken(s.secret, token)
+	pipe := s.rdbClient.Pipeline()
+	getCmd := pipe.Get(ctx, keyPrefix+id)
+	lastUsedCmd := pipe.HGet(ctx, lastUsedKey, id)
+	_, err = pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		return false, auth.TokenInfo{}, fmt.Errorf("fail to get token: %w", err)
+	}
+
+	raw, err := getCmd.Result()
+	if err == redis.Nil {
+		var migrated bool
+		raw, migrated, err = s.rehash(ctx, token)
+		if err == nil && !migrated {
+			return false, auth.TokenInfo{}, nil
+		}
+	}
 	if err != nil {
-		if err == redis.Nil {
-			return false, "", nil
+		return false, auth.TokenInfo{}, fmt.Errorf("fail to get token: %w", err)
+	}
+	info, err = decodeTokenInfo(raw)
+	if err != nil {
+		return false, auth.TokenInfo{}, err
+	}
+	info.ID = id
+	info.LastUsedAt = parseLastUsed(lastUsedCmd.Val())
+
+	if now := time.Now(); now.Sub(info.LastUsedAt) >= lastUsedResolution {
+		// Best effort: a failed write only makes last_used_at stale.
+		if err := s.rdbClient.HSet(ctx, lastUsedKey, id, now.Unix()).Err(); err == nil {
+			info.LastUsedAt = time.Unix(now.Unix(), 0).UTC()
 		}
-		return false, "", fmt.Errorf("fail to get token: %w", err)
 	}
-	return true, alias, nil
+	return true, info, nil
+}
+
+// decodeTokenInfo reads a stored token record. Tokens minted before metadata
+// existed hold the bare alias and grant the defaults.
+func decodeTokenInfo(raw string) (auth.TokenInfo, error) {
+	if !strings.HasPrefix(raw, "{") {
+		return auth.TokenInfo{Alias: raw}, nil
+	}
+	var info auth.TokenInfo
+	if err := json.Unmarshal([]byte(raw), &info); err != nil {
+		return auth.TokenInfo{}, fmt.Errorf("fail to decode token: %w", err)
+	}
+	return info, nil
 }
 
-// Delete removes the token from Redis.
+func parseLastUsed(raw string) time.Time {
+	unix, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || unix <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0).UTC()
+}
+
+// Delete removes the token from Redis, under its hash and, if it was never
+// migrated, under its raw value.
 func (s *RedisAuthService) Delete(ctx context.Context, token string) error {
+	if !CheckTokenFormat(tokenPrefix, entropyLength, token) {
+		return nil
+	}
+	if err := s.Revoke(ctx, HashToken(s.secret, token)); err != nil && !errors.Is(err, auth.ErrTokenNotFound) {
+		return fmt.Errorf("fail to delete token: %w", err)
+	}
 	if err := s.rdbClient.Del(ctx, keyPrefix+token).Err(); err != nil {
 		return fmt.Errorf("fail to delete token: %w", err)
 	}
 	return nil
 }
+
+// Revoke deletes the token with id and drops it from the indexes.
+func (s *RedisAuthService) Revoke(ctx context.Context, id string) error {
+	info, err := s.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		tx.Del(ctx, keyPrefix+id)
+		tx.ZRem(ctx, indexKey, id)
+		tx.ZRem(ctx, aliasIndexPrefix+info.Alias, id)
+		tx.HDel(ctx, lastUsedKey, id)
+		tx.Publish(ctx, InvalidationChannel, id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeByAlias deletes every indexed token of alias. Tokens still stored
+// under their raw value are not indexed; run MigrateTokens first.
+func (s *RedisAuthService) RevokeByAlias(ctx context.Context, alias string) (int, error) {
+	aliasKey := aliasIndexPrefix + alias
+	ids, err := s.rdbClient.ZRange(ctx, aliasKey, 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("fail to list tokens of alias: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(ids))
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		keys[i] = keyPrefix + id
+		members[i] = id
+	}
+	var delCmd *redis.IntCmd
+	_, err = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		delCmd = tx.Del(ctx, keys...)
+		tx.ZRem(ctx, indexKey, members...)
+		tx.ZRem(ctx, aliasKey, members...)
+		tx.HDel(ctx, lastUsedKey, ids...)
+		for _, id := range ids {
+			tx.Publish(ctx, InvalidationChannel, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("fail to revoke tokens of alias: %w", err)
+	}
+	return int(delCmd.Val()), nil
+}
+
+// Rotate mints a replacement for the token with id and shortens the old
+// token's life to grace, or revokes it outright when grace <= 0.
+func (s *RedisAuthService) Rotate(ctx context.Context, id string, grace time.Duration) (string, auth.TokenInfo, error) {
+	old, err := s.load(ctx, id)
+	if err != nil {
+		return "", auth.TokenInfo{}, err
+	}
+	if old.Expired(time.Now()) {
+		return "", auth.TokenInfo{}, auth.ErrTokenExpired
+	}
+	token, info, err := s.create(ctx, auth.TokenInfo{
+		Alias:          old.Alias,
+		ExpiresAt:      old.ExpiresAt,
+		Scopes:         old.Scopes,
+		Models:         old.Models,
+		RAGCollections: old.RAGCollections,
+		Labels:         old.Labels,
+		Budget:         old.Budget,
+	})
+	if err != nil {
+		return "", auth.TokenInfo{}, err
+	}
+
+	if grace <= 0 {
+		err = s.Revoke(ctx, id)
+	} else {
+		if until := time.Now().Add(grace).UTC(); old.ExpiresAt.IsZero() || until.Before(old.ExpiresAt) {
+			old.ExpiresAt = until
+		}
+		if err = s.store(ctx, old); err == nil {
+			// Best effort: gateways that miss it pick up the new expiry
+			// when their cached copy times out.
+			_ = s.rdbClient.Publish(ctx, InvalidationChannel, id).Err()
+		}
+	}
+	if err != nil {
+		// The replacement exists; hand it out so the caller is not left
+		// with neither, and report the old token as still live.
+		return token, info, fmt.Errorf("replacement created but old token not retired: %w", err)
+	}
+	return token, info, nil
+}
+
+// Lookup returns the token with id, with its last use.
+func (s *RedisAuthService) Lookup(ctx context.Context, id string) (auth.TokenInfo, error) {
+	infos, err := s.loadMany(ctx, []string{id})
+	if err != nil {
+		return auth.TokenInfo{}, err
+	}
+	if infos[0] == nil {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	return *infos[0], nil
+}
+
+// SetBudget replaces the budget of token id, or removes it when budget is
+// nil.
+func (s *RedisAuthService) SetBudget(ctx context.Context, id string, budget *auth.Budget) (auth.TokenInfo, error) {
+	if budget != nil {
+		if err := budget.Validate(); err != nil {
+			return auth.TokenInfo{}, fmt.Errorf("invalid budget: %w", err)
+		}
+	}
+	info, err := s.load(ctx, id)
+	if err != nil {
+		return auth.TokenInfo{}, err
+	}
+	info.Budget = budget
+	if err := s.store(ctx, info); err != nil {
+		return auth.TokenInfo{}, fmt.Errorf("fail to set budget: %w", err)
+	}
+	// Best effort, as in Rotate.
+	_ = s.rdbClient.Publish(ctx, InvalidationChannel, id).Err()
+	return info, nil
+}
+
+// load reads the record of token id.
+func (s *RedisAuthService) load(ctx context.Context, id string) (auth.TokenInfo, error) {
+	raw, err := s.rdbClient.Get(ctx, keyPrefix+id).Result()
+	if err == redis.Nil {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	if err != nil {
+		return auth.TokenInfo{}, fmt.Errorf("fail to get token: %w", err)
+	}
+	info, err := decodeTokenInfo(raw)
+	if err != nil {
+		return auth.TokenInfo{}, err
+	}
+	info.ID = id
+	return info, nil
+}
+
+// List walks the creation-time index (per alias when f.Alias is set) and
+// returns up to f.Limit matching tokens. The cursor is an offset into the
+// index. Entries whose record has expired out of Redis are pruned on the way.
+func (s *RedisAuthService) List(ctx context.Context, f auth.ListFilter) (auth.TokenPage, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	var start int64
+	if f.Cursor != "" {
+		n, err := strconv.ParseInt(f.Cursor, 10, 64)
+		if err != nil || n < 0 {
+			return auth.TokenPage{}, fmt.Errorf("invalid cursor %q", f.Cursor)
+		}
+		start = n
+	}
+	index := indexKey
+	if f.Alias != "" {
+		index = aliasIndexPrefix + f.Alias
+	}
+
+	page := auth.TokenPage{Tokens: []auth.TokenInfo{}}
+	for {
+		ids, err := s.rdbClient.ZRange(ctx, index, start, start+int64(limit)-1).Result()
+		if err != nil {
+			return auth.TokenPage{}, fmt.Errorf("fail to list tokens: %w", err)
+		}
+		if len(ids) == 0 {
+			return page, nil
+		}
+		infos, err := s.loadMany(ctx, ids)
+		if err != nil {
+			return auth.TokenPage{}, err
+		}
+
+		var stale []any
+		for i, info := range infos {
+			if info == nil {
+				stale = append(stale, ids[i])
+				continue
+			}
+			if !f.Matches(*info) {
+				continue
+			}
+			page.Tokens = append(page.Tokens, *info)
+			if len(page.Tokens) == limit {
+				// Pruning shifts later offsets down by the entries removed.
+				s.prune(ctx, index, stale)
+				page.NextCursor = strconv.FormatInt(start+int64(i)+1-int64(len(stale)), 10)
+				return page, nil
+			}
+		}
+		s.prune(ctx, index, stale)
+		if len(ids) < limit {
+			return page, nil
+		}
+		start += int64(len(ids) - len(stale))
+	}
+}
+
+// loadMany reads the records and last-used times of ids. A missing record
+// comes back nil.
+func (s *RedisAuthService) loadMany(ctx context.Context, ids []string) ([]*auth.TokenInfo, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = keyPrefix + id
+	}
+	pipe := s.rdbClient.Pipeline()
+	recordsCmd := pipe.MGet(ctx, keys...)
+	lastUsedCmd := pipe.HMGet(ctx, lastUsedKey, ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("fail to list tokens: %w", err)
+	}
+	records, lastUsed := recordsCmd.Val(), lastUsedCmd.Val()
+
+	infos := make([]*auth.TokenInfo, len(ids))
+	for i, v := range records {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		info, err := decodeTokenInfo(raw)
+		if err != nil {
+			return nil, err
+		}
+		info.ID = ids[i]
+		if used, ok := lastUsed[i].(string); ok {
+			info.LastUsedAt = parseLastUsed(used)
+		}
+		infos[i] = &info
+	}
+	return infos, nil
+}
+
+// prune drops index entries whose record is gone. Failures are harmless: the
+// next List tries again.
+func (s *RedisAuthService) prune(ctx context.Context, index string, ids []any) {
+	if len(ids) == 0 {
+		return
+	}
+	_, _ = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		tx.ZRem(ctx, indexKey, ids...)
+		if index != indexKey {
+			tx.ZRem(ctx, index, ids...)
+		}
+		return nil
+	})
+}
+
+// MigrateTokens rehashes every token still stored under its raw value
+// (auth:token:sk-...) in place: the record moves to the hashed key with its
+// TTL, gains a display prefix and ID, is indexed, and the raw key is deleted.
+// Hashed records missing from the List indexes are indexed. Safe to run more
+// than once and while the service is serving.
+func (s *RedisAuthService) MigrateTokens(ctx context.Context) (migrated int, err error) {
+	iter := s.rdbClient.Scan(ctx, 0, keyPrefix+"*", migrateScanCount).Iterator()
+	for iter.Next(ctx) {
+		token := strings.TrimPrefix(iter.Val(), keyPrefix)
+		if !CheckTokenFormat(tokenPrefix, entropyLength, token) {
+			if err := s.reindex(ctx, token); err != nil {
+				return migrated, fmt.Errorf("fail to index token %s: %w", token, err)
+			}
+			continue
+		}
+		_, ok, err := s.rehash(ctx, token)
+		if err != nil {
+			return migrated, fmt.Errorf("fail to migrate token %s: %w", DisplayPrefix(token), err)
+		}
+		if ok {
+			migrated++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return migrated, fmt.Errorf("fail to scan tokens: %w", err)
+	}
+	return migrated, nil
+}
+
+// reindex adds the hashed record id to the List indexes if it is missing.
+func (s *RedisAuthService) reindex(ctx context.Context, id string) error {
+	info, err := s.load(ctx, id)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		return nil // expired since the scan saw it
+	}
+	if err != nil {
+		return err
+	}
+	z := indexEntry(info)
+	_, err = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		tx.ZAddNX(ctx, indexKey, z)
+		tx.ZAddNX(ctx, aliasIndexPrefix+info.Alias, z)
+		return nil
+	})
+	return err
+}
+
+// rehash moves the record stored under the raw token to its hashed key and
+// returns the new record. ok is false when there is no raw key.
+func (s *RedisAuthService) rehash(ctx context.Context, token string) (raw string, ok bool, err error) {
+	legacyKey := keyPrefix + token
+	pipe := s.rdbClient.Pipeline()
+	getCmd := pipe.Get(ctx, legacyKey)
+	ttlCmd := pipe.PTTL(ctx, legacyKey)
+	if _, err := pipe.Exec(ctx); err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	info, err := decodeTokenInfo(getCmd.Val())
+	if err != nil {
+		return "", false, err
+	}
+	info.Prefix = DisplayPrefix(token)
+	info.ID = HashToken(s.secret, token)
+	b, err := json.Marshal(info)
+	if err != nil {
+		return "", false, err
+	}
+	// PTTL is negative for a key without expiry; Set takes 0 for that.
+	ttl := max(ttlCmd.Val(), 0)
+	z := indexEntry(info)
+
+	_, err = s.rdbClient.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		tx.Set(ctx, keyPrefix+info.ID, b, ttl)
+		tx.ZAdd(ctx, indexKey, z)
+		tx.ZAdd(ctx, aliasIndexPrefix+info.Alias, z)
+		tx.Del(ctx, legacyKey)
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
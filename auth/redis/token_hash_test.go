@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashToken_KeyedBySecret(t *testing.T) {
+	token, err := GenerateToken(tokenPrefix, entropyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := []byte(strings.Repeat("a", MinSecretLength))
+	b := []byte(strings.Repeat("b", MinSecretLength))
+
+	if HashToken(a, token) != HashToken(a, token) {
+		t.Error("same secret and token must hash the same")
+	}
+	if HashToken(a, token) == HashToken(b, token) {
+		t.Error("different secrets must hash differently")
+	}
+	if h := HashToken(a, token); strings.Contains(h, token) || len(h) != 64 {
+		t.Errorf("hash: got %q", h)
+	}
+}
+
+func TestDisplayPrefix(t *testing.T) {
+	token, err := GenerateToken(tokenPrefix, entropyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := DisplayPrefix(token)
+	if len(p) != displayPrefixLen || !strings.HasPrefix(token, p) || !strings.HasPrefix(p, "sk-") {
+		t.Errorf("prefix: got %q for %q", p, token)
+	}
+}
+
+func TestNewRedisAuthService_RejectsShortSecret(t *testing.T) {
+	if _, err := NewRedisAuthService("127.0.0.1:1", "", 0, []byte("short")); err == nil {
+		t.Fatal("want error for a short secret")
+	}
+}
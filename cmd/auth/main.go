@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	authgrpc "llm_gateway/auth/grpc"
 	pb "llm_gateway/auth/proto"
 	"llm_gateway/auth/redis"
@@ -43,6 +44,11 @@ func main() {
 		panic("REDIS_DB must be a valid integer: " + err.Error())
 	}
 
+	tokenSecret := os.Getenv("AUTH_TOKEN_SECRET")
+	if tokenSecret == "" {
+		panic("AUTH_TOKEN_SECRET not set")
+	}
+
 	servePort := os.Getenv("SERVE_PORT")
 	if servePort == "" {
 		servePort = "50054"
@@ -54,12 +60,24 @@ func main() {
 	}
 	defer func() { _ = tracingShutdown(context.Background()) }()
 
-	authService, err := redis.NewRedisAuthService(redisAddr, password, db)
+	authService, err := redis.NewRedisAuthService(redisAddr, password, db, []byte(tokenSecret))
 	if err != nil {
 		slog.Error("auth service init failed", "err", err)
 		os.Exit(1)
 	}
 
+	// "auth migrate-tokens" rehashes tokens stored before hashing, then exits.
+	if len(os.Args) > 1 && os.Args[1] == "migrate-tokens" {
+		migrated, err := authService.MigrateTokens(context.Background())
+		if err != nil {
+			slog.Error("token migration failed", "migrated", migrated, "err", err)
+			os.Exit(1)
+		}
+		// Printed, not logged: LOG_LEVEL defaults to ERROR.
+		fmt.Printf("migrated %d tokens\n", migrated)
+		return
+	}
+
 	lis, err := net.Listen("tcp", ":"+servePort)
 	if err != nil {
 		slog.Error("listen failed", "port", servePort, "err", err)
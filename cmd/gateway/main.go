@@ -7,16 +7,21 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"strconv"
 
+	"llm_gateway/auth"
 	authGrpc "llm_gateway/auth/grpc"
+	"llm_gateway/auth/oidc"
 	cacheGrpc "llm_gateway/cache/grpc"
 	completionGrpc "llm_gateway/completion/grpc"
+	embeddingGrpc "llm_gateway/embedding/grpc"
 	"llm_gateway/gateway"
 	"llm_gateway/internal/logging"
 	"llm_gateway/internal/metrics"
 	"llm_gateway/internal/tracing"
 	ragGrpc "llm_gateway/rag/grpc"
 
+	goredis "github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -72,12 +77,116 @@ func main() {
 	}
 	defer authSvc.Close()
 
+	modelAllowlist, err := gateway.LoadModelAllowlistFromEnv()
+	if err != nil {
+		slog.Error("model allowlist load failed", "err", err)
+		return
+	}
+
+	pricing, err := gateway.LoadPricingFromEnv()
+	if err != nil {
+		slog.Error("pricing load failed", "err", err)
+		return
+	}
+
+	rateLimits, err := gateway.LoadRateLimitConfigFromEnv()
+	if err != nil {
+		slog.Error("rate limit config load failed", "err", err)
+		return
+	}
+
+	usageRetention, err := gateway.LoadUsageRetentionFromEnv()
+	if err != nil {
+		slog.Error("usage ledger config load failed", "err", err)
+		return
+	}
+
+	authCacheConfig, err := gateway.LoadAuthCacheConfigFromEnv()
+	if err != nil {
+		slog.Error("auth cache config load failed", "err", err)
+		return
+	}
+
+	adminCredentials, err := gateway.LoadAdminCredentialsFromEnv()
+	if err != nil {
+		slog.Error("admin credentials load failed", "err", err)
+		return
+	}
+
+	// JWT bearer tokens from an OIDC identity provider are accepted
+	// alongside API tokens when OIDC_AUTH(_FILE) is set.
+	oidcConfig, err := gateway.LoadOIDCConfigFromEnv()
+	if err != nil {
+		slog.Error("oidc auth config load failed", "err", err)
+		return
+	}
+	var authProviders []auth.Provider
+	if oidcConfig != nil {
+		oidcProvider, err := oidc.NewProvider(context.Background(), *oidcConfig)
+		if err != nil {
+			slog.Error("oidc auth init failed", "err", err)
+			return
+		}
+		authProviders = append(authProviders, oidcProvider)
+		slog.Info("oidc bearer auth enabled", "issuer", oidcConfig.Issuer)
+	}
+
+	// Limits, budget spend and the usage ledger are shared across replicas through the auth service's
+	// Redis when REDIS_ADDR is set; otherwise each replica keeps its own.
+	var rateLimiter gateway.RateLimiter = gateway.NewLocalRateLimiter(rateLimits)
+	var budgets gateway.BudgetStore = gateway.NewMemoryBudgetStore()
+	var usage gateway.UsageLedger = gateway.NewMemoryUsageLedger()
+	var rdb *goredis.Client
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		redisDB, err := strconv.Atoi(os.Getenv("REDIS_DB"))
+		if err != nil && os.Getenv("REDIS_DB") != "" {
+			slog.Error("REDIS_DB must be a valid integer", "err", err)
+			return
+		}
+		rdb = goredis.NewClient(&goredis.Options{
+			Addr:     redisAddr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       redisDB,
+		})
+		defer rdb.Close()
+		rateLimiter = gateway.NewRedisRateLimiter(rdb, rateLimits)
+		budgets = gateway.NewRedisBudgetStore(rdb)
+		usage = gateway.NewRedisUsageLedger(rdb, usageRetention)
+		slog.Info("rate limiter, budget spend and usage ledger using redis", "addr", redisAddr)
+	} else {
+		slog.Warn("usage ledger and budget spend kept in memory without REDIS_ADDR: usage and spend are lost on restart and not shared across replicas; set REDIS_ADDR outside tests and development")
+	}
+
+	// Token lookups are cached in process. Revocations reach other replicas
+	// through the auth service's Redis channel when REDIS_ADDR is set, and
+	// otherwise only once their entries expire.
+	var authService auth.Service = authSvc
+	if authCacheConfig.TTL > 0 {
+		cachedAuth := gateway.NewCachedAuth(authSvc, authCacheConfig)
+		if rdb != nil {
+			watchCtx, stopWatch := context.WithCancel(context.Background())
+			defer stopWatch()
+			go cachedAuth.WatchInvalidations(watchCtx, rdb)
+		} else {
+			slog.Warn("auth cache without REDIS_ADDR: revoked tokens stay valid here until their cache entry expires", "ttl", authCacheConfig.TTL)
+		}
+		authService = cachedAuth
+	}
+
 	deps := gateway.Dependencies{
-		Auth:            authSvc,
-		Cache:           cacheSvc,
-		Completion:      completionSvc,
-		CompletionStats: completionSvc,
-		CompletionAdmin: completionSvc,
+		Auth:             authService,
+		AuthProviders:    authProviders,
+		AdminCredentials: adminCredentials,
+		Cache:            cacheSvc,
+		Completion:       completionSvc,
+		CompletionStats:  completionSvc,
+		CompletionAdmin:  completionSvc,
+		CompletionModels: completionSvc,
+		ModelAllowlist:   modelAllowlist,
+		RateLimiter:      rateLimiter,
+		Budgets:          budgets,
+		Pricing:          pricing,
+		Usage:            usage,
 	}
 
 	// RAG service is optional: omit RAG_ADDR to run without it.
@@ -93,6 +202,19 @@ func main() {
 		slog.Info("rag client connected", "addr", ragGrpcAddress)
 	}
 
+	// Embedding service is optional: omit EMBED_ADDR and /v1/embeddings returns 503.
+	embedGrpcAddress := os.Getenv("EMBED_ADDR")
+	if embedGrpcAddress != "" {
+		embedSvc, err := embeddingGrpc.NewClient(embedGrpcAddress)
+		if err != nil {
+			slog.Error("embedding client init failed", "err", err)
+			return
+		}
+		defer embedSvc.Close()
+		deps.Embedding = embedSvc
+		slog.Info("embedding client connected", "addr", embedGrpcAddress)
+	}
+
 	gatewayServer := gateway.NewServer(deps)
 	defer gatewayServer.Shutdown()
 
@@ -0,0 +1,44 @@
+package completion
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is a failure reported by, or on the way to, an upstream endpoint. It
+// keeps the upstream's OpenAI-style error fields so the gateway can answer the
+// client with the right status and body instead of a blanket 502. It survives
+// the gRPC hop: the server encodes it as a status with details and the client
+// decodes it back.
+type Error struct {
+	StatusCode int    // upstream HTTP status; 0 when no response was received
+	Type       string // OpenAI error.type, e.g. "invalid_request_error"
+	Code       string // OpenAI error.code, e.g. "context_length_exceeded"
+	Param      string
+	Message    string
+	Err        error // underlying cause, if any
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		if e.Err != nil {
+			return fmt.Sprintf("%s: %v", e.Message, e.Err)
+		}
+		return e.Message
+	}
+	return fmt.Sprintf("upstream api returned status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// ClientFault reports whether the upstream rejected the request itself
+// (malformed body, unknown model, context too long). Another endpoint would
+// reject it the same way, so the pool neither retries it nor counts it against
+// the endpoint's breaker.
+func (e *Error) ClientFault() bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
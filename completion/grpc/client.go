@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"llm_gateway/completion"
@@ -32,18 +33,22 @@ func NewClient(address string) (*Client, error) {
 
 func (c *Client) GetStream(ctx context.Context, req *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
 	// Convert completion.CompletionRequest to pb.CompletionRequest
-	pbReq := &pb.CompletionRequest{
-		Model:       req.Model,
-		Question:    req.Question,
-		Temperature: req.Temperature,
-		MaxTokens:   int32(req.MaxTokens),
-		Stream:      req.Stream,
-	}
+	pbReq := toPBRequest(req)
 
 	// Call gRPC streaming method
 	stream, err := c.client.GetStream(ctx, pbReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stream: %w", err)
+		return nil, fmt.Errorf("failed to get stream: %w", fromStatusError(err))
+	}
+	// The server sends headers only once the upstream has accepted the
+	// request, so waiting for them turns an upstream rejection into a
+	// synchronous error rather than a first-chunk error.
+	if md, _ := stream.Header(); md == nil {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			err = errors.New("stream ended without headers")
+		}
+		return nil, fmt.Errorf("failed to get stream: %w", fromStatusError(err))
 	}
 
 	// Create a channel to return completion chunks
@@ -69,11 +74,15 @@ func (c *Client) GetStream(ctx context.Context, req *completion.CompletionReques
 
 			// Convert pb.CompletionChunk to completion.CompletionChunk
 			chunk := &completion.CompletionChunk{
-				Content:          pbChunk.Content,
-				Done:             pbChunk.Done,
-				TokenUsage:       int(pbChunk.TokenUsage),
-				PromptTokens:     int(pbChunk.PromptTokens),
-				CompletionTokens: int(pbChunk.CompletionTokens),
+				Content:            pbChunk.Content,
+				ToolCalls:          fromPBToolCalls(pbChunk.ToolCalls),
+				Done:               pbChunk.Done,
+				FinishReason:       pbChunk.FinishReason,
+				TokenUsage:         int(pbChunk.TokenUsage),
+				PromptTokens:       int(pbChunk.PromptTokens),
+				CompletionTokens:   int(pbChunk.CompletionTokens),
+				CachedPromptTokens: int(pbChunk.CachedPromptTokens),
+				Endpoint:           pbChunk.Endpoint,
 			}
 			if pbChunk.Error != "" {
 				chunk.Error = fmt.Errorf("%s", pbChunk.Error)
@@ -103,11 +112,21 @@ func (c *Client) PoolStats(ctx context.Context) ([]completion.EndpointStatsSnaps
 			SuccessRate:  e.SuccessRate,
 			LatencyMs:    e.LatencyMsEwma,
 			BreakerState: e.BreakerState,
+			Hedges:       e.Hedges,
+			HedgeWins:    e.HedgeWins,
 		})
 	}
 	return out, nil
 }
 
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := c.client.ListModels(ctx, &pb.ListModelsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("ListModels rpc: %w", err)
+	}
+	return resp.Models, nil
+}
+
 func (c *Client) ListEndpoints(ctx context.Context) ([]completion.EndpointView, error) {
 	resp, err := c.admin.ListEndpoints(ctx, &pb.ListEndpointsRequest{})
 	if err != nil {
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"encoding/json"
+
+	"llm_gateway/completion"
+	pb "llm_gateway/completion/proto"
+)
+
+func toPBRequest(req *completion.CompletionRequest) *pb.CompletionRequest {
+	out := &pb.CompletionRequest{
+		Model:             req.Model,
+		Messages:          toPBMessages(req.Messages),
+		Temperature:       req.Temperature,
+		TopP:              req.TopP,
+		MaxTokens:         int32(req.MaxTokens),
+		Stop:              req.Stop,
+		PresencePenalty:   req.PresencePenalty,
+		FrequencyPenalty:  req.FrequencyPenalty,
+		Seed:              req.Seed,
+		LogitBias:         req.LogitBias,
+		User:              req.User,
+		ResponseFormat:    string(req.ResponseFormat),
+		Stream:            req.Stream,
+		ToolChoice:        string(req.ToolChoice),
+		ParallelToolCalls: req.ParallelToolCalls,
+	}
+	if req.MaxCompletionTokens != nil {
+		n := int32(*req.MaxCompletionTokens)
+		out.MaxCompletionTokens = &n
+	}
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, &pb.Tool{
+			Type:        t.Type,
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  string(t.Parameters),
+		})
+	}
+	return out
+}
+
+func toPBMessages(msgs []completion.Message) []*pb.ChatMessage {
+	out := make([]*pb.ChatMessage, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, &pb.ChatMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			Parts:      toPBParts(m.Parts),
+			Name:       m.Name,
+			ToolCalls:  toPBToolCalls(m.ToolCalls),
+			ToolCallId: m.ToolCallID,
+		})
+	}
+	return out
+}
+
+func toPBParts(parts []completion.ContentPart) []*pb.ContentPart {
+	if len(parts) == 0 {
+		return nil
+	}
+	out := make([]*pb.ContentPart, 0, len(parts))
+	for _, p := range parts {
+		out = append(out, &pb.ContentPart{
+			Type:        p.Type,
+			Text:        p.Text,
+			ImageUrl:    p.ImageURL,
+			ImageDetail: p.ImageDetail,
+			AudioData:   p.AudioData,
+			AudioFormat: p.AudioFormat,
+		})
+	}
+	return out
+}
+
+func fromPBParts(parts []*pb.ContentPart) []completion.ContentPart {
+	if len(parts) == 0 {
+		return nil
+	}
+	out := make([]completion.ContentPart, 0, len(parts))
+	for _, p := range parts {
+		out = append(out, completion.ContentPart{
+			Type:        p.Type,
+			Text:        p.Text,
+			ImageURL:    p.ImageUrl,
+			ImageDetail: p.ImageDetail,
+			AudioData:   p.AudioData,
+			AudioFormat: p.AudioFormat,
+		})
+	}
+	return out
+}
+
+func toPBToolCalls(calls []completion.ToolCall) []*pb.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]*pb.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, &pb.ToolCall{
+			Index:     int32(c.Index),
+			Id:        c.ID,
+			Type:      c.Type,
+			Name:      c.Name,
+			Arguments: c.Arguments,
+		})
+	}
+	return out
+}
+
+func fromPBToolCalls(calls []*pb.ToolCall) []completion.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]completion.ToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, completion.ToolCall{
+			Index:     int(c.Index),
+			ID:        c.Id,
+			Type:      c.Type,
+			Name:      c.Name,
+			Arguments: c.Arguments,
+		})
+	}
+	return out
+}
+
+// fromPBRequest converts the wire request back into the transport-neutral
+// shape. A request from an older gateway that only sets the deprecated
+// question field is treated as a single user turn.
+func fromPBRequest(req *pb.CompletionRequest) *completion.CompletionRequest {
+	out := &completion.CompletionRequest{
+		Model:             req.Model,
+		Temperature:       req.Temperature,
+		TopP:              req.TopP,
+		MaxTokens:         int(req.MaxTokens),
+		Stop:              req.Stop,
+		PresencePenalty:   req.PresencePenalty,
+		FrequencyPenalty:  req.FrequencyPenalty,
+		Seed:              req.Seed,
+		LogitBias:         req.LogitBias,
+		User:              req.User,
+		Stream:            req.Stream,
+		ParallelToolCalls: req.ParallelToolCalls,
+		Messages:          make([]completion.Message, 0, len(req.Messages)),
+	}
+	if req.MaxCompletionTokens != nil {
+		n := int(*req.MaxCompletionTokens)
+		out.MaxCompletionTokens = &n
+	}
+	if req.ResponseFormat != "" {
+		out.ResponseFormat = json.RawMessage(req.ResponseFormat)
+	}
+	for _, m := range req.Messages {
+		out.Messages = append(out.Messages, completion.Message{
+			Role:       m.Role,
+			Content:    m.Content,
+			Parts:      fromPBParts(m.Parts),
+			Name:       m.Name,
+			ToolCalls:  fromPBToolCalls(m.ToolCalls),
+			ToolCallID: m.ToolCallId,
+		})
+	}
+	if len(out.Messages) == 0 && req.Question != "" {
+		out.Messages = append(out.Messages, completion.Message{Role: "user", Content: req.Question})
+	}
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, completion.Tool{
+			Type:        t.Type,
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  json.RawMessage(t.Parameters),
+		})
+	}
+	if req.ToolChoice != "" {
+		out.ToolChoice = json.RawMessage(req.ToolChoice)
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"llm_gateway/completion"
+)
+
+// errorDomain tags the ErrorInfo detail that carries a *completion.Error
+// across the wire.
+const (
+	errorDomain = "completion"
+	errorReason = "UPSTREAM_ERROR"
+)
+
+// toStatusError encodes err as a gRPC status. A *completion.Error keeps its
+// upstream HTTP status, type, code and param in an ErrorInfo detail so the
+// client can rebuild it; anything else becomes a bare status.
+func toStatusError(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+
+	var upstreamErr *completion.Error
+	if !errors.As(err, &upstreamErr) {
+		return status.Error(code, err.Error())
+	}
+	if code == codes.Unknown {
+		code = codeForHTTPStatus(upstreamErr.StatusCode)
+	}
+	message := upstreamErr.Message
+	if upstreamErr.StatusCode == 0 {
+		message = upstreamErr.Error()
+	}
+
+	st := status.New(code, message)
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: errorReason,
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"status_code": strconv.Itoa(upstreamErr.StatusCode),
+			"type":        upstreamErr.Type,
+			"code":        upstreamErr.Code,
+			"param":       upstreamErr.Param,
+		},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// fromStatusError is the inverse of toStatusError. Statuses without our
+// ErrorInfo detail are returned unchanged, except that Canceled and
+// DeadlineExceeded wrap their context counterparts.
+func fromStatusError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != errorDomain {
+			continue
+		}
+		statusCode, _ := strconv.Atoi(info.Metadata["status_code"])
+		return &completion.Error{
+			StatusCode: statusCode,
+			Type:       info.Metadata["type"],
+			Code:       info.Metadata["code"],
+			Param:      info.Metadata["param"],
+			Message:    st.Message(),
+		}
+	}
+	switch st.Code() {
+	case codes.Canceled:
+		return fmt.Errorf("%s: %w", st.Message(), context.Canceled)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%s: %w", st.Message(), context.DeadlineExceeded)
+	}
+	return err
+}
+
+func codeForHTTPStatus(statusCode int) codes.Code {
+	switch {
+	case statusCode == 0, statusCode >= 500:
+		return codes.Unavailable
+	case statusCode == http.StatusBadRequest,
+		statusCode == http.StatusRequestEntityTooLarge,
+		statusCode == http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case statusCode == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case statusCode == http.StatusForbidden:
+		return codes.PermissionDenied
+	case statusCode == http.StatusNotFound:
+		return codes.NotFound
+	case statusCode == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	}
+	return codes.Unknown
+}
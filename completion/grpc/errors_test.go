@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"llm_gateway/completion"
+	pb "llm_gateway/completion/proto"
+)
+
+type fakeService struct {
+	err error
+}
+
+func (f fakeService) GetStream(context.Context, *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan *completion.CompletionChunk, 1)
+	ch <- &completion.CompletionChunk{Content: "hi", Done: true}
+	close(ch)
+	return ch, nil
+}
+
+func newBufClient(t *testing.T, svc completion.Service) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer()
+	pb.RegisterCompletionServiceServer(srv, NewServer(svc))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &Client{conn: conn, client: pb.NewCompletionServiceClient(conn)}
+}
+
+func TestGetStream_UpstreamErrorSurvivesGRPC(t *testing.T) {
+	want := &completion.Error{
+		StatusCode: 400,
+		Type:       "invalid_request_error",
+		Code:       "context_length_exceeded",
+		Param:      "messages",
+		Message:    "This model's maximum context length is 8192 tokens.",
+	}
+	c := newBufClient(t, fakeService{err: want})
+
+	_, err := c.GetStream(context.Background(), &completion.CompletionRequest{})
+
+	var got *completion.Error
+	if !errors.As(err, &got) {
+		t.Fatalf("error: got %v, want *completion.Error", err)
+	}
+	if *got != *want {
+		t.Errorf("error: got %+v, want %+v", got, want)
+	}
+}
+
+func TestGetStream_StreamsAfterHeaders(t *testing.T) {
+	c := newBufClient(t, fakeService{})
+
+	ch, err := c.GetStream(context.Background(), &completion.CompletionRequest{})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	chunk := <-ch
+	if chunk.Content != "hi" || !chunk.Done {
+		t.Errorf("chunk: got %+v", chunk)
+	}
+}
+
+func TestFromStatusError_ContextErrors(t *testing.T) {
+	err := fromStatusError(toStatusError(context.DeadlineExceeded))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"llm_gateway/completion"
@@ -14,6 +15,7 @@ type Server struct {
 	pb.UnimplementedCompletionServiceServer
 	completionService completion.Service
 	statsProvider     completion.StatsProvider // optional; nil = PoolStats returns Unimplemented
+	modelLister       completion.ModelLister   // optional; nil = ListModels returns Unimplemented
 }
 
 func NewServer(completionService completion.Service) *Server {
@@ -21,33 +23,39 @@ func NewServer(completionService completion.Service) *Server {
 	if sp, ok := completionService.(completion.StatsProvider); ok {
 		s.statsProvider = sp
 	}
+	if ml, ok := completionService.(completion.ModelLister); ok {
+		s.modelLister = ml
+	}
 	return s
 }
 
 func (s *Server) GetStream(req *pb.CompletionRequest, stream pb.CompletionService_GetStreamServer) error {
 	// Convert pb.CompletionRequest to completion.CompletionRequest
-	completionReq := &completion.CompletionRequest{
-		Model:       req.Model,
-		Question:    req.Question,
-		Temperature: req.Temperature,
-		MaxTokens:   int(req.MaxTokens),
-		Stream:      req.Stream,
-	}
+	completionReq := fromPBRequest(req)
 
 	// Call the completion service
 	chunkChan, err := s.completionService.GetStream(stream.Context(), completionReq)
 	if err != nil {
+		return toStatusError(err)
+	}
+	// Headers tell the client the upstream accepted the request; a failure
+	// before this point reaches it as a trailers-only status instead.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
 		return err
 	}
 
 	// Stream the chunks back to the client
 	for chunk := range chunkChan {
 		pbChunk := &pb.CompletionChunk{
-			Content:          chunk.Content,
-			Done:             chunk.Done,
-			TokenUsage:       int32(chunk.TokenUsage),
-			PromptTokens:     int32(chunk.PromptTokens),
-			CompletionTokens: int32(chunk.CompletionTokens),
+			Content:            chunk.Content,
+			ToolCalls:          toPBToolCalls(chunk.ToolCalls),
+			Done:               chunk.Done,
+			FinishReason:       chunk.FinishReason,
+			TokenUsage:         int32(chunk.TokenUsage),
+			PromptTokens:       int32(chunk.PromptTokens),
+			CompletionTokens:   int32(chunk.CompletionTokens),
+			CachedPromptTokens: int32(chunk.CachedPromptTokens),
+			Endpoint:           chunk.Endpoint,
 		}
 		if chunk.Error != nil {
 			pbChunk.Error = chunk.Error.Error()
@@ -81,7 +89,20 @@ func (s *Server) PoolStats(ctx context.Context, _ *pb.PoolStatsRequest) (*pb.Poo
 			SuccessRate:   s.SuccessRate,
 			LatencyMsEwma: s.LatencyMs,
 			BreakerState:  s.BreakerState,
+			Hedges:        s.Hedges,
+			HedgeWins:     s.HedgeWins,
 		})
 	}
 	return resp, nil
 }
+
+func (s *Server) ListModels(ctx context.Context, _ *pb.ListModelsRequest) (*pb.ListModelsResponse, error) {
+	if s.modelLister == nil {
+		return nil, status.Error(codes.Unimplemented, "completion service does not expose a model list")
+	}
+	models, err := s.modelLister.ListModels(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "ListModels: %v", err)
+	}
+	return &pb.ListModelsResponse{Models: models}, nil
+}
@@ -17,6 +17,8 @@ type EndpointStatsSnapshot struct {
 	SuccessRate  float64 `json:"success_rate"`
 	LatencyMs    float64 `json:"latency_ms_ewma"`
 	BreakerState string  `json:"breaker_state"`
+	Hedges       uint64  `json:"hedges"`
+	HedgeWins    uint64  `json:"hedge_wins"`
 }
 
 // StatsProvider is implemented by anything that can report per-endpoint stats — both
@@ -25,6 +27,13 @@ type StatsProvider interface {
 	PoolStats(ctx context.Context) ([]EndpointStatsSnapshot, error)
 }
 
+// ModelLister is implemented by anything that can report which models the upstream
+// pool serves — the in-process pool.Service and the gRPC client alike. Endpoints
+// that accept any model (empty list or "*") cannot be enumerated and contribute nothing.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
 // EndpointSpec is the transport-neutral shape for runtime endpoint additions.
 type EndpointSpec struct {
 	Name      string   `json:"name"`
@@ -5,11 +5,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"llm_gateway/completion"
 	"llm_gateway/internal/tracing"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -18,15 +20,25 @@ import (
 	"go.opentelemetry.io/otel/codes"
 )
 
+// ErrBuildRequest wraps failures to build the upstream HTTP request.
+var ErrBuildRequest = errors.New("fail to build upstream request")
+
 type OpenaiCompletionService struct {
 	client        *http.Client
+	timeouts      Timeouts
 	endpoint      string
 	apiKeyEnvName string
 }
 
 func New(endpoint string, apiKeyEnvName string) *OpenaiCompletionService {
+	return NewWithTimeouts(endpoint, apiKeyEnvName, Timeouts{})
+}
+
+func NewWithTimeouts(endpoint string, apiKeyEnvName string, timeouts Timeouts) *OpenaiCompletionService {
+	timeouts = timeouts.withDefaults()
 	return &OpenaiCompletionService{
-		client:        &http.Client{Timeout: time.Second * 30},
+		client:        newHTTPClient(timeouts),
+		timeouts:      timeouts,
 		endpoint:      endpoint,
 		apiKeyEnvName: apiKeyEnvName,
 	}
@@ -35,20 +47,33 @@ func New(endpoint string, apiKeyEnvName string) *OpenaiCompletionService {
 func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
 	upstreamReq, err := s.buildUpstreamRequest(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("fail to build upstream request: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrBuildRequest, err)
+	}
+
+	// callCtx carries the phase timeouts: each timer cancels it with its own
+	// sentinel as the cause, which callError turns back into the error.
+	callCtx, cancel := context.WithCancelCause(ctx)
+	totalTimer := time.AfterFunc(s.timeouts.Total, func() { cancel(ErrTotalTimeout) })
+	release := func() {
+		totalTimer.Stop()
+		cancel(nil)
 	}
 
 	// upstream.http span covers only the HTTP request/response-header phase.
 	// We end it before the SSE goroutine starts so the trace timeline isn't
 	// distorted by a span that lasts for the whole stream (can be 30s+).
-	httpCtx, httpSpan := tracing.Tracer("completion.openai").Start(ctx, "completion.upstream.http")
+	httpCtx, httpSpan := tracing.Tracer("completion.openai").Start(callCtx, "completion.upstream.http")
 	upstreamReq = upstreamReq.WithContext(httpCtx)
+	headerTimer := time.AfterFunc(s.timeouts.ResponseHeader, func() { cancel(ErrResponseHeaderTimeout) })
 	resp, err := s.client.Do(upstreamReq)
+	headerTimer.Stop()
 	if err != nil {
+		err = callError(callCtx, err)
 		httpSpan.RecordError(err)
 		httpSpan.SetStatus(codes.Error, "upstream call failed")
 		httpSpan.End()
-		return nil, fmt.Errorf("fail to call upstream api: %w", err)
+		release()
+		return nil, &completion.Error{Message: "fail to call upstream api", Err: err}
 	}
 	httpSpan.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
@@ -57,7 +82,8 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 		httpSpan.End()
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("upstream api returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		release()
+		return nil, parseErrorResponse(resp.StatusCode, bodyBytes)
 	}
 	httpSpan.End()
 
@@ -71,10 +97,24 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 	// parse SSE and add content to channel
 	go func() {
 		defer close(ch)
+		defer release()
 		defer resp.Body.Close()
 
 		reader := bufio.NewReader(resp.Body)
-		var totalTokens, promptTokens, completionTokens int
+		var totalTokens, promptTokens, completionTokens, cachedTokens int
+		var finishReason string
+		// Until the first token only the first-token budget applies, so a
+		// reasoning model may think silently; after it, every line re-arms
+		// the idle timer.
+		firstTokenTimer := time.AfterFunc(s.timeouts.FirstToken, func() { cancel(ErrFirstTokenTimeout) })
+		defer firstTokenTimer.Stop()
+		var idleTimer *time.Timer
+		defer func() {
+			if idleTimer != nil {
+				idleTimer.Stop()
+			}
+		}()
+
 		ttfbEnded := false
 		endTTFB := func(success bool) {
 			if ttfbEnded {
@@ -83,6 +123,9 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 			ttfbEnded = true
 			if !success {
 				ttfbSpan.SetStatus(codes.Error, "stream ended before first chunk")
+			} else {
+				firstTokenTimer.Stop()
+				idleTimer = time.AfterFunc(s.timeouts.Idle, func() { cancel(ErrIdleTimeout) })
 			}
 			ttfbSpan.End()
 		}
@@ -93,12 +136,14 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 			select {
 			case <-ctx.Done():
 				ch <- &completion.CompletionChunk{
-					Content:          "",
-					Error:            ctx.Err(),
-					Done:             true,
-					TokenUsage:       totalTokens,
-					PromptTokens:     promptTokens,
-					CompletionTokens: completionTokens,
+					Content:            "",
+					Error:              ctx.Err(),
+					Done:               true,
+					TokenUsage:         totalTokens,
+					PromptTokens:       promptTokens,
+					CompletionTokens:   completionTokens,
+					FinishReason:       finishReason,
+					CachedPromptTokens: cachedTokens,
 				}
 				return
 			default:
@@ -109,68 +154,85 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 				if err == io.EOF {
 					// Stream completed successfully
 					ch <- &completion.CompletionChunk{
-						Content:          "",
-						Error:            nil,
-						Done:             true,
-						TokenUsage:       totalTokens,
-						PromptTokens:     promptTokens,
-						CompletionTokens: completionTokens,
+						Content:            "",
+						Error:              nil,
+						Done:               true,
+						TokenUsage:         totalTokens,
+						PromptTokens:       promptTokens,
+						CompletionTokens:   completionTokens,
+						FinishReason:       finishReason,
+						CachedPromptTokens: cachedTokens,
 					}
 					return
 				}
 				// Read error
 				ch <- &completion.CompletionChunk{
-					Content:          "",
-					Error:            fmt.Errorf("failed to read from upstream: %w", err),
-					Done:             true,
-					TokenUsage:       totalTokens,
-					PromptTokens:     promptTokens,
-					CompletionTokens: completionTokens,
+					Content:            "",
+					Error:              fmt.Errorf("failed to read from upstream: %w", callError(callCtx, err)),
+					Done:               true,
+					TokenUsage:         totalTokens,
+					PromptTokens:       promptTokens,
+					CompletionTokens:   completionTokens,
+					FinishReason:       finishReason,
+					CachedPromptTokens: cachedTokens,
 				}
 				return
 			}
 
+			if idleTimer != nil {
+				idleTimer.Reset(s.timeouts.Idle)
+			}
+
 			// Skip blank lines
 			if len(line) == 0 || line[0] == '\n' || line[0] == '\r' {
 				continue
 			}
 
 			// Parse SSE line
-			content, done, pt, ct, tt, err := s.parseSSELine(line)
+			ev, err := s.parseSSELine(line)
 			if err != nil {
 				// Non-fatal parse error, continue
 				continue
 			}
 
 			// Update token counters as they arrive
-			if tt > 0 {
-				totalTokens = tt
+			if ev.totalTokens > 0 {
+				totalTokens = ev.totalTokens
+			}
+			if ev.promptTokens > 0 {
+				promptTokens = ev.promptTokens
+			}
+			if ev.completionTokens > 0 {
+				completionTokens = ev.completionTokens
 			}
-			if pt > 0 {
-				promptTokens = pt
+			if ev.cachedTokens > 0 {
+				cachedTokens = ev.cachedTokens
 			}
-			if ct > 0 {
-				completionTokens = ct
+			if ev.finishReason != "" {
+				finishReason = ev.finishReason
 			}
 
-			if content != "" {
+			if ev.content != "" || len(ev.toolCalls) > 0 {
 				endTTFB(true)
 				ch <- &completion.CompletionChunk{
-					Content:    content,
+					Content:    ev.content,
+					ToolCalls:  ev.toolCalls,
 					Error:      nil,
 					Done:       false,
 					TokenUsage: 0,
 				}
 			}
 
-			if done {
+			if ev.done {
 				ch <- &completion.CompletionChunk{
-					Content:          "",
-					Error:            nil,
-					Done:             true,
-					TokenUsage:       totalTokens,
-					PromptTokens:     promptTokens,
-					CompletionTokens: completionTokens,
+					Content:            "",
+					Error:              nil,
+					Done:               true,
+					TokenUsage:         totalTokens,
+					PromptTokens:       promptTokens,
+					CompletionTokens:   completionTokens,
+					FinishReason:       finishReason,
+					CachedPromptTokens: cachedTokens,
 				}
 				return
 			}
@@ -182,21 +244,56 @@ func (s *OpenaiCompletionService) GetStream(ctx context.Context, req *completion
 
 func (s *OpenaiCompletionService) buildUpstreamRequest(ctx context.Context, original_req *completion.CompletionRequest) (*http.Request, error) {
 	// build openai api format request
-	openaiReq := ChatCompleteionRequest{
-		Model: original_req.Model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: original_req.Question,
+	messages := make([]Message, 0, len(original_req.Messages))
+	for _, m := range original_req.Messages {
+		msg := Message{
+			Role:       m.Role,
+			Content:    messageContent(m),
+			Name:       m.Name,
+			ToolCallID: m.ToolCallID,
+		}
+		for _, tc := range m.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:       tc.ID,
+				Type:     toolType(tc.Type),
+				Function: FunctionCall{Name: tc.Name, Arguments: tc.Arguments},
+			})
+		}
+		messages = append(messages, msg)
+	}
+	var tools []Tool
+	for _, t := range original_req.Tools {
+		tools = append(tools, Tool{
+			Type: toolType(t.Type),
+			Function: ToolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.Parameters,
 			},
-		},
-		Temperature: original_req.Temperature,
-		MaxTokens:   original_req.MaxTokens,
-		Stream:      true,
+		})
+	}
+	openaiReq := ChatCompleteionRequest{
+		Model:               original_req.Model,
+		Messages:            messages,
+		Temperature:         original_req.Temperature,
+		TopP:                original_req.TopP,
+		MaxTokens:           original_req.MaxTokens,
+		MaxCompletionTokens: original_req.MaxCompletionTokens,
+		Stop:                original_req.Stop,
+		PresencePenalty:     original_req.PresencePenalty,
+		FrequencyPenalty:    original_req.FrequencyPenalty,
+		Seed:                original_req.Seed,
+		LogitBias:           original_req.LogitBias,
+		User:                original_req.User,
+		ResponseFormat:      original_req.ResponseFormat,
+		Stream:              true,
 		// Ask upstream to emit a final SSE chunk containing usage info.
 		// OpenAI's streaming API omits usage by default — without this flag
 		// the gateway can never forward token counts to the client.
-		StreamOptions: &StreamOptions{IncludeUsage: true},
+		StreamOptions:     &StreamOptions{IncludeUsage: true},
+		Tools:             tools,
+		ToolChoice:        original_req.ToolChoice,
+		ParallelToolCalls: original_req.ParallelToolCalls,
 	}
 
 	reqBodyBytes, err := json.Marshal(openaiReq)
@@ -209,6 +306,8 @@ func (s *OpenaiCompletionService) buildUpstreamRequest(ctx context.Context, orig
 	// (model + body size) is fine for debugging upstream call sizing.
 	slog.DebugContext(ctx, "upstream openai request built",
 		"model", openaiReq.Model,
+		"messages", len(openaiReq.Messages),
+		"tools", len(openaiReq.Tools),
 		"body_bytes", len(reqBodyBytes),
 	)
 
@@ -226,16 +325,104 @@ func (s *OpenaiCompletionService) buildUpstreamRequest(ctx context.Context, orig
 	return req, nil
 }
 
-// parseSSELine parses a single SSE line and returns
-// (content, isDone, promptTokens, completionTokens, totalTokens, error).
-func (s *OpenaiCompletionService) parseSSELine(line []byte) (string, bool, int, int, int, error) {
+// callError explains a failed call or body read. A phase timeout that
+// cancelled callCtx replaces the "context canceled" it produced, and a
+// transport timeout can only be a dial or TLS timeout (see newHTTPClient).
+// Cancellation by the caller is returned as-is.
+func callError(callCtx context.Context, err error) error {
+	switch cause := context.Cause(callCtx); cause {
+	case ErrResponseHeaderTimeout, ErrFirstTokenTimeout, ErrIdleTimeout, ErrTotalTimeout:
+		return cause
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrDialTimeout, err)
+	}
+	return err
+}
+
+// parseErrorResponse turns a non-200 upstream answer into a *completion.Error,
+// keeping the OpenAI error fields when the body has them and the raw body as
+// the message otherwise.
+func parseErrorResponse(statusCode int, body []byte) *completion.Error {
+	e := &completion.Error{StatusCode: statusCode, Message: string(body)}
+	var parsed ErrorResponse
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Error.Message == "" {
+		return e
+	}
+	e.Message = parsed.Error.Message
+	e.Type = parsed.Error.Type
+	e.Code = jsonScalarString(parsed.Error.Code)
+	e.Param = jsonScalarString(parsed.Error.Param)
+	return e
+}
+
+// jsonScalarString renders a string, number or null JSON value as a string;
+// providers disagree on whether error.code is a string or an integer.
+func jsonScalarString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// messageContent returns the string content, or the content-part array when
+// the turn is multimodal.
+func messageContent(m completion.Message) any {
+	if m.Parts == nil {
+		return m.Content
+	}
+	parts := make([]ContentPart, 0, len(m.Parts))
+	for _, p := range m.Parts {
+		part := ContentPart{Type: p.Type, Text: p.Text}
+		switch p.Type {
+		case "image_url":
+			part.ImageURL = &ImageURL{URL: p.ImageURL, Detail: p.ImageDetail}
+		case "input_audio":
+			part.InputAudio = &InputAudio{Data: p.AudioData, Format: p.AudioFormat}
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// toolType defaults an unset tool type to "function", the only kind the
+// chat completions API defines.
+func toolType(t string) string {
+	if t == "" {
+		return "function"
+	}
+	return t
+}
+
+// sseEvent is what a single SSE data line contributes to the stream.
+type sseEvent struct {
+	content          string
+	toolCalls        []completion.ToolCall
+	finishReason     string
+	done             bool
+	promptTokens     int
+	completionTokens int
+	totalTokens      int
+	cachedTokens     int
+}
+
+// parseSSELine parses a single SSE line into an sseEvent.
+func (s *OpenaiCompletionService) parseSSELine(line []byte) (sseEvent, error) {
 	if len(line) == 0 {
-		return "", false, 0, 0, 0, nil
+		return sseEvent{}, nil
 	}
 
 	// Check for "data: " prefix
 	if !bytes.HasPrefix(line, []byte("data: ")) {
-		return "", false, 0, 0, 0, fmt.Errorf("invalid SSE line, missing 'data: ' prefix")
+		return sseEvent{}, fmt.Errorf("invalid SSE line, missing 'data: ' prefix")
 	}
 
 	// Extract JSON part
@@ -243,34 +430,50 @@ func (s *OpenaiCompletionService) parseSSELine(line []byte) (string, bool, int,
 
 	// Check for [DONE] marker
 	if bytes.Equal(jsonBytes, []byte("[DONE]")) {
-		return "", true, 0, 0, 0, nil
+		return sseEvent{done: true}, nil
 	}
 
 	// Parse JSON response
 	var resp ChatStreamResponse
 	if err := json.Unmarshal(jsonBytes, &resp); err != nil {
-		return "", false, 0, 0, 0, fmt.Errorf("fail to unmarshal SSE json: %w", err)
+		return sseEvent{}, fmt.Errorf("fail to unmarshal SSE json: %w", err)
 	}
 
-	// Handle usage info (last block before [DONE] when stream_options.include_usage is set)
-	if resp.Usage != nil && (resp.Usage.TotalTokens != 0 || resp.Usage.PromptTokens != 0 || resp.Usage.CompletionTokens != 0) {
-		return "", false, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens, nil
+	// Usage comes on the last block before [DONE] when
+	// stream_options.include_usage is set. OpenAI sends it on a chunk of its
+	// own with no choices; other providers put it on the last choice chunk,
+	// so both are read from the same event.
+	var ev sseEvent
+	if resp.Usage != nil {
+		ev.promptTokens = resp.Usage.PromptTokens
+		ev.completionTokens = resp.Usage.CompletionTokens
+		ev.totalTokens = resp.Usage.TotalTokens
+		if resp.Usage.PromptTokensDetails != nil {
+			ev.cachedTokens = resp.Usage.PromptTokensDetails.CachedTokens
+		}
 	}
 
 	// Extract content from choices
 	if len(resp.Choices) == 0 {
-		return "", false, 0, 0, 0, nil
+		return ev, nil
 	}
 
-	choice := resp.Choices[0]
-
 	// finish_reason marks the end of generated content, but the upstream may
 	// still emit one more chunk carrying usage info when stream_options
 	// include_usage=true is set. We rely on either [DONE] or EOF (handled by
-	// the caller) to terminate; finish_reason alone is not a terminator.
-	if choice.FinishReason != "" {
-		return "", false, 0, 0, 0, nil
+	// the caller) to terminate; finish_reason alone is not a terminator, so it
+	// is remembered and reported on the final Done chunk. Any delta riding on
+	// the same chunk is still forwarded.
+	delta := resp.Choices[0].Delta
+	ev.content, ev.finishReason = delta.Content, resp.Choices[0].FinishReason
+	for _, tc := range delta.ToolCalls {
+		ev.toolCalls = append(ev.toolCalls, completion.ToolCall{
+			Index:     tc.Index,
+			ID:        tc.ID,
+			Type:      tc.Type,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
 	}
-
-	return choice.Delta.Content, false, 0, 0, 0, nil
+	return ev, nil
 }
@@ -0,0 +1,302 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"llm_gateway/completion"
+)
+
+// newCapturingUpstream returns a server that records the decoded request body
+// and answers with a single content delta followed by [DONE].
+func newCapturingUpstream(t *testing.T, got *ChatCompleteionRequest) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Errorf("decode upstream body: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func drain(ch <-chan *completion.CompletionChunk) {
+	for range ch {
+	}
+}
+
+func TestGetStream_ForwardsStructuredMessages(t *testing.T) {
+	var got ChatCompleteionRequest
+	srv := newCapturingUpstream(t, &got)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	svc := New(srv.URL, "TEST_OPENAI_KEY")
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{
+		Model: "m",
+		Messages: []completion.Message{
+			{Role: "system", Content: "be brief"},
+			{Role: "user", Content: "hi", Name: "alice"},
+			{Role: "assistant", Content: "hello"},
+			{Role: "user", Content: "bye"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	drain(ch)
+
+	want := []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "hi", Name: "alice"},
+		{Role: "assistant", Content: "hello"},
+		{Role: "user", Content: "bye"},
+	}
+	if len(got.Messages) != len(want) {
+		t.Fatalf("messages: got %d, want %d (%+v)", len(got.Messages), len(want), got.Messages)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got.Messages[i], want[i]) {
+			t.Errorf("message[%d]: got %+v, want %+v", i, got.Messages[i], want[i])
+		}
+	}
+}
+
+func TestGetStream_ForwardsToolsAndParsesToolCallDeltas(t *testing.T) {
+	var got ChatCompleteionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode upstream body: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"f\",\"arguments\":\"\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{}\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	svc := New(srv.URL, "TEST_OPENAI_KEY")
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{
+		Model: "m",
+		Messages: []completion.Message{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", ToolCalls: []completion.ToolCall{{ID: "call_0", Name: "f", Arguments: "{}"}}},
+			{Role: "tool", Content: "done", ToolCallID: "call_0"},
+		},
+		Tools:      []completion.Tool{{Name: "f", Parameters: json.RawMessage(`{"type":"object"}`)}},
+		ToolChoice: json.RawMessage(`"required"`),
+	})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var deltas []completion.ToolCall
+	for c := range ch {
+		deltas = append(deltas, c.ToolCalls...)
+	}
+
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "f" {
+		t.Errorf("tools: got %+v", got.Tools)
+	}
+	if string(got.ToolChoice) != `"required"` {
+		t.Errorf("tool_choice: got %s", got.ToolChoice)
+	}
+	if tc := got.Messages[1].ToolCalls; len(tc) != 1 || tc[0].Type != "function" || tc[0].Function.Name != "f" {
+		t.Errorf("assistant tool_calls: got %+v", tc)
+	}
+	if got.Messages[2].ToolCallID != "call_0" {
+		t.Errorf("tool_call_id: got %q", got.Messages[2].ToolCallID)
+	}
+
+	want := []completion.ToolCall{
+		{Index: 0, ID: "call_1", Type: "function", Name: "f"},
+		{Index: 0, Arguments: "{}"},
+	}
+	if !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas: got %+v, want %+v", deltas, want)
+	}
+}
+
+func TestGetStream_ForwardsContentParts(t *testing.T) {
+	srvBody := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		srvBody <- body
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	svc := New(srv.URL, "TEST_OPENAI_KEY")
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{
+		Model: "m",
+		Messages: []completion.Message{{Role: "user", Parts: []completion.ContentPart{
+			{Type: "text", Text: "describe"},
+			{Type: "image_url", ImageURL: "https://x/cat.png", ImageDetail: "low"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	drain(ch)
+
+	body := <-srvBody
+	got, _ := json.Marshal(body["messages"].([]any)[0].(map[string]any)["content"])
+	want := `[{"text":"describe","type":"text"},{"image_url":{"detail":"low","url":"https://x/cat.png"},"type":"image_url"}]`
+	if string(got) != want {
+		t.Errorf("content:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestBuildUpstreamRequest_ExplicitZeroTemperatureIsSent(t *testing.T) {
+	zero := 0.0
+	svc := New("http://upstream.invalid", "TEST_OPENAI_KEY")
+
+	for _, tc := range []struct {
+		name        string
+		temperature *float64
+		want        bool
+	}{
+		{"unset", nil, false},
+		{"zero", &zero, true},
+	} {
+		req, err := svc.buildUpstreamRequest(context.Background(), &completion.CompletionRequest{
+			Model:       "m",
+			Messages:    []completion.Message{{Role: "user", Content: "hi"}},
+			Temperature: tc.temperature,
+			Stop:        []string{"END"},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode: %v", tc.name, err)
+		}
+		if _, ok := body["temperature"]; ok != tc.want {
+			t.Errorf("%s: temperature present = %v, want %v", tc.name, ok, tc.want)
+		}
+		if _, ok := body["top_p"]; ok {
+			t.Errorf("%s: unset top_p must be omitted", tc.name)
+		}
+		if stop, _ := body["stop"].([]any); len(stop) != 1 || stop[0] != "END" {
+			t.Errorf("%s: stop: got %v", tc.name, body["stop"])
+		}
+	}
+}
+
+func TestGetStream_ReportsFinishReasonOnDoneChunk(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"length\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":1,\"total_tokens\":2}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	ch, err := New(srv.URL, "TEST_OPENAI_KEY").GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var last *completion.CompletionChunk
+	for c := range ch {
+		last = c
+	}
+	if last == nil || !last.Done || last.FinishReason != "length" || last.TokenUsage != 2 {
+		t.Fatalf("done chunk: got %+v", last)
+	}
+}
+
+// Some providers send usage on the last choice chunk rather than on a chunk
+// of its own; its delta and finish_reason must not be dropped.
+func TestGetStream_UsageOnLastChoiceChunk(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"b\"},\"finish_reason\":\"length\"}],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":2,\"total_tokens\":3}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	ch, err := New(srv.URL, "TEST_OPENAI_KEY").GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var content string
+	var last *completion.CompletionChunk
+	for c := range ch {
+		content += c.Content
+		last = c
+	}
+	if content != "ab" {
+		t.Errorf("content: got %q, want %q", content, "ab")
+	}
+	if last == nil || !last.Done || last.FinishReason != "length" || last.TokenUsage != 3 || last.CompletionTokens != 2 {
+		t.Fatalf("done chunk: got %+v", last)
+	}
+}
+
+func TestGetStream_ReportsCachedPromptTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":100,\"completion_tokens\":1,\"total_tokens\":101,\"prompt_tokens_details\":{\"cached_tokens\":64}}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	ch, err := New(srv.URL, "TEST_OPENAI_KEY").GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var last *completion.CompletionChunk
+	for c := range ch {
+		last = c
+	}
+	if last == nil || last.PromptTokens != 100 || last.CachedPromptTokens != 64 {
+		t.Fatalf("done chunk: got %+v", last)
+	}
+}
+
+func TestGetStream_ReturnsTypedUpstreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"too long","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+
+	_, err := New(srv.URL, "TEST_OPENAI_KEY").GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+
+	var got *completion.Error
+	if !errors.As(err, &got) {
+		t.Fatalf("error: got %v, want *completion.Error", err)
+	}
+	want := completion.Error{StatusCode: 400, Type: "invalid_request_error", Code: "context_length_exceeded", Param: "messages", Message: "too long"}
+	if *got != want {
+		t.Errorf("error: got %+v, want %+v", *got, want)
+	}
+}
+
+func TestParseErrorResponse_NonOpenAIBodies(t *testing.T) {
+	got := parseErrorResponse(http.StatusTooManyRequests, []byte(`{"error":{"message":"slow down","code":1302}}`))
+	if got.Code != "1302" || got.Message != "slow down" {
+		t.Errorf("numeric code: got %+v", got)
+	}
+
+	got = parseErrorResponse(http.StatusBadGateway, []byte("<html>bad gateway</html>"))
+	if got.StatusCode != http.StatusBadGateway || got.Message != "<html>bad gateway</html>" {
+		t.Errorf("raw body: got %+v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Each phase of an upstream call fails with its own sentinel so the pool can
+// report which budget ran out.
+var (
+	ErrDialTimeout           = errors.New("upstream dial timeout")
+	ErrResponseHeaderTimeout = errors.New("upstream response header timeout")
+	ErrFirstTokenTimeout     = errors.New("upstream first token timeout")
+	ErrIdleTimeout           = errors.New("upstream idle timeout")
+	ErrTotalTimeout          = errors.New("upstream total timeout")
+)
+
+// Timeouts bounds the phases of one streaming call. A zero field takes the
+// default; there is deliberately no whole-body http.Client.Timeout, since a
+// healthy stream from a reasoning model can run for minutes.
+type Timeouts struct {
+	Dial           time.Duration // TCP connect, and separately the TLS handshake
+	ResponseHeader time.Duration // request sent until response headers
+	FirstToken     time.Duration // response headers until the first content or tool-call delta
+	Idle           time.Duration // longest gap between SSE lines once tokens flow
+	Total          time.Duration // whole call, request through [DONE]
+}
+
+const (
+	defaultDialTimeout           = 10 * time.Second
+	defaultResponseHeaderTimeout = 60 * time.Second
+	defaultFirstTokenTimeout     = 3 * time.Minute
+	defaultIdleTimeout           = 60 * time.Second
+	defaultTotalTimeout          = 15 * time.Minute
+)
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Dial <= 0 {
+		t.Dial = defaultDialTimeout
+	}
+	if t.ResponseHeader <= 0 {
+		t.ResponseHeader = defaultResponseHeaderTimeout
+	}
+	if t.FirstToken <= 0 {
+		t.FirstToken = defaultFirstTokenTimeout
+	}
+	if t.Idle <= 0 {
+		t.Idle = defaultIdleTimeout
+	}
+	if t.Total <= 0 {
+		t.Total = defaultTotalTimeout
+	}
+	return t
+}
+
+// newHTTPClient bounds only dial and TLS at the transport level; the later
+// phases are enforced per call by cancelling the request context with the
+// matching sentinel as its cause. A transport timeout is therefore always a
+// dial timeout.
+func newHTTPClient(t Timeouts) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: t.Dial, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = t.Dial
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"llm_gateway/completion"
+)
+
+// newSlowUpstream runs handle with a flushing writer. gone closes when the
+// client hangs up, which the server only notices once the request body has
+// been drained, so handlers never outlive the test.
+func newSlowUpstream(t *testing.T, handle func(w http.ResponseWriter, flush func(), gone <-chan struct{})) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		flush := func() { w.(http.Flusher).Flush() }
+		handle(w, flush, r.Context().Done())
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TEST_OPENAI_KEY", "k")
+	return srv
+}
+
+func lastChunkError(ch <-chan *completion.CompletionChunk) error {
+	var err error
+	for c := range ch {
+		if c.Error != nil {
+			err = c.Error
+		}
+	}
+	return err
+}
+
+func TestGetStream_ResponseHeaderTimeout(t *testing.T) {
+	srv := newSlowUpstream(t, func(_ http.ResponseWriter, _ func(), gone <-chan struct{}) {
+		<-gone
+	})
+	svc := NewWithTimeouts(srv.URL, "TEST_OPENAI_KEY", Timeouts{ResponseHeader: 50 * time.Millisecond})
+
+	_, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+
+	if !errors.Is(err, ErrResponseHeaderTimeout) {
+		t.Fatalf("error: got %v, want ErrResponseHeaderTimeout", err)
+	}
+}
+
+func TestGetStream_FirstTokenTimeout(t *testing.T) {
+	srv := newSlowUpstream(t, func(w http.ResponseWriter, flush func(), gone <-chan struct{}) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// A role-only delta is not a token.
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		flush()
+		<-gone
+	})
+	svc := NewWithTimeouts(srv.URL, "TEST_OPENAI_KEY", Timeouts{FirstToken: 50 * time.Millisecond})
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	if err := lastChunkError(ch); !errors.Is(err, ErrFirstTokenTimeout) {
+		t.Fatalf("chunk error: got %v, want ErrFirstTokenTimeout", err)
+	}
+}
+
+func TestGetStream_IdleTimeoutAfterFirstToken(t *testing.T) {
+	srv := newSlowUpstream(t, func(w http.ResponseWriter, flush func(), gone <-chan struct{}) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+		flush()
+		<-gone
+	})
+	svc := NewWithTimeouts(srv.URL, "TEST_OPENAI_KEY", Timeouts{Idle: 50 * time.Millisecond})
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	if err := lastChunkError(ch); !errors.Is(err, ErrIdleTimeout) {
+		t.Fatalf("chunk error: got %v, want ErrIdleTimeout", err)
+	}
+}
+
+// A steady trickle of tokens keeps resetting the idle timer, so only the
+// total budget can end the stream.
+func TestGetStream_TotalTimeoutOnSteadyStream(t *testing.T) {
+	srv := newSlowUpstream(t, func(w http.ResponseWriter, flush func(), gone <-chan struct{}) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		tick := time.NewTicker(10 * time.Millisecond)
+		defer tick.Stop()
+		for {
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+			flush()
+			select {
+			case <-gone:
+				return
+			case <-tick.C:
+			}
+		}
+	})
+	svc := NewWithTimeouts(srv.URL, "TEST_OPENAI_KEY", Timeouts{Idle: 100 * time.Millisecond, Total: 150 * time.Millisecond})
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	if err := lastChunkError(ch); !errors.Is(err, ErrTotalTimeout) {
+		t.Fatalf("chunk error: got %v, want ErrTotalTimeout", err)
+	}
+}
+
+func TestCallError_CallerCancellationIsNotATimeout(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(nil)
+
+	err := callError(ctx, context.Canceled)
+
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrDialTimeout) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
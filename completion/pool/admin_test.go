@@ -184,10 +184,12 @@ func TestAdmin_ResetBreaker_DisabledReturnsError(t *testing.T) {
 
 // Mutations during in-flight requests must not affect the snapshot taken at start.
 func TestAdmin_RemoveDuringInflightDoesNotPanic(t *testing.T) {
-	// Use a blocking client we can release on demand.
+	// Use a blocking client we can release on demand. The first chunk goes
+	// out at once: GetStream holds the stream until it sees content.
 	release := make(chan struct{})
 	blockedCh := make(chan *completion.CompletionChunk, 1)
 	go func() {
+		blockedCh <- &completion.CompletionChunk{Content: "first"}
 		<-release
 		blockedCh <- &completion.CompletionChunk{Content: "late"}
 		blockedCh <- &completion.CompletionChunk{Done: true}
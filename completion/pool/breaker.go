@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -74,6 +75,10 @@ func newBreaker(endpointName string, cfg BreakerConfig) (*gobreaker.CircuitBreak
 			failures := float64(c.TotalFailures) / float64(c.Requests)
 			return failures >= ratio
 		},
+		// Client faults and lost hedge races say nothing about the endpoint's health.
+		IsSuccessful: func(err error) bool {
+			return err == nil || isClientFault(err) || errors.Is(err, errHedgeLost)
+		},
 		OnStateChange: func(name string, from, to gobreaker.State) {
 			// Async callback — no request ctx is reachable here, so the log
 			// record carries no trace_id. That's expected: state transitions
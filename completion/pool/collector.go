@@ -17,6 +17,8 @@ type Collector struct {
 	descInFlight     *prometheus.Desc
 	descEWMAms       *prometheus.Desc
 	descBreakerState *prometheus.Desc
+	descHedges       *prometheus.Desc
+	descHedgeWins    *prometheus.Desc
 }
 
 func NewCollector(svc *Service) *Collector {
@@ -48,6 +50,16 @@ func NewCollector(svc *Service) *Collector {
 			"Circuit-breaker state per endpoint: -1=disabled, 0=closed, 1=half_open, 2=open.",
 			labels, nil,
 		),
+		descHedges: prometheus.NewDesc(
+			"completion_pool_hedges_total",
+			"Total hedged requests fired because the endpoint was slow to its first token.",
+			labels, nil,
+		),
+		descHedgeWins: prometheus.NewDesc(
+			"completion_pool_hedge_wins_total",
+			"Total hedged requests the endpoint served after another endpoint stalled.",
+			labels, nil,
+		),
 	}
 }
 
@@ -57,6 +69,8 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.descInFlight
 	ch <- c.descEWMAms
 	ch <- c.descBreakerState
+	ch <- c.descHedges
+	ch <- c.descHedgeWins
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
@@ -70,6 +84,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.descInFlight, prometheus.GaugeValue, float64(s.InFlight), s.Endpoint)
 		ch <- prometheus.MustNewConstMetric(c.descEWMAms, prometheus.GaugeValue, s.LatencyMs, s.Endpoint)
 		ch <- prometheus.MustNewConstMetric(c.descBreakerState, prometheus.GaugeValue, breakerStateNum(s.BreakerState), s.Endpoint)
+		ch <- prometheus.MustNewConstMetric(c.descHedges, prometheus.CounterValue, float64(s.Hedges), s.Endpoint)
+		ch <- prometheus.MustNewConstMetric(c.descHedgeWins, prometheus.CounterValue, float64(s.HedgeWins), s.Endpoint)
 	}
 }
 
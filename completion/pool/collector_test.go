@@ -51,9 +51,9 @@ func TestCollector_ExposesPoolStats(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(NewCollector(svc))
 
-	// 5 metric families × 2 endpoints = 10 series total.
-	if got := testutil.CollectAndCount(NewCollector(svc)); got != 10 {
-		t.Fatalf("expected 10 series, got %d", got)
+	// 7 metric families × 2 endpoints = 14 series total.
+	if got := testutil.CollectAndCount(NewCollector(svc)); got != 14 {
+		t.Fatalf("expected 14 series, got %d", got)
 	}
 
 	// Spot-check: success_total for a == 1, failure_total for b == 1.
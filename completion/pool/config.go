@@ -25,6 +25,7 @@ type Config struct {
 	Strategy    string           `json:"strategy"`
 	MaxAttempts int              `json:"max_attempts"`
 	Breaker     BreakerConfig    `json:"breaker"`
+	Hedge       HedgeConfig      `json:"hedge"`
 	Endpoints   []EndpointConfig `json:"endpoints"`
 }
 
@@ -115,6 +116,9 @@ func validate(cfg *Config) error {
 		if ep.Weight <= 0 {
 			ep.Weight = 1
 		}
+		if _, err := ep.Timeouts.resolved(); err != nil {
+			return fmt.Errorf("pool: endpoint %q: %w", ep.Name, err)
+		}
 		if ep.Enabled {
 			enabledCount++
 		}
@@ -135,5 +139,10 @@ func validate(cfg *Config) error {
 			return fmt.Errorf("pool: invalid breaker config: %w", err)
 		}
 	}
+	if cfg.Hedge.Enabled {
+		if _, _, err := cfg.Hedge.resolved(); err != nil {
+			return fmt.Errorf("pool: invalid hedge config: %w", err)
+		}
+	}
 	return nil
 }
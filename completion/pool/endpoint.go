@@ -2,19 +2,62 @@ package pool
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/sony/gobreaker"
 
 	"llm_gateway/completion"
+	"llm_gateway/completion/openai"
 )
 
 type EndpointConfig struct {
-	Name      string   `json:"name"`
-	URL       string   `json:"url"`
-	APIKeyEnv string   `json:"api_key_env"`
-	Weight    int      `json:"weight"`
-	Models    []string `json:"models,omitempty"`
-	Enabled   bool     `json:"enabled"`
+	Name      string        `json:"name"`
+	URL       string        `json:"url"`
+	APIKeyEnv string        `json:"api_key_env"`
+	Weight    int           `json:"weight"`
+	Models    []string      `json:"models,omitempty"`
+	Enabled   bool          `json:"enabled"`
+	Timeouts  TimeoutConfig `json:"timeouts,omitzero"`
+}
+
+// TimeoutConfig holds the per-phase upstream budgets as Go duration strings
+// ("10s", "2m"). Empty fields take the openai client defaults.
+type TimeoutConfig struct {
+	Dial           string `json:"dial,omitempty"`
+	ResponseHeader string `json:"response_header,omitempty"`
+	FirstToken     string `json:"first_token,omitempty"`
+	Idle           string `json:"idle,omitempty"`
+	Total          string `json:"total,omitempty"`
+}
+
+func (c TimeoutConfig) resolved() (openai.Timeouts, error) {
+	var t openai.Timeouts
+	fields := []struct {
+		name string
+		raw  string
+		dst  *time.Duration
+	}{
+		{"dial", c.Dial, &t.Dial},
+		{"response_header", c.ResponseHeader, &t.ResponseHeader},
+		{"first_token", c.FirstToken, &t.FirstToken},
+		{"idle", c.Idle, &t.Idle},
+		{"total", c.Total, &t.Total},
+	}
+	for _, f := range fields {
+		if f.raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.raw)
+		if err != nil {
+			return openai.Timeouts{}, fmt.Errorf("timeouts.%s: %w", f.name, err)
+		}
+		if d <= 0 {
+			return openai.Timeouts{}, fmt.Errorf("timeouts.%s: must be positive", f.name)
+		}
+		*f.dst = d
+	}
+	return t, nil
 }
 
 type upstreamClient interface {
@@ -0,0 +1,76 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HedgeConfig turns on hedged requests: when the endpoint serving an attempt
+// has not produced its first content chunk within the hedge delay, the same
+// request is fired at a second eligible endpoint and whichever stream starts
+// first is kept.
+type HedgeConfig struct {
+	Enabled bool `json:"enabled"`
+	// Delay is the fixed hedge delay, and the fallback while an endpoint has
+	// too few time-to-first-token samples for Percentile.
+	Delay string `json:"delay"`
+	// Percentile, when set, hedges once the call has been waiting longer than
+	// this quantile (0 < p < 1) of the endpoint's recent time to first token.
+	// 0, what an omitted field decodes to, means unset: only Delay applies.
+	Percentile float64 `json:"percentile"`
+}
+
+const (
+	defaultHedgeDelay = 2 * time.Second
+	// hedgeMinSamples is how many time-to-first-token samples an endpoint
+	// needs before its percentile replaces the fixed delay.
+	hedgeMinSamples = 20
+)
+
+// errHedgeLost is the cancel cause of the slower call in a hedged pair. It is
+// neither a success nor a failure of that endpoint.
+var errHedgeLost = errors.New("pool: hedged call lost the race")
+
+func (c HedgeConfig) resolved() (time.Duration, float64, error) {
+	delay := defaultHedgeDelay
+	if c.Delay != "" {
+		d, err := time.ParseDuration(c.Delay)
+		if err != nil {
+			return 0, 0, fmt.Errorf("hedge.delay: %w", err)
+		}
+		if d <= 0 {
+			return 0, 0, fmt.Errorf("hedge.delay: must be positive, got %s", c.Delay)
+		}
+		delay = d
+	}
+	if c.Percentile < 0 || c.Percentile >= 1 {
+		return 0, 0, fmt.Errorf("hedge.percentile: must be in (0, 1), or 0 for unset, got %g", c.Percentile)
+	}
+	return delay, c.Percentile, nil
+}
+
+// hedgePolicy is the resolved HedgeConfig; the zero value disables hedging.
+type hedgePolicy struct {
+	enabled    bool
+	delay      time.Duration
+	percentile float64
+}
+
+func newHedgePolicy(cfg HedgeConfig) hedgePolicy {
+	if !cfg.Enabled {
+		return hedgePolicy{}
+	}
+	delay, p, _ := cfg.resolved()
+	return hedgePolicy{enabled: true, delay: delay, percentile: p}
+}
+
+// delayFor returns how long to wait on ep before hedging.
+func (h hedgePolicy) delayFor(ep *Endpoint) time.Duration {
+	if h.percentile > 0 {
+		if d, ok := ep.Stats.ttftPercentile(h.percentile, hedgeMinSamples); ok {
+			return d
+		}
+	}
+	return h.delay
+}
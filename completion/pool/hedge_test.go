@@ -0,0 +1,178 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"llm_gateway/completion"
+)
+
+// stallClient opens a stream that never produces a chunk, and reports the
+// cause its call context was cancelled with.
+type stallClient struct {
+	cause chan error
+}
+
+func newStallClient() *stallClient { return &stallClient{cause: make(chan error, 1)} }
+
+func (s *stallClient) GetStream(ctx context.Context, _ *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
+	ch := make(chan *completion.CompletionChunk)
+	go func() {
+		<-ctx.Done()
+		s.cause <- context.Cause(ctx)
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func newHedgedService(t *testing.T, maxAttempts int, hedge HedgeConfig, clients map[string]upstreamClient) *Service {
+	t.Helper()
+	cfg := Config{
+		MaxAttempts: maxAttempts,
+		Hedge:       hedge,
+		Endpoints: []EndpointConfig{
+			{Name: "a", URL: "http://a", APIKeyEnv: "K", Enabled: true},
+			{Name: "b", URL: "http://b", APIKeyEnv: "K", Enabled: true},
+		},
+	}
+	svc, err := newFromConfig(cfg, func(c EndpointConfig) upstreamClient { return clients[c.Name] })
+	if err != nil {
+		t.Fatalf("newFromConfig: %v", err)
+	}
+	svc.selector = &orderedSelector{order: []string{"a", "b"}}
+	return svc
+}
+
+func endpointByName(svc *Service, name string) *Endpoint {
+	for _, ep := range svc.snapshotEndpoints() {
+		if ep.Cfg.Name == name {
+			return ep
+		}
+	}
+	return nil
+}
+
+func TestPool_HedgeWinsWhenPrimaryStalls(t *testing.T) {
+	a := newStallClient()
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("from b")}}}
+	svc := newHedgedService(t, 3, HedgeConfig{Enabled: true, Delay: "10ms"},
+		map[string]upstreamClient{"a": a, "b": b})
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var got string
+	for c := range ch {
+		got += c.Content
+	}
+	if got != "from b" {
+		t.Fatalf("content = %q, want the hedge's stream", got)
+	}
+
+	select {
+	case cause := <-a.cause:
+		if !errors.Is(cause, errHedgeLost) {
+			t.Fatalf("primary cancel cause = %v, want errHedgeLost", cause)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("primary call was not cancelled")
+	}
+
+	epA, epB := endpointByName(svc, "a"), endpointByName(svc, "b")
+	if n := epA.Stats.Hedges.Load(); n != 1 {
+		t.Errorf("a hedges = %d, want 1", n)
+	}
+	if n := epB.Stats.HedgeWins.Load(); n != 1 {
+		t.Errorf("b hedge wins = %d, want 1", n)
+	}
+	deadline := time.Now().Add(time.Second)
+	for epA.Stats.InFlight.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if in := epA.Stats.InFlight.Load(); in != 0 {
+		t.Errorf("a in-flight = %d after losing, want 0", in)
+	}
+	if f := epA.Stats.Failure.Load(); f != 0 {
+		t.Errorf("a failures = %d, want 0: losing a hedge is not a failure", f)
+	}
+}
+
+func TestPool_HedgeNotFiredWhenPrimaryIsFast(t *testing.T) {
+	a := &fakeClient{name: "a", queue: []fakeResult{{ch: makeChunkChan("from a")}}}
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("from b")}}}
+	svc := newHedgedService(t, 3, HedgeConfig{Enabled: true, Delay: "1s"},
+		map[string]upstreamClient{"a": a, "b": b})
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	for range ch {
+	}
+	if b.calls != 0 {
+		t.Fatalf("b called %d times, want no hedge", b.calls)
+	}
+	if n := endpointByName(svc, "a").Stats.Hedges.Load(); n != 0 {
+		t.Fatalf("a hedges = %d, want 0", n)
+	}
+}
+
+func TestPool_HedgeRespectsMaxAttempts(t *testing.T) {
+	a := newStallClient()
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("from b")}}}
+	svc := newHedgedService(t, 1, HedgeConfig{Enabled: true, Delay: "5ms"},
+		map[string]upstreamClient{"a": a, "b": b})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := svc.GetStream(ctx, &completion.CompletionRequest{}); err == nil {
+		t.Fatal("expected the stalled call to time out")
+	}
+	if b.calls != 0 {
+		t.Fatalf("b called %d times, want no hedge with max_attempts=1", b.calls)
+	}
+}
+
+func TestHedgePolicy_DelayFromPercentile(t *testing.T) {
+	ep := testEndpoint("a", 1, true, nil)
+	h := hedgePolicy{enabled: true, delay: time.Second, percentile: 0.9}
+
+	if d := h.delayFor(ep); d != time.Second {
+		t.Fatalf("without samples: delay = %v, want the fixed delay", d)
+	}
+	for i := 1; i <= 100; i++ {
+		ep.Stats.observeTTFT(time.Duration(i) * time.Millisecond)
+	}
+	if d := h.delayFor(ep); d != 90*time.Millisecond {
+		t.Fatalf("p90 delay = %v, want 90ms", d)
+	}
+}
+
+func TestPool_InvalidHedgeConfigRejected(t *testing.T) {
+	for name, hedge := range map[string]HedgeConfig{
+		"bad delay":      {Enabled: true, Delay: "soon"},
+		"negative delay": {Enabled: true, Delay: "-1s"},
+		"percentile 1":   {Enabled: true, Percentile: 1},
+		"negative pct":   {Enabled: true, Percentile: -0.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				Hedge:     hedge,
+				Endpoints: []EndpointConfig{{Name: "a", URL: "http://a", APIKeyEnv: "K", Enabled: true}},
+			}
+			if err := validate(&cfg); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestHedgeConfig_ZeroPercentileIsUnset(t *testing.T) {
+	delay, p, err := HedgeConfig{Enabled: true, Delay: "1s"}.resolved()
+	if err != nil || delay != time.Second || p != 0 {
+		t.Fatalf("resolved() = %v, %g, %v; want 1s, 0, nil", delay, p, err)
+	}
+}
@@ -61,7 +61,7 @@ func TestIntegration_FailoverAcrossUpstreams(t *testing.T) {
 	successes := 0
 	for i := range requests {
 		ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{
-			Model: "test", Question: "hi", MaxTokens: 16,
+			Model: "test", Messages: []completion.Message{{Role: "user", Content: "hi"}}, MaxTokens: 16,
 		})
 		if err != nil {
 			t.Errorf("req %d: GetStream failed: %v", i, err)
@@ -113,7 +113,7 @@ func TestIntegration_AllUpstreamsFailingReturnsError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewFromConfig: %v", err)
 	}
-	_, err = svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "x", Question: "y"})
+	_, err = svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "x", Messages: []completion.Message{{Role: "user", Content: "y"}}})
 	if err == nil {
 		t.Fatal("expected error when all upstreams fail")
 	}
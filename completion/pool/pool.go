@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"net"
+	"sort"
 	"sync"
 	"time"
 
 	"llm_gateway/completion"
 	"llm_gateway/completion/openai"
+	"llm_gateway/internal/metrics"
 	"llm_gateway/internal/tracing"
 
 	"github.com/sony/gobreaker"
@@ -26,6 +28,16 @@ func errorClass(err error) string {
 		return "none"
 	}
 	switch {
+	case errors.Is(err, openai.ErrDialTimeout):
+		return "timeout_dial"
+	case errors.Is(err, openai.ErrResponseHeaderTimeout):
+		return "timeout_response_header"
+	case errors.Is(err, openai.ErrFirstTokenTimeout):
+		return "timeout_first_token"
+	case errors.Is(err, openai.ErrIdleTimeout):
+		return "timeout_idle"
+	case errors.Is(err, openai.ErrTotalTimeout):
+		return "timeout_total"
 	case errors.Is(err, gobreaker.ErrOpenState):
 		return "breaker_open"
 	case errors.Is(err, gobreaker.ErrTooManyRequests):
@@ -35,21 +47,25 @@ func errorClass(err error) string {
 	case errors.Is(err, context.Canceled):
 		return "canceled"
 	}
-	s := err.Error()
+	var upstreamErr *completion.Error
+	if errors.As(err, &upstreamErr) {
+		switch {
+		case upstreamErr.StatusCode >= 500:
+			return "http_5xx"
+		case upstreamErr.StatusCode >= 400:
+			return "http_4xx"
+		case upstreamErr.StatusCode == 0:
+			return "network"
+		}
+	}
+	var netErr net.Error
 	switch {
-	case strings.Contains(s, "upstream api returned status 5"):
-		return "http_5xx"
-	case strings.Contains(s, "upstream api returned status 4"):
-		return "http_4xx"
-	case strings.Contains(s, "fail to call upstream api"),
-		strings.Contains(s, "connection refused"),
-		strings.Contains(s, "no such host"),
-		strings.Contains(s, "i/o timeout"):
+	case errors.As(err, &netErr):
 		return "network"
-	case strings.Contains(s, "fail to build upstream request"),
-		strings.Contains(s, "marshal"),
-		strings.Contains(s, "unmarshal"):
+	case errors.Is(err, openai.ErrBuildRequest):
 		return "parse_error"
+	case errors.Is(err, errStreamFailedEarly):
+		return "stream_failed"
 	}
 	return "other"
 }
@@ -63,12 +79,16 @@ type Service struct {
 	// Captured at construction so Admin.AddEndpoint / ResetBreaker can rebuild lazily.
 	factory    clientFactory
 	breakerCfg BreakerConfig
+	hedge      hedgePolicy
 }
 
 type clientFactory func(cfg EndpointConfig) upstreamClient
 
+// defaultClientFactory relies on validate having checked cfg.Timeouts;
+// endpoints added at runtime carry none and get the client defaults.
 func defaultClientFactory(cfg EndpointConfig) upstreamClient {
-	return openai.New(cfg.URL, cfg.APIKeyEnv)
+	timeouts, _ := cfg.Timeouts.resolved()
+	return openai.NewWithTimeouts(cfg.URL, cfg.APIKeyEnv, timeouts)
 }
 
 func NewFromConfig(cfg Config) (*Service, error) {
@@ -118,6 +138,7 @@ func newFromConfig(cfg Config, factory clientFactory) (*Service, error) {
 	slog.Info("pool initialized",
 		"strategy", sel.Name(),
 		"max_attempts", cfg.MaxAttempts,
+		"hedge", cfg.Hedge.Enabled,
 		"endpoints", names,
 	)
 
@@ -128,6 +149,7 @@ func newFromConfig(cfg Config, factory clientFactory) (*Service, error) {
 		maxAttempts: cfg.MaxAttempts,
 		factory:     factory,
 		breakerCfg:  cfg.Breaker,
+		hedge:       newHedgePolicy(cfg.Hedge),
 	}, nil
 }
 
@@ -139,6 +161,7 @@ func (s *Service) PoolStats(_ context.Context) ([]completion.EndpointStatsSnapsh
 	out := make([]completion.EndpointStatsSnapshot, 0, len(s.endpoints))
 	for _, ep := range s.endpoints {
 		in, succ, fail, rate, latMs := ep.Stats.snapshot()
+		hedges, hedgeWins := ep.Stats.hedgeCounts()
 		out = append(out, completion.EndpointStatsSnapshot{
 			Endpoint:     ep.Cfg.Name,
 			Weight:       ep.Cfg.Weight,
@@ -149,11 +172,39 @@ func (s *Service) PoolStats(_ context.Context) ([]completion.EndpointStatsSnapsh
 			SuccessRate:  rate,
 			LatencyMs:    latMs,
 			BreakerState: breakerStateName(ep.Breaker),
+			Hedges:       hedges,
+			HedgeWins:    hedgeWins,
 		})
 	}
 	return out, nil
 }
 
+// ListModels returns the sorted, de-duplicated union of the model lists declared
+// by enabled endpoints. Wildcard entries are skipped because they name no model.
+// Implements completion.ModelLister.
+func (s *Service) ListModels(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]struct{})
+	for _, ep := range s.endpoints {
+		if !ep.Cfg.Enabled {
+			continue
+		}
+		for _, m := range ep.Cfg.Models {
+			if m == "" || m == "*" {
+				continue
+			}
+			seen[m] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for m := range seen {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
 func (s *Service) snapshotEndpoints() []*Endpoint {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -201,31 +252,13 @@ func (s *Service) GetStream(ctx context.Context, req *completion.CompletionReque
 
 	tried := make(map[string]struct{}, s.maxAttempts)
 	var lastErr error
-	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+	streamFailovers := 0
+	// attempt counts launched calls, so a hedge uses up one of max_attempts.
+	for attempt := 0; attempt < s.maxAttempts; {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		candidates := s.applyFilters(ctx, req, snapshot)
-
-		tracing.AddEvent(ctx, "completion.retry.attempt",
-			attribute.Int("attempt", attempt),
-			attribute.Int("candidates", len(candidates)),
-			attribute.Int("tried_count", len(tried)),
-			attribute.String("strategy", s.selector.Name()),
-		)
-
-		_, selectSpan := tracing.Tracer("completion.pool").Start(ctx, "completion.pool.select")
-		ep, ok := s.selector.Pick(req, candidates, tried)
-		selectSpan.SetAttributes(
-			attribute.String("strategy", s.selector.Name()),
-			attribute.Int("attempt", attempt),
-			attribute.Int("candidates", len(candidates)),
-		)
-		if ok {
-			selectSpan.SetAttributes(attribute.String("endpoint", ep.Cfg.Name))
-		}
-		selectSpan.End()
-
+		ep, ok := s.pick(ctx, req, snapshot, tried, attempt)
 		if !ok {
 			tracing.AddEvent(ctx, "completion.retry.no_eligible",
 				attribute.Int("attempts_used", attempt),
@@ -236,40 +269,73 @@ func (s *Service) GetStream(ctx context.Context, req *completion.CompletionReque
 			}
 			return nil, errors.New("pool: no eligible endpoint")
 		}
-		tried[ep.Cfg.Name] = struct{}{}
 
-		tracing.AddEvent(ctx, "completion.endpoint.selected",
-			attribute.String("endpoint", ep.Cfg.Name),
-			attribute.String("breaker_state", breakerStateName(ep.Breaker)),
-			attribute.Int("attempt", attempt),
-		)
+		results := make(chan callResult, 2)
+		primary := launchCall(ctx, ep, req, attempt, results)
+		calls := []*call{primary}
+		attempt++
 
-		started := ep.Stats.start()
-		ch, err := callEndpoint(ctx, ep, req)
-		if err == nil {
-			tracing.AddEvent(ctx, "completion.retry.succeeded",
-				attribute.String("endpoint", ep.Cfg.Name),
-				attribute.Int("attempts_used", attempt+1),
-			)
-			slog.InfoContext(ctx, "pool served request",
-				"endpoint", ep.Cfg.Name, "attempt", attempt+1)
-			return wrapChannelForStats(ep, started, ch), nil
+		var hedgeAt <-chan time.Time
+		if s.hedge.enabled && attempt < s.maxAttempts {
+			hedgeAt = time.After(s.hedge.delayFor(ep))
+		}
+
+		for pending := 1; pending > 0; {
+			select {
+			case <-hedgeAt:
+				hedgeAt = nil
+				backup, ok := s.pick(ctx, req, snapshot, tried, attempt)
+				if !ok {
+					continue
+				}
+				ep.Stats.Hedges.Add(1)
+				tracing.AddEvent(ctx, "completion.hedge.fired",
+					attribute.String("endpoint", ep.Cfg.Name),
+					attribute.String("hedge_endpoint", backup.Cfg.Name),
+					attribute.Int64("waited_ms", time.Since(primary.started).Milliseconds()),
+				)
+				calls = append(calls, launchCall(ctx, backup, req, attempt, results))
+				attempt++
+				pending++
+
+			case res := <-results:
+				pending--
+				if res.err == nil {
+					abandonCalls(calls, res.call, results, pending)
+					winner := res.call
+					hedgeWon := winner != primary
+					if hedgeWon {
+						winner.ep.Stats.HedgeWins.Add(1)
+					}
+					winner.ep.Stats.observeTTFT(time.Since(winner.started))
+					tracing.AddEvent(ctx, "completion.retry.succeeded",
+						attribute.String("endpoint", winner.ep.Cfg.Name),
+						attribute.Int("attempts_used", attempt),
+						attribute.Int("stream_failovers", streamFailovers),
+						attribute.Bool("hedge_won", hedgeWon),
+					)
+					slog.InfoContext(ctx, "pool served request",
+						"endpoint", winner.ep.Cfg.Name, "attempt", winner.attempt+1, "hedge_won", hedgeWon)
+					return wrapChannelForStats(ctx, winner.ep, winner.started, res.ch), nil
+				}
+				if errors.Is(res.err, errStreamFailedEarly) {
+					streamFailovers++
+				}
+				recordCallFailure(ctx, res.call, res.err)
+				if isClientFault(res.err) {
+					// Every endpoint would reject the same request; hand the
+					// upstream's answer back instead of burning the other attempts.
+					abandonCalls(calls, res.call, results, pending)
+					return nil, res.err
+				}
+				lastErr = res.err
+			}
 		}
-		ep.Stats.end(started, true)
-		tracing.AddEvent(ctx, "completion.endpoint.failed",
-			attribute.String("endpoint", ep.Cfg.Name),
-			attribute.String("error_class", errorClass(err)),
-			attribute.String("error_msg", tracing.TruncateErr(err, 200)),
-			attribute.Int64("latency_ms", time.Since(started).Milliseconds()),
-			attribute.Int("attempt", attempt),
-		)
-		slog.InfoContext(ctx, "pool pre-stream error",
-			"endpoint", ep.Cfg.Name, "err", err, "attempt", attempt+1)
-		lastErr = err
 	}
 	tracing.AddEvent(ctx, "completion.retry.exhausted",
 		attribute.Int("attempts_used", s.maxAttempts),
 		attribute.String("last_error_class", errorClass(lastErr)),
+		attribute.Int("stream_failovers", streamFailovers),
 	)
 	if lastErr == nil {
 		lastErr = errors.New("unknown")
@@ -277,16 +343,141 @@ func (s *Service) GetStream(ctx context.Context, req *completion.CompletionReque
 	return nil, fmt.Errorf("pool: max_attempts=%d exhausted: %w", s.maxAttempts, lastErr)
 }
 
+// pick filters the snapshot and asks the selector for an untried endpoint,
+// marking it tried.
+func (s *Service) pick(ctx context.Context, req *completion.CompletionRequest, snapshot []*Endpoint, tried map[string]struct{}, attempt int) (*Endpoint, bool) {
+	candidates := s.applyFilters(ctx, req, snapshot)
+
+	tracing.AddEvent(ctx, "completion.retry.attempt",
+		attribute.Int("attempt", attempt),
+		attribute.Int("candidates", len(candidates)),
+		attribute.Int("tried_count", len(tried)),
+		attribute.String("strategy", s.selector.Name()),
+	)
+
+	_, selectSpan := tracing.Tracer("completion.pool").Start(ctx, "completion.pool.select")
+	ep, ok := s.selector.Pick(req, candidates, tried)
+	selectSpan.SetAttributes(
+		attribute.String("strategy", s.selector.Name()),
+		attribute.Int("attempt", attempt),
+		attribute.Int("candidates", len(candidates)),
+	)
+	if ok {
+		selectSpan.SetAttributes(attribute.String("endpoint", ep.Cfg.Name))
+	}
+	selectSpan.End()
+	if !ok {
+		return nil, false
+	}
+	tried[ep.Cfg.Name] = struct{}{}
+
+	tracing.AddEvent(ctx, "completion.endpoint.selected",
+		attribute.String("endpoint", ep.Cfg.Name),
+		attribute.String("breaker_state", breakerStateName(ep.Breaker)),
+		attribute.Int("attempt", attempt),
+	)
+	return ep, true
+}
+
+// call is one in-flight endpoint call. Each runs under its own context so the
+// loser of a hedge race can be cancelled without touching the winner.
+type call struct {
+	ep      *Endpoint
+	attempt int
+	started time.Time
+	cancel  context.CancelCauseFunc
+}
+
+type callResult struct {
+	call *call
+	ch   <-chan *completion.CompletionChunk
+	err  error
+}
+
+func launchCall(ctx context.Context, ep *Endpoint, req *completion.CompletionRequest, attempt int, results chan<- callResult) *call {
+	callCtx, cancel := context.WithCancelCause(ctx)
+	c := &call{ep: ep, attempt: attempt, started: ep.Stats.start(), cancel: cancel}
+	go func() {
+		ch, err := callEndpoint(callCtx, ep, req)
+		results <- callResult{call: c, ch: ch, err: err}
+	}()
+	return c
+}
+
+func recordCallFailure(ctx context.Context, c *call, err error) {
+	c.ep.Stats.end(c.started, true)
+	class := errorClass(err)
+	metrics.CompletionUpstreamErrors.WithLabelValues(c.ep.Cfg.Name, class, "pre_stream").Inc()
+	tracing.AddEvent(ctx, "completion.endpoint.failed",
+		attribute.String("endpoint", c.ep.Cfg.Name),
+		attribute.String("error_class", class),
+		attribute.String("error_msg", tracing.TruncateErr(err, 200)),
+		attribute.Int64("latency_ms", time.Since(c.started).Milliseconds()),
+		attribute.Int("attempt", c.attempt),
+		attribute.Bool("stream_opened", errors.Is(err, errStreamFailedEarly)),
+	)
+	slog.InfoContext(ctx, "pool pre-stream error",
+		"endpoint", c.ep.Cfg.Name, "err", err, "attempt", c.attempt+1)
+}
+
+// abandonCalls cancels every call but keep and settles the pending ones in the
+// background: a stream that opened anyway is drained, and only genuine
+// failures count against their endpoint.
+func abandonCalls(calls []*call, keep *call, results <-chan callResult, pending int) {
+	for _, c := range calls {
+		if c != keep {
+			c.cancel(errHedgeLost)
+		}
+	}
+	if pending == 0 {
+		return
+	}
+	go func() {
+		for range pending {
+			res := <-results
+			switch {
+			case res.err == nil:
+				go drain(res.ch)
+				res.call.ep.Stats.abandon()
+			case errors.Is(res.err, errHedgeLost):
+				res.call.ep.Stats.abandon()
+			default:
+				res.call.ep.Stats.end(res.call.started, true)
+			}
+		}
+	}()
+}
+
+// isClientFault reports whether err is an upstream rejection of the request
+// itself rather than of the endpoint that served it.
+func isClientFault(err error) bool {
+	var upstreamErr *completion.Error
+	return errors.As(err, &upstreamErr) && upstreamErr.ClientFault()
+}
+
 // wrapChannelForStats forwards chunks while tracking success/failure + latency.
-// First chunk.Error (or context.Canceled drain) marks the call failed.
-func wrapChannelForStats(ep *Endpoint, started time.Time, src <-chan *completion.CompletionChunk) <-chan *completion.CompletionChunk {
+// First chunk.Error (or context.Canceled drain) marks the call failed and is
+// reported with its error class, so mid-stream timeouts show up per phase.
+// The Done chunk is stamped with the endpoint name for per-endpoint pricing.
+func wrapChannelForStats(ctx context.Context, ep *Endpoint, started time.Time, src <-chan *completion.CompletionChunk) <-chan *completion.CompletionChunk {
 	out := make(chan *completion.CompletionChunk, cap(src))
 	go func() {
 		defer close(out)
 		errored := false
 		for c := range src {
-			if c != nil && c.Error != nil {
+			if c != nil && c.Error != nil && !errored {
 				errored = true
+				class := errorClass(c.Error)
+				metrics.CompletionUpstreamErrors.WithLabelValues(ep.Cfg.Name, class, "mid_stream").Inc()
+				tracing.AddEvent(ctx, "completion.stream.failed",
+					attribute.String("endpoint", ep.Cfg.Name),
+					attribute.String("error_class", class),
+					attribute.String("error_msg", tracing.TruncateErr(c.Error, 200)),
+					attribute.Int64("latency_ms", time.Since(started).Milliseconds()),
+				)
+			}
+			if c != nil && c.Done {
+				c.Endpoint = ep.Cfg.Name
 			}
 			out <- c
 		}
@@ -297,10 +488,10 @@ func wrapChannelForStats(ep *Endpoint, started time.Time, src <-chan *completion
 
 func callEndpoint(ctx context.Context, ep *Endpoint, req *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
 	if ep.Breaker == nil {
-		return ep.Client.GetStream(ctx, req)
+		return openStream(ctx, ep, req)
 	}
 	res, err := ep.Breaker.Execute(func() (any, error) {
-		return ep.Client.GetStream(ctx, req)
+		return openStream(ctx, ep, req)
 	})
 	if err != nil {
 		// Surface the synchronous breaker rejection paths as a distinct event.
@@ -318,3 +509,69 @@ func callEndpoint(ctx context.Context, ep *Endpoint, req *completion.CompletionR
 	ch, _ := res.(<-chan *completion.CompletionChunk)
 	return ch, nil
 }
+
+// errStreamFailedEarly marks a stream that opened but failed before its first
+// content chunk; nothing has reached the caller yet, so it is retried like a
+// pre-stream error.
+var errStreamFailedEarly = errors.New("pool: stream failed before first content chunk")
+
+// openStream calls the endpoint and holds its stream back until the first
+// content chunk, tool-call delta or Done arrives. Runs inside the breaker so
+// an early stream failure counts against the endpoint.
+func openStream(ctx context.Context, ep *Endpoint, req *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
+	// A call cancelled for losing a hedge race reports errHedgeLost, whatever
+	// the client made of the cancellation, so the breaker does not count it.
+	lost := func(err error) error {
+		if errors.Is(context.Cause(ctx), errHedgeLost) {
+			return errHedgeLost
+		}
+		return err
+	}
+	ch, err := ep.Client.GetStream(ctx, req)
+	if err != nil {
+		return nil, lost(err)
+	}
+	var head []*completion.CompletionChunk
+	for {
+		select {
+		case <-ctx.Done():
+			go drain(ch)
+			return nil, lost(ctx.Err())
+		case c, ok := <-ch:
+			if !ok {
+				return nil, lost(fmt.Errorf("%w: stream closed", errStreamFailedEarly))
+			}
+			if c == nil {
+				continue
+			}
+			if c.Error != nil {
+				go drain(ch)
+				return nil, lost(fmt.Errorf("%w: %w", errStreamFailedEarly, c.Error))
+			}
+			head = append(head, c)
+			if c.Content != "" || len(c.ToolCalls) > 0 || c.Done {
+				return replay(head, ch), nil
+			}
+		}
+	}
+}
+
+// replay yields head and then everything left on src.
+func replay(head []*completion.CompletionChunk, src <-chan *completion.CompletionChunk) <-chan *completion.CompletionChunk {
+	out := make(chan *completion.CompletionChunk, max(cap(src), len(head)))
+	go func() {
+		defer close(out)
+		for _, c := range head {
+			out <- c
+		}
+		for c := range src {
+			out <- c
+		}
+	}()
+	return out
+}
+
+func drain(ch <-chan *completion.CompletionChunk) {
+	for range ch {
+	}
+}
@@ -3,11 +3,15 @@ package pool
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"llm_gateway/completion"
+	"llm_gateway/completion/openai"
 
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -99,6 +103,9 @@ func TestPool_RetriesOnSyncError(t *testing.T) {
 	if got.Content != "ok" {
 		t.Fatalf("expected content 'ok', got %q", got.Content)
 	}
+	if done := <-ch; !done.Done || done.Endpoint != "b" {
+		t.Fatalf("done chunk: got %+v, want it stamped with the serving endpoint", done)
+	}
 	if a.calls != 1 || b.calls != 1 {
 		t.Fatalf("expected a=1 b=1, got a=%d b=%d", a.calls, b.calls)
 	}
@@ -225,6 +232,33 @@ func TestPool_InlineJSONParsed(t *testing.T) {
 	}
 }
 
+func TestPool_EndpointTimeoutsParsed(t *testing.T) {
+	t.Setenv(envPoolConfigFile, "")
+	t.Setenv(envPoolConfig, `{"endpoints":[{"name":"a","url":"http://a","api_key_env":"K","enabled":true,
+        "timeouts":{"dial":"5s","first_token":"2m","idle":"45s"}}]}`)
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv: %v", err)
+	}
+	got, err := cfg.Endpoints[0].Timeouts.resolved()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := openai.Timeouts{Dial: 5 * time.Second, FirstToken: 2 * time.Minute, Idle: 45 * time.Second}
+	if got != want {
+		t.Fatalf("timeouts: got %+v, want %+v", got, want)
+	}
+}
+
+func TestPool_InvalidEndpointTimeoutRejected(t *testing.T) {
+	t.Setenv(envPoolConfigFile, "")
+	t.Setenv(envPoolConfig, `{"endpoints":[{"name":"a","url":"http://a","api_key_env":"K","enabled":true,"timeouts":{"idle":"soon"}}]}`)
+	_, err := LoadConfigFromEnv()
+	if err == nil || !strings.Contains(err.Error(), "timeouts.idle") {
+		t.Fatalf("expected timeouts.idle error, got %v", err)
+	}
+}
+
 func TestPool_DuplicateNameRejected(t *testing.T) {
 	t.Setenv(envPoolConfigFile, "")
 	t.Setenv(envPoolConfig, `{
@@ -238,6 +272,127 @@ func TestPool_DuplicateNameRejected(t *testing.T) {
 	}
 }
 
+func TestPool_DoesNotRetryClientFault(t *testing.T) {
+	rejected := &completion.Error{StatusCode: 400, Code: "context_length_exceeded", Message: "too long"}
+	a := &fakeClient{name: "a", queue: []fakeResult{{err: rejected}}}
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("ok")}}}
+
+	svc := &Service{
+		endpoints: []*Endpoint{
+			testEndpoint("a", 1, true, a),
+			testEndpoint("b", 1, true, b),
+		},
+		selector:    &orderedSelector{order: []string{"a", "b"}},
+		maxAttempts: 3,
+	}
+
+	_, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if !errors.Is(err, rejected) {
+		t.Fatalf("error: got %v, want the upstream rejection", err)
+	}
+	if a.calls != 1 || b.calls != 0 {
+		t.Fatalf("expected a=1 b=0, got a=%d b=%d", a.calls, b.calls)
+	}
+}
+
+func chunkChan(chunks ...*completion.CompletionChunk) <-chan *completion.CompletionChunk {
+	ch := make(chan *completion.CompletionChunk, len(chunks))
+	for _, c := range chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch
+}
+
+func TestPool_FailsOverWhenStreamDiesBeforeContent(t *testing.T) {
+	cases := map[string]<-chan *completion.CompletionChunk{
+		"error chunk":   chunkChan(&completion.CompletionChunk{Error: errors.New("connection reset"), Done: true}),
+		"closed silent": chunkChan(),
+	}
+	for name, broken := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := &fakeClient{name: "a", queue: []fakeResult{{ch: broken}}}
+			b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("ok")}}}
+			svc := &Service{
+				endpoints: []*Endpoint{
+					testEndpoint("a", 1, true, a),
+					testEndpoint("b", 1, true, b),
+				},
+				selector:    &orderedSelector{order: []string{"a", "b"}},
+				maxAttempts: 3,
+			}
+
+			ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+			if err != nil {
+				t.Fatalf("GetStream: %v", err)
+			}
+			if got := <-ch; got.Content != "ok" {
+				t.Fatalf("first chunk: got %+v", got)
+			}
+			if a.calls != 1 || b.calls != 1 {
+				t.Fatalf("expected a=1 b=1, got a=%d b=%d", a.calls, b.calls)
+			}
+		})
+	}
+}
+
+func TestPool_EarlyStreamFailureRespectsMaxAttempts(t *testing.T) {
+	broken := func() *fakeClient {
+		return &fakeClient{queue: []fakeResult{{ch: chunkChan(&completion.CompletionChunk{Error: errors.New("reset"), Done: true})}}}
+	}
+	a, b := broken(), broken()
+	svc := &Service{
+		endpoints: []*Endpoint{
+			testEndpoint("a", 1, true, a),
+			testEndpoint("b", 1, true, b),
+		},
+		selector:    &orderedSelector{order: []string{"a", "b"}},
+		maxAttempts: 1,
+	}
+
+	_, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if !errors.Is(err, errStreamFailedEarly) {
+		t.Fatalf("error: got %v, want errStreamFailedEarly", err)
+	}
+	if a.calls != 1 || b.calls != 0 {
+		t.Fatalf("expected a=1 b=0, got a=%d b=%d", a.calls, b.calls)
+	}
+}
+
+// Once content has been forwarded a later error is the caller's to see;
+// replaying from another endpoint would duplicate output.
+func TestPool_NoFailoverAfterFirstContent(t *testing.T) {
+	midErr := errors.New("reset after content")
+	a := &fakeClient{name: "a", queue: []fakeResult{{ch: chunkChan(
+		&completion.CompletionChunk{Content: "part"},
+		&completion.CompletionChunk{Error: midErr, Done: true},
+	)}}}
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("ok")}}}
+	svc := &Service{
+		endpoints: []*Endpoint{
+			testEndpoint("a", 1, true, a),
+			testEndpoint("b", 1, true, b),
+		},
+		selector:    &orderedSelector{order: []string{"a", "b"}},
+		maxAttempts: 3,
+	}
+
+	ch, err := svc.GetStream(context.Background(), &completion.CompletionRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	var got []*completion.CompletionChunk
+	for c := range ch {
+		got = append(got, c)
+	}
+	if len(got) != 2 || got[0].Content != "part" || !errors.Is(got[1].Error, midErr) {
+		t.Fatalf("chunks: got %+v", got)
+	}
+	if b.calls != 0 {
+		t.Fatalf("expected no call to b, got %d", b.calls)
+	}
+}
+
 // TestPool_RetryEvents_Fallover asserts the full P3 event timeline on the
 // request span when the pool fails over from a broken endpoint to a healthy
 // one: retry.attempt(x2) + endpoint.selected(x2) + endpoint.failed(x1) +
@@ -251,7 +406,7 @@ func TestPool_RetryEvents_Fallover(t *testing.T) {
 	otel.SetTracerProvider(tp)
 	t.Cleanup(func() { otel.SetTracerProvider(prev) })
 
-	a := &fakeClient{name: "a", queue: []fakeResult{{err: errors.New("upstream api returned status 503: bad gateway")}}}
+	a := &fakeClient{name: "a", queue: []fakeResult{{err: &completion.Error{StatusCode: 503, Message: "bad gateway"}}}}
 	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("ok")}}}
 
 	svc := &Service{
@@ -332,10 +487,18 @@ func TestErrorClass(t *testing.T) {
 		{nil, "none"},
 		{context.DeadlineExceeded, "timeout"},
 		{context.Canceled, "canceled"},
-		{errors.New("upstream api returned status 503: foo"), "http_5xx"},
-		{errors.New("upstream api returned status 404: nope"), "http_4xx"},
-		{errors.New("fail to call upstream api: dial tcp: connection refused"), "network"},
-		{errors.New("fail to build upstream request: bad url"), "parse_error"},
+		{&completion.Error{StatusCode: 503, Message: "foo"}, "http_5xx"},
+		{fmt.Errorf("pool: exhausted: %w", &completion.Error{StatusCode: 404, Message: "nope"}), "http_4xx"},
+		{&completion.Error{Message: "fail to call upstream api", Err: errors.New("dial tcp: connection refused")}, "network"},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, "network"},
+		{fmt.Errorf("%w: bad url", openai.ErrBuildRequest), "parse_error"},
+		{&completion.Error{Message: "fail to call upstream api", Err: fmt.Errorf("%w: i/o timeout", openai.ErrDialTimeout)}, "timeout_dial"},
+		{&completion.Error{Message: "fail to call upstream api", Err: openai.ErrResponseHeaderTimeout}, "timeout_response_header"},
+		{fmt.Errorf("failed to read from upstream: %w", openai.ErrFirstTokenTimeout), "timeout_first_token"},
+		{fmt.Errorf("failed to read from upstream: %w", openai.ErrIdleTimeout), "timeout_idle"},
+		{fmt.Errorf("failed to read from upstream: %w", openai.ErrTotalTimeout), "timeout_total"},
+		{fmt.Errorf("%w: %w", errStreamFailedEarly, openai.ErrFirstTokenTimeout), "timeout_first_token"},
+		{fmt.Errorf("%w: stream closed", errStreamFailedEarly), "stream_failed"},
 		{errors.New("unrelated"), "other"},
 	}
 	for _, c := range cases {
@@ -372,3 +535,65 @@ func TestPool_ConcurrentSafe(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestPool_ListModelsUnionOfEnabledEndpoints(t *testing.T) {
+	a := testEndpoint("a", 1, true, nil)
+	a.Cfg.Models = []string{"gpt-4o", "gpt-4o-mini"}
+	b := testEndpoint("b", 1, true, nil)
+	b.Cfg.Models = []string{"gpt-4o-mini", "*", "claude"}
+	c := testEndpoint("c", 1, false, nil)
+	c.Cfg.Models = []string{"disabled-only"}
+	svc := &Service{endpoints: []*Endpoint{a, b, c}}
+
+	got, err := svc.ListModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"claude", "gpt-4o", "gpt-4o-mini"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("ListModels: got %v, want %v", got, want)
+	}
+}
+
+func TestPool_RetryEvents_StreamFailover(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	a := &fakeClient{name: "a", queue: []fakeResult{{ch: chunkChan(&completion.CompletionChunk{Error: errors.New("reset"), Done: true})}}}
+	b := &fakeClient{name: "b", queue: []fakeResult{{ch: makeChunkChan("ok")}}}
+	svc := &Service{
+		endpoints: []*Endpoint{
+			testEndpoint("a", 1, true, a),
+			testEndpoint("b", 1, true, b),
+		},
+		selector:    &orderedSelector{order: []string{"a", "b"}},
+		maxAttempts: 3,
+	}
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "root")
+	if _, err := svc.GetStream(ctx, &completion.CompletionRequest{Model: "m"}); err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+	span.End()
+
+	attrs := map[string]map[string]string{}
+	for _, s := range rec.Ended() {
+		if s.Name() != "root" {
+			continue
+		}
+		for _, e := range s.Events() {
+			m := map[string]string{}
+			for _, a := range e.Attributes {
+				m[string(a.Key)] = a.Value.Emit()
+			}
+			attrs[e.Name] = m
+		}
+	}
+	if got := attrs["completion.endpoint.failed"]["stream_opened"]; got != "true" {
+		t.Errorf("endpoint.failed stream_opened=%q want true", got)
+	}
+	if got := attrs["completion.retry.succeeded"]["stream_failovers"]; got != "1" {
+		t.Errorf("retry.succeeded stream_failovers=%q want 1", got)
+	}
+}
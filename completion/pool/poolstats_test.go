@@ -68,7 +68,7 @@ func BenchmarkWrapChannelForStats(b *testing.B) {
 			src <- &completion.CompletionChunk{Content: "x"}
 		}
 		close(src)
-		out := wrapChannelForStats(ep, ep.Stats.start(), src)
+		out := wrapChannelForStats(context.Background(), ep, ep.Stats.start(), src)
 		for range out {
 		}
 	}
@@ -2,17 +2,28 @@ package pool
 
 import (
 	"math"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 const ewmaAlphaPercent uint64 = 20 // alpha=0.2 expressed as a percentage to keep math integer-friendly
 
+const ttftWindow = 128 // most recent time-to-first-token samples kept per endpoint
+
 type endpointStats struct {
 	InFlight       atomic.Int64
 	Success        atomic.Uint64
 	Failure        atomic.Uint64
 	LatencyUsEWMA  atomic.Uint64 // microseconds; 0 means "no samples yet"
+	Hedges         atomic.Uint64 // hedges fired because this endpoint was slow to first token
+	HedgeWins      atomic.Uint64 // hedged calls this endpoint served after another endpoint stalled
+
+	ttftMu   sync.Mutex
+	ttft     [ttftWindow]time.Duration
+	ttftLen  int
+	ttftNext int
 }
 
 func (s *endpointStats) start() time.Time {
@@ -31,6 +42,34 @@ func (s *endpointStats) end(startedAt time.Time, errored bool) {
 	s.observeLatency(uint64(dur.Microseconds()))
 }
 
+// abandon ends a call that lost a hedge race; it is neither a success nor a
+// failure, and its latency says nothing about the endpoint.
+func (s *endpointStats) abandon() {
+	s.InFlight.Add(-1)
+}
+
+func (s *endpointStats) observeTTFT(d time.Duration) {
+	s.ttftMu.Lock()
+	defer s.ttftMu.Unlock()
+	s.ttft[s.ttftNext] = d
+	s.ttftNext = (s.ttftNext + 1) % ttftWindow
+	s.ttftLen = min(s.ttftLen+1, ttftWindow)
+}
+
+// ttftPercentile returns the p-quantile of the recent time-to-first-token
+// samples, or false while fewer than minSamples have been observed.
+func (s *endpointStats) ttftPercentile(p float64, minSamples int) (time.Duration, bool) {
+	s.ttftMu.Lock()
+	samples := slices.Clone(s.ttft[:s.ttftLen])
+	s.ttftMu.Unlock()
+	if len(samples) == 0 || len(samples) < minSamples {
+		return 0, false
+	}
+	slices.Sort(samples)
+	idx := min(int(math.Ceil(p*float64(len(samples))))-1, len(samples)-1)
+	return samples[max(idx, 0)], true
+}
+
 // observeLatency updates the EWMA: new = alpha * sample + (1-alpha) * old.
 // First sample (old == 0) initializes EWMA to the sample value.
 func (s *endpointStats) observeLatency(sampleUs uint64) {
@@ -63,3 +102,7 @@ func (s *endpointStats) snapshot() (int64, uint64, uint64, float64, float64) {
 	}
 	return in, succ, fail, rate, latMs
 }
+
+func (s *endpointStats) hedgeCounts() (uint64, uint64) {
+	return s.Hedges.Load(), s.HedgeWins.Load()
+}
@@ -21,15 +21,30 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Optional fields are unset when the client did not send them; an explicit
+// zero value is forwarded to the upstream.
 type CompletionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
-	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
-	Temperature   float64                `protobuf:"fixed64,3,opt,name=temperature,proto3" json:"temperature,omitempty"`
-	MaxTokens     int32                  `protobuf:"varint,4,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
-	Stream        bool                   `protobuf:"varint,5,opt,name=stream,proto3" json:"stream,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Model               string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Question            string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"` // deprecated: flattened prompt; only read when messages is empty
+	Temperature         *float64               `protobuf:"fixed64,3,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	MaxTokens           int32                  `protobuf:"varint,4,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	Stream              bool                   `protobuf:"varint,5,opt,name=stream,proto3" json:"stream,omitempty"`
+	Messages            []*ChatMessage         `protobuf:"bytes,6,rep,name=messages,proto3" json:"messages,omitempty"`
+	Tools               []*Tool                `protobuf:"bytes,7,rep,name=tools,proto3" json:"tools,omitempty"`
+	ToolChoice          string                 `protobuf:"bytes,8,opt,name=tool_choice,json=toolChoice,proto3" json:"tool_choice,omitempty"` // raw JSON; empty = not set
+	TopP                *float64               `protobuf:"fixed64,9,opt,name=top_p,json=topP,proto3,oneof" json:"top_p,omitempty"`
+	Stop                []string               `protobuf:"bytes,10,rep,name=stop,proto3" json:"stop,omitempty"`
+	PresencePenalty     *float64               `protobuf:"fixed64,11,opt,name=presence_penalty,json=presencePenalty,proto3,oneof" json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64               `protobuf:"fixed64,12,opt,name=frequency_penalty,json=frequencyPenalty,proto3,oneof" json:"frequency_penalty,omitempty"`
+	Seed                *int64                 `protobuf:"varint,13,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	LogitBias           map[string]float64     `protobuf:"bytes,14,rep,name=logit_bias,json=logitBias,proto3" json:"logit_bias,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	User                string                 `protobuf:"bytes,15,opt,name=user,proto3" json:"user,omitempty"`
+	ResponseFormat      string                 `protobuf:"bytes,16,opt,name=response_format,json=responseFormat,proto3" json:"response_format,omitempty"` // raw JSON; empty = not set
+	MaxCompletionTokens *int32                 `protobuf:"varint,17,opt,name=max_completion_tokens,json=maxCompletionTokens,proto3,oneof" json:"max_completion_tokens,omitempty"`
+	ParallelToolCalls   *bool                  `protobuf:"varint,18,opt,name=parallel_tool_calls,json=parallelToolCalls,proto3,oneof" json:"parallel_tool_calls,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *CompletionRequest) Reset() {
@@ -77,8 +92,8 @@ func (x *CompletionRequest) GetQuestion() string {
 }
 
 func (x *CompletionRequest) GetTemperature() float64 {
-	if x != nil {
-		return x.Temperature
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
 	}
 	return 0
 }
@@ -97,21 +112,430 @@ func (x *CompletionRequest) GetStream() bool {
 	return false
 }
 
+func (x *CompletionRequest) GetMessages() []*ChatMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *CompletionRequest) GetTools() []*Tool {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+func (x *CompletionRequest) GetToolChoice() string {
+	if x != nil {
+		return x.ToolChoice
+	}
+	return ""
+}
+
+func (x *CompletionRequest) GetTopP() float64 {
+	if x != nil && x.TopP != nil {
+		return *x.TopP
+	}
+	return 0
+}
+
+func (x *CompletionRequest) GetStop() []string {
+	if x != nil {
+		return x.Stop
+	}
+	return nil
+}
+
+func (x *CompletionRequest) GetPresencePenalty() float64 {
+	if x != nil && x.PresencePenalty != nil {
+		return *x.PresencePenalty
+	}
+	return 0
+}
+
+func (x *CompletionRequest) GetFrequencyPenalty() float64 {
+	if x != nil && x.FrequencyPenalty != nil {
+		return *x.FrequencyPenalty
+	}
+	return 0
+}
+
+func (x *CompletionRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *CompletionRequest) GetLogitBias() map[string]float64 {
+	if x != nil {
+		return x.LogitBias
+	}
+	return nil
+}
+
+func (x *CompletionRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *CompletionRequest) GetResponseFormat() string {
+	if x != nil {
+		return x.ResponseFormat
+	}
+	return ""
+}
+
+func (x *CompletionRequest) GetMaxCompletionTokens() int32 {
+	if x != nil && x.MaxCompletionTokens != nil {
+		return *x.MaxCompletionTokens
+	}
+	return 0
+}
+
+func (x *CompletionRequest) GetParallelToolCalls() bool {
+	if x != nil && x.ParallelToolCalls != nil {
+		return *x.ParallelToolCalls
+	}
+	return false
+}
+
+type ChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	ToolCalls     []*ToolCall            `protobuf:"bytes,4,rep,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`
+	ToolCallId    string                 `protobuf:"bytes,5,opt,name=tool_call_id,json=toolCallId,proto3" json:"tool_call_id,omitempty"`
+	Parts         []*ContentPart         `protobuf:"bytes,6,rep,name=parts,proto3" json:"parts,omitempty"` // multimodal content; when set, content is ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_completion_proto_completion_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChatMessage) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetToolCalls() []*ToolCall {
+	if x != nil {
+		return x.ToolCalls
+	}
+	return nil
+}
+
+func (x *ChatMessage) GetToolCallId() string {
+	if x != nil {
+		return x.ToolCallId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetParts() []*ContentPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type ContentPart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // text | image_url | input_audio
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	ImageUrl      string                 `protobuf:"bytes,3,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	ImageDetail   string                 `protobuf:"bytes,4,opt,name=image_detail,json=imageDetail,proto3" json:"image_detail,omitempty"`
+	AudioData     string                 `protobuf:"bytes,5,opt,name=audio_data,json=audioData,proto3" json:"audio_data,omitempty"`
+	AudioFormat   string                 `protobuf:"bytes,6,opt,name=audio_format,json=audioFormat,proto3" json:"audio_format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContentPart) Reset() {
+	*x = ContentPart{}
+	mi := &file_completion_proto_completion_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContentPart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentPart) ProtoMessage() {}
+
+func (x *ContentPart) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentPart.ProtoReflect.Descriptor instead.
+func (*ContentPart) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ContentPart) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ContentPart) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ContentPart) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *ContentPart) GetImageDetail() string {
+	if x != nil {
+		return x.ImageDetail
+	}
+	return ""
+}
+
+func (x *ContentPart) GetAudioData() string {
+	if x != nil {
+		return x.AudioData
+	}
+	return ""
+}
+
+func (x *ContentPart) GetAudioFormat() string {
+	if x != nil {
+		return x.AudioFormat
+	}
+	return ""
+}
+
+type Tool struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Parameters    string                 `protobuf:"bytes,4,opt,name=parameters,proto3" json:"parameters,omitempty"` // raw JSON Schema
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tool) Reset() {
+	*x = Tool{}
+	mi := &file_completion_proto_completion_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tool) ProtoMessage() {}
+
+func (x *Tool) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tool.ProtoReflect.Descriptor instead.
+func (*Tool) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Tool) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Tool) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tool) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Tool) GetParameters() string {
+	if x != nil {
+		return x.Parameters
+	}
+	return ""
+}
+
+// ToolCall is a complete call inside ChatMessage and an incremental delta
+// inside CompletionChunk (index identifies the call, arguments is a fragment).
+type ToolCall struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Arguments     string                 `protobuf:"bytes,5,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCall) Reset() {
+	*x = ToolCall{}
+	mi := &file_completion_proto_completion_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCall) ProtoMessage() {}
+
+func (x *ToolCall) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCall.ProtoReflect.Descriptor instead.
+func (*ToolCall) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ToolCall) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ToolCall) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ToolCall) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ToolCall) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolCall) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
 type CompletionChunk struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Content          string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Error            string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Done             bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
-	TokenUsage       int32                  `protobuf:"varint,4,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"` // total_tokens; kept for backward compat
-	PromptTokens     int32                  `protobuf:"varint,5,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
-	CompletionTokens int32                  `protobuf:"varint,6,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Content            string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Error              string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Done               bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	TokenUsage         int32                  `protobuf:"varint,4,opt,name=token_usage,json=tokenUsage,proto3" json:"token_usage,omitempty"` // total_tokens; kept for backward compat
+	PromptTokens       int32                  `protobuf:"varint,5,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens   int32                  `protobuf:"varint,6,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	ToolCalls          []*ToolCall            `protobuf:"bytes,7,rep,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`
+	FinishReason       string                 `protobuf:"bytes,8,opt,name=finish_reason,json=finishReason,proto3" json:"finish_reason,omitempty"`                      // set on the done chunk; empty if the upstream sent none
+	CachedPromptTokens int32                  `protobuf:"varint,9,opt,name=cached_prompt_tokens,json=cachedPromptTokens,proto3" json:"cached_prompt_tokens,omitempty"` // prompt tokens served from the provider's prompt cache
+	Endpoint           string                 `protobuf:"bytes,10,opt,name=endpoint,proto3" json:"endpoint,omitempty"`                                                 // set on the done chunk: the pool endpoint that served the stream
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *CompletionChunk) Reset() {
 	*x = CompletionChunk{}
-	mi := &file_completion_proto_completion_proto_msgTypes[1]
+	mi := &file_completion_proto_completion_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -123,7 +547,7 @@ func (x *CompletionChunk) String() string {
 func (*CompletionChunk) ProtoMessage() {}
 
 func (x *CompletionChunk) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[1]
+	mi := &file_completion_proto_completion_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -136,7 +560,7 @@ func (x *CompletionChunk) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompletionChunk.ProtoReflect.Descriptor instead.
 func (*CompletionChunk) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{1}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CompletionChunk) GetContent() string {
@@ -181,6 +605,34 @@ func (x *CompletionChunk) GetCompletionTokens() int32 {
 	return 0
 }
 
+func (x *CompletionChunk) GetToolCalls() []*ToolCall {
+	if x != nil {
+		return x.ToolCalls
+	}
+	return nil
+}
+
+func (x *CompletionChunk) GetFinishReason() string {
+	if x != nil {
+		return x.FinishReason
+	}
+	return ""
+}
+
+func (x *CompletionChunk) GetCachedPromptTokens() int32 {
+	if x != nil {
+		return x.CachedPromptTokens
+	}
+	return 0
+}
+
+func (x *CompletionChunk) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
 type PoolStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -189,7 +641,7 @@ type PoolStatsRequest struct {
 
 func (x *PoolStatsRequest) Reset() {
 	*x = PoolStatsRequest{}
-	mi := &file_completion_proto_completion_proto_msgTypes[2]
+	mi := &file_completion_proto_completion_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -201,7 +653,7 @@ func (x *PoolStatsRequest) String() string {
 func (*PoolStatsRequest) ProtoMessage() {}
 
 func (x *PoolStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[2]
+	mi := &file_completion_proto_completion_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -214,7 +666,7 @@ func (x *PoolStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PoolStatsRequest.ProtoReflect.Descriptor instead.
 func (*PoolStatsRequest) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{2}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{6}
 }
 
 type PoolStatsResponse struct {
@@ -226,7 +678,7 @@ type PoolStatsResponse struct {
 
 func (x *PoolStatsResponse) Reset() {
 	*x = PoolStatsResponse{}
-	mi := &file_completion_proto_completion_proto_msgTypes[3]
+	mi := &file_completion_proto_completion_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -238,7 +690,7 @@ func (x *PoolStatsResponse) String() string {
 func (*PoolStatsResponse) ProtoMessage() {}
 
 func (x *PoolStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[3]
+	mi := &file_completion_proto_completion_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -251,7 +703,7 @@ func (x *PoolStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PoolStatsResponse.ProtoReflect.Descriptor instead.
 func (*PoolStatsResponse) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{3}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PoolStatsResponse) GetEndpoints() []*EndpointStat {
@@ -272,13 +724,15 @@ type EndpointStat struct {
 	SuccessRate   float64                `protobuf:"fixed64,7,opt,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"`
 	LatencyMsEwma float64                `protobuf:"fixed64,8,opt,name=latency_ms_ewma,json=latencyMsEwma,proto3" json:"latency_ms_ewma,omitempty"`
 	BreakerState  string                 `protobuf:"bytes,9,opt,name=breaker_state,json=breakerState,proto3" json:"breaker_state,omitempty"`
+	Hedges        uint64                 `protobuf:"varint,10,opt,name=hedges,proto3" json:"hedges,omitempty"`
+	HedgeWins     uint64                 `protobuf:"varint,11,opt,name=hedge_wins,json=hedgeWins,proto3" json:"hedge_wins,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EndpointStat) Reset() {
 	*x = EndpointStat{}
-	mi := &file_completion_proto_completion_proto_msgTypes[4]
+	mi := &file_completion_proto_completion_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -290,7 +744,7 @@ func (x *EndpointStat) String() string {
 func (*EndpointStat) ProtoMessage() {}
 
 func (x *EndpointStat) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[4]
+	mi := &file_completion_proto_completion_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +757,7 @@ func (x *EndpointStat) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EndpointStat.ProtoReflect.Descriptor instead.
 func (*EndpointStat) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{4}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *EndpointStat) GetName() string {
@@ -369,6 +823,100 @@ func (x *EndpointStat) GetBreakerState() string {
 	return ""
 }
 
+func (x *EndpointStat) GetHedges() uint64 {
+	if x != nil {
+		return x.Hedges
+	}
+	return 0
+}
+
+func (x *EndpointStat) GetHedgeWins() uint64 {
+	if x != nil {
+		return x.HedgeWins
+	}
+	return 0
+}
+
+type ListModelsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsRequest) Reset() {
+	*x = ListModelsRequest{}
+	mi := &file_completion_proto_completion_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsRequest) ProtoMessage() {}
+
+func (x *ListModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsRequest.ProtoReflect.Descriptor instead.
+func (*ListModelsRequest) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{9}
+}
+
+type ListModelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Models        []string               `protobuf:"bytes,1,rep,name=models,proto3" json:"models,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsResponse) Reset() {
+	*x = ListModelsResponse{}
+	mi := &file_completion_proto_completion_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsResponse) ProtoMessage() {}
+
+func (x *ListModelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_completion_proto_completion_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsResponse.ProtoReflect.Descriptor instead.
+func (*ListModelsResponse) Descriptor() ([]byte, []int) {
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListModelsResponse) GetModels() []string {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
 type ListEndpointsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -377,7 +925,7 @@ type ListEndpointsRequest struct {
 
 func (x *ListEndpointsRequest) Reset() {
 	*x = ListEndpointsRequest{}
-	mi := &file_completion_proto_completion_proto_msgTypes[5]
+	mi := &file_completion_proto_completion_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -389,7 +937,7 @@ func (x *ListEndpointsRequest) String() string {
 func (*ListEndpointsRequest) ProtoMessage() {}
 
 func (x *ListEndpointsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[5]
+	mi := &file_completion_proto_completion_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,7 +950,7 @@ func (x *ListEndpointsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListEndpointsRequest.ProtoReflect.Descriptor instead.
 func (*ListEndpointsRequest) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{5}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{11}
 }
 
 type ListEndpointsResponse struct {
@@ -414,7 +962,7 @@ type ListEndpointsResponse struct {
 
 func (x *ListEndpointsResponse) Reset() {
 	*x = ListEndpointsResponse{}
-	mi := &file_completion_proto_completion_proto_msgTypes[6]
+	mi := &file_completion_proto_completion_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -426,7 +974,7 @@ func (x *ListEndpointsResponse) String() string {
 func (*ListEndpointsResponse) ProtoMessage() {}
 
 func (x *ListEndpointsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[6]
+	mi := &file_completion_proto_completion_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -439,7 +987,7 @@ func (x *ListEndpointsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListEndpointsResponse.ProtoReflect.Descriptor instead.
 func (*ListEndpointsResponse) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{6}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ListEndpointsResponse) GetEndpoints() []*EndpointView {
@@ -464,7 +1012,7 @@ type EndpointView struct {
 
 func (x *EndpointView) Reset() {
 	*x = EndpointView{}
-	mi := &file_completion_proto_completion_proto_msgTypes[7]
+	mi := &file_completion_proto_completion_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -476,7 +1024,7 @@ func (x *EndpointView) String() string {
 func (*EndpointView) ProtoMessage() {}
 
 func (x *EndpointView) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[7]
+	mi := &file_completion_proto_completion_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -489,7 +1037,7 @@ func (x *EndpointView) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EndpointView.ProtoReflect.Descriptor instead.
 func (*EndpointView) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{7}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *EndpointView) GetName() string {
@@ -555,7 +1103,7 @@ type EndpointSpec struct {
 
 func (x *EndpointSpec) Reset() {
 	*x = EndpointSpec{}
-	mi := &file_completion_proto_completion_proto_msgTypes[8]
+	mi := &file_completion_proto_completion_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -567,7 +1115,7 @@ func (x *EndpointSpec) String() string {
 func (*EndpointSpec) ProtoMessage() {}
 
 func (x *EndpointSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[8]
+	mi := &file_completion_proto_completion_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -580,7 +1128,7 @@ func (x *EndpointSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EndpointSpec.ProtoReflect.Descriptor instead.
 func (*EndpointSpec) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{8}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *EndpointSpec) GetName() string {
@@ -634,7 +1182,7 @@ type EndpointName struct {
 
 func (x *EndpointName) Reset() {
 	*x = EndpointName{}
-	mi := &file_completion_proto_completion_proto_msgTypes[9]
+	mi := &file_completion_proto_completion_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -646,7 +1194,7 @@ func (x *EndpointName) String() string {
 func (*EndpointName) ProtoMessage() {}
 
 func (x *EndpointName) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[9]
+	mi := &file_completion_proto_completion_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,7 +1207,7 @@ func (x *EndpointName) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EndpointName.ProtoReflect.Descriptor instead.
 func (*EndpointName) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{9}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *EndpointName) GetName() string {
@@ -679,7 +1227,7 @@ type ReweightRequest struct {
 
 func (x *ReweightRequest) Reset() {
 	*x = ReweightRequest{}
-	mi := &file_completion_proto_completion_proto_msgTypes[10]
+	mi := &file_completion_proto_completion_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -691,7 +1239,7 @@ func (x *ReweightRequest) String() string {
 func (*ReweightRequest) ProtoMessage() {}
 
 func (x *ReweightRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[10]
+	mi := &file_completion_proto_completion_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -704,7 +1252,7 @@ func (x *ReweightRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReweightRequest.ProtoReflect.Descriptor instead.
 func (*ReweightRequest) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{10}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ReweightRequest) GetName() string {
@@ -731,7 +1279,7 @@ type SetEnabledRequest struct {
 
 func (x *SetEnabledRequest) Reset() {
 	*x = SetEnabledRequest{}
-	mi := &file_completion_proto_completion_proto_msgTypes[11]
+	mi := &file_completion_proto_completion_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -743,7 +1291,7 @@ func (x *SetEnabledRequest) String() string {
 func (*SetEnabledRequest) ProtoMessage() {}
 
 func (x *SetEnabledRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[11]
+	mi := &file_completion_proto_completion_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,7 +1304,7 @@ func (x *SetEnabledRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetEnabledRequest.ProtoReflect.Descriptor instead.
 func (*SetEnabledRequest) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{11}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *SetEnabledRequest) GetName() string {
@@ -782,7 +1330,7 @@ type AdminAck struct {
 
 func (x *AdminAck) Reset() {
 	*x = AdminAck{}
-	mi := &file_completion_proto_completion_proto_msgTypes[12]
+	mi := &file_completion_proto_completion_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -794,7 +1342,7 @@ func (x *AdminAck) String() string {
 func (*AdminAck) ProtoMessage() {}
 
 func (x *AdminAck) ProtoReflect() protoreflect.Message {
-	mi := &file_completion_proto_completion_proto_msgTypes[12]
+	mi := &file_completion_proto_completion_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -807,7 +1355,7 @@ func (x *AdminAck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AdminAck.ProtoReflect.Descriptor instead.
 func (*AdminAck) Descriptor() ([]byte, []int) {
-	return file_completion_proto_completion_proto_rawDescGZIP(), []int{12}
+	return file_completion_proto_completion_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *AdminAck) GetOk() bool {
@@ -822,14 +1370,70 @@ var File_completion_proto_completion_proto protoreflect.FileDescriptor
 const file_completion_proto_completion_proto_rawDesc = "" +
 	"\n" +
 	"!completion/proto/completion.proto\x12\n" +
-	"completion\"\x9e\x01\n" +
+	"completion\"\x80\a\n" +
 	"\x11CompletionRequest\x12\x14\n" +
 	"\x05model\x18\x01 \x01(\tR\x05model\x12\x1a\n" +
-	"\bquestion\x18\x02 \x01(\tR\bquestion\x12 \n" +
-	"\vtemperature\x18\x03 \x01(\x01R\vtemperature\x12\x1d\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12%\n" +
+	"\vtemperature\x18\x03 \x01(\x01H\x00R\vtemperature\x88\x01\x01\x12\x1d\n" +
 	"\n" +
 	"max_tokens\x18\x04 \x01(\x05R\tmaxTokens\x12\x16\n" +
-	"\x06stream\x18\x05 \x01(\bR\x06stream\"\xc8\x01\n" +
+	"\x06stream\x18\x05 \x01(\bR\x06stream\x123\n" +
+	"\bmessages\x18\x06 \x03(\v2\x17.completion.ChatMessageR\bmessages\x12&\n" +
+	"\x05tools\x18\a \x03(\v2\x10.completion.ToolR\x05tools\x12\x1f\n" +
+	"\vtool_choice\x18\b \x01(\tR\n" +
+	"toolChoice\x12\x18\n" +
+	"\x05top_p\x18\t \x01(\x01H\x01R\x04topP\x88\x01\x01\x12\x12\n" +
+	"\x04stop\x18\n" +
+	" \x03(\tR\x04stop\x12.\n" +
+	"\x10presence_penalty\x18\v \x01(\x01H\x02R\x0fpresencePenalty\x88\x01\x01\x120\n" +
+	"\x11frequency_penalty\x18\f \x01(\x01H\x03R\x10frequencyPenalty\x88\x01\x01\x12\x17\n" +
+	"\x04seed\x18\r \x01(\x03H\x04R\x04seed\x88\x01\x01\x12K\n" +
+	"\n" +
+	"logit_bias\x18\x0e \x03(\v2,.completion.CompletionRequest.LogitBiasEntryR\tlogitBias\x12\x12\n" +
+	"\x04user\x18\x0f \x01(\tR\x04user\x12'\n" +
+	"\x0fresponse_format\x18\x10 \x01(\tR\x0eresponseFormat\x127\n" +
+	"\x15max_completion_tokens\x18\x11 \x01(\x05H\x05R\x13maxCompletionTokens\x88\x01\x01\x123\n" +
+	"\x13parallel_tool_calls\x18\x12 \x01(\bH\x06R\x11parallelToolCalls\x88\x01\x01\x1a<\n" +
+	"\x0eLogitBiasEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B\x0e\n" +
+	"\f_temperatureB\b\n" +
+	"\x06_top_pB\x13\n" +
+	"\x11_presence_penaltyB\x14\n" +
+	"\x12_frequency_penaltyB\a\n" +
+	"\x05_seedB\x18\n" +
+	"\x16_max_completion_tokensB\x16\n" +
+	"\x14_parallel_tool_calls\"\xd5\x01\n" +
+	"\vChatMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x123\n" +
+	"\n" +
+	"tool_calls\x18\x04 \x03(\v2\x14.completion.ToolCallR\ttoolCalls\x12 \n" +
+	"\ftool_call_id\x18\x05 \x01(\tR\n" +
+	"toolCallId\x12-\n" +
+	"\x05parts\x18\x06 \x03(\v2\x17.completion.ContentPartR\x05parts\"\xb7\x01\n" +
+	"\vContentPart\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1b\n" +
+	"\timage_url\x18\x03 \x01(\tR\bimageUrl\x12!\n" +
+	"\fimage_detail\x18\x04 \x01(\tR\vimageDetail\x12\x1d\n" +
+	"\n" +
+	"audio_data\x18\x05 \x01(\tR\taudioData\x12!\n" +
+	"\faudio_format\x18\x06 \x01(\tR\vaudioFormat\"p\n" +
+	"\x04Tool\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\x04 \x01(\tR\n" +
+	"parameters\"v\n" +
+	"\bToolCall\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1c\n" +
+	"\targuments\x18\x05 \x01(\tR\targuments\"\xf0\x02\n" +
 	"\x0fCompletionChunk\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\tR\acontent\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12\x12\n" +
@@ -837,10 +1441,16 @@ const file_completion_proto_completion_proto_rawDesc = "" +
 	"\vtoken_usage\x18\x04 \x01(\x05R\n" +
 	"tokenUsage\x12#\n" +
 	"\rprompt_tokens\x18\x05 \x01(\x05R\fpromptTokens\x12+\n" +
-	"\x11completion_tokens\x18\x06 \x01(\x05R\x10completionTokens\"\x12\n" +
+	"\x11completion_tokens\x18\x06 \x01(\x05R\x10completionTokens\x123\n" +
+	"\n" +
+	"tool_calls\x18\a \x03(\v2\x14.completion.ToolCallR\ttoolCalls\x12#\n" +
+	"\rfinish_reason\x18\b \x01(\tR\ffinishReason\x120\n" +
+	"\x14cached_prompt_tokens\x18\t \x01(\x05R\x12cachedPromptTokens\x12\x1a\n" +
+	"\bendpoint\x18\n" +
+	" \x01(\tR\bendpoint\"\x12\n" +
 	"\x10PoolStatsRequest\"K\n" +
 	"\x11PoolStatsResponse\x126\n" +
-	"\tendpoints\x18\x01 \x03(\v2\x18.completion.EndpointStatR\tendpoints\"\x95\x02\n" +
+	"\tendpoints\x18\x01 \x03(\v2\x18.completion.EndpointStatR\tendpoints\"\xcc\x02\n" +
 	"\fEndpointStat\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06weight\x18\x02 \x01(\x05R\x06weight\x12\x18\n" +
@@ -850,7 +1460,14 @@ const file_completion_proto_completion_proto_rawDesc = "" +
 	"\afailure\x18\x06 \x01(\x04R\afailure\x12!\n" +
 	"\fsuccess_rate\x18\a \x01(\x01R\vsuccessRate\x12&\n" +
 	"\x0flatency_ms_ewma\x18\b \x01(\x01R\rlatencyMsEwma\x12#\n" +
-	"\rbreaker_state\x18\t \x01(\tR\fbreakerState\"\x16\n" +
+	"\rbreaker_state\x18\t \x01(\tR\fbreakerState\x12\x16\n" +
+	"\x06hedges\x18\n" +
+	" \x01(\x04R\x06hedges\x12\x1d\n" +
+	"\n" +
+	"hedge_wins\x18\v \x01(\x04R\thedgeWins\"\x13\n" +
+	"\x11ListModelsRequest\",\n" +
+	"\x12ListModelsResponse\x12\x16\n" +
+	"\x06models\x18\x01 \x03(\tR\x06models\"\x16\n" +
 	"\x14ListEndpointsRequest\"O\n" +
 	"\x15ListEndpointsResponse\x126\n" +
 	"\tendpoints\x18\x01 \x03(\v2\x18.completion.EndpointViewR\tendpoints\"\xc3\x01\n" +
@@ -878,10 +1495,12 @@ const file_completion_proto_completion_proto_rawDesc = "" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aenabled\x18\x02 \x01(\bR\aenabled\"\x1a\n" +
 	"\bAdminAck\x12\x0e\n" +
-	"\x02ok\x18\x01 \x01(\bR\x02ok2\xa8\x01\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok2\xf5\x01\n" +
 	"\x11CompletionService\x12I\n" +
 	"\tGetStream\x12\x1d.completion.CompletionRequest\x1a\x1b.completion.CompletionChunk0\x01\x12H\n" +
-	"\tPoolStats\x12\x1c.completion.PoolStatsRequest\x1a\x1d.completion.PoolStatsResponse2\xaa\x03\n" +
+	"\tPoolStats\x12\x1c.completion.PoolStatsRequest\x1a\x1d.completion.PoolStatsResponse\x12K\n" +
+	"\n" +
+	"ListModels\x12\x1d.completion.ListModelsRequest\x1a\x1e.completion.ListModelsResponse2\xaa\x03\n" +
 	"\x0fCompletionAdmin\x12T\n" +
 	"\rListEndpoints\x12 .completion.ListEndpointsRequest\x1a!.completion.ListEndpointsResponse\x12=\n" +
 	"\vAddEndpoint\x12\x18.completion.EndpointSpec\x1a\x14.completion.AdminAck\x12@\n" +
@@ -903,46 +1522,61 @@ func file_completion_proto_completion_proto_rawDescGZIP() []byte {
 	return file_completion_proto_completion_proto_rawDescData
 }
 
-var file_completion_proto_completion_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_completion_proto_completion_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_completion_proto_completion_proto_goTypes = []any{
 	(*CompletionRequest)(nil),     // 0: completion.CompletionRequest
-	(*CompletionChunk)(nil),       // 1: completion.CompletionChunk
-	(*PoolStatsRequest)(nil),      // 2: completion.PoolStatsRequest
-	(*PoolStatsResponse)(nil),     // 3: completion.PoolStatsResponse
-	(*EndpointStat)(nil),          // 4: completion.EndpointStat
-	(*ListEndpointsRequest)(nil),  // 5: completion.ListEndpointsRequest
-	(*ListEndpointsResponse)(nil), // 6: completion.ListEndpointsResponse
-	(*EndpointView)(nil),          // 7: completion.EndpointView
-	(*EndpointSpec)(nil),          // 8: completion.EndpointSpec
-	(*EndpointName)(nil),          // 9: completion.EndpointName
-	(*ReweightRequest)(nil),       // 10: completion.ReweightRequest
-	(*SetEnabledRequest)(nil),     // 11: completion.SetEnabledRequest
-	(*AdminAck)(nil),              // 12: completion.AdminAck
+	(*ChatMessage)(nil),           // 1: completion.ChatMessage
+	(*ContentPart)(nil),           // 2: completion.ContentPart
+	(*Tool)(nil),                  // 3: completion.Tool
+	(*ToolCall)(nil),              // 4: completion.ToolCall
+	(*CompletionChunk)(nil),       // 5: completion.CompletionChunk
+	(*PoolStatsRequest)(nil),      // 6: completion.PoolStatsRequest
+	(*PoolStatsResponse)(nil),     // 7: completion.PoolStatsResponse
+	(*EndpointStat)(nil),          // 8: completion.EndpointStat
+	(*ListModelsRequest)(nil),     // 9: completion.ListModelsRequest
+	(*ListModelsResponse)(nil),    // 10: completion.ListModelsResponse
+	(*ListEndpointsRequest)(nil),  // 11: completion.ListEndpointsRequest
+	(*ListEndpointsResponse)(nil), // 12: completion.ListEndpointsResponse
+	(*EndpointView)(nil),          // 13: completion.EndpointView
+	(*EndpointSpec)(nil),          // 14: completion.EndpointSpec
+	(*EndpointName)(nil),          // 15: completion.EndpointName
+	(*ReweightRequest)(nil),       // 16: completion.ReweightRequest
+	(*SetEnabledRequest)(nil),     // 17: completion.SetEnabledRequest
+	(*AdminAck)(nil),              // 18: completion.AdminAck
+	nil,                           // 19: completion.CompletionRequest.LogitBiasEntry
 }
 var file_completion_proto_completion_proto_depIdxs = []int32{
-	4,  // 0: completion.PoolStatsResponse.endpoints:type_name -> completion.EndpointStat
-	7,  // 1: completion.ListEndpointsResponse.endpoints:type_name -> completion.EndpointView
-	0,  // 2: completion.CompletionService.GetStream:input_type -> completion.CompletionRequest
-	2,  // 3: completion.CompletionService.PoolStats:input_type -> completion.PoolStatsRequest
-	5,  // 4: completion.CompletionAdmin.ListEndpoints:input_type -> completion.ListEndpointsRequest
-	8,  // 5: completion.CompletionAdmin.AddEndpoint:input_type -> completion.EndpointSpec
-	9,  // 6: completion.CompletionAdmin.RemoveEndpoint:input_type -> completion.EndpointName
-	10, // 7: completion.CompletionAdmin.Reweight:input_type -> completion.ReweightRequest
-	11, // 8: completion.CompletionAdmin.SetEnabled:input_type -> completion.SetEnabledRequest
-	9,  // 9: completion.CompletionAdmin.ResetBreaker:input_type -> completion.EndpointName
-	1,  // 10: completion.CompletionService.GetStream:output_type -> completion.CompletionChunk
-	3,  // 11: completion.CompletionService.PoolStats:output_type -> completion.PoolStatsResponse
-	6,  // 12: completion.CompletionAdmin.ListEndpoints:output_type -> completion.ListEndpointsResponse
-	12, // 13: completion.CompletionAdmin.AddEndpoint:output_type -> completion.AdminAck
-	12, // 14: completion.CompletionAdmin.RemoveEndpoint:output_type -> completion.AdminAck
-	12, // 15: completion.CompletionAdmin.Reweight:output_type -> completion.AdminAck
-	12, // 16: completion.CompletionAdmin.SetEnabled:output_type -> completion.AdminAck
-	12, // 17: completion.CompletionAdmin.ResetBreaker:output_type -> completion.AdminAck
-	10, // [10:18] is the sub-list for method output_type
-	2,  // [2:10] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	1,  // 0: completion.CompletionRequest.messages:type_name -> completion.ChatMessage
+	3,  // 1: completion.CompletionRequest.tools:type_name -> completion.Tool
+	19, // 2: completion.CompletionRequest.logit_bias:type_name -> completion.CompletionRequest.LogitBiasEntry
+	4,  // 3: completion.ChatMessage.tool_calls:type_name -> completion.ToolCall
+	2,  // 4: completion.ChatMessage.parts:type_name -> completion.ContentPart
+	4,  // 5: completion.CompletionChunk.tool_calls:type_name -> completion.ToolCall
+	8,  // 6: completion.PoolStatsResponse.endpoints:type_name -> completion.EndpointStat
+	13, // 7: completion.ListEndpointsResponse.endpoints:type_name -> completion.EndpointView
+	0,  // 8: completion.CompletionService.GetStream:input_type -> completion.CompletionRequest
+	6,  // 9: completion.CompletionService.PoolStats:input_type -> completion.PoolStatsRequest
+	9,  // 10: completion.CompletionService.ListModels:input_type -> completion.ListModelsRequest
+	11, // 11: completion.CompletionAdmin.ListEndpoints:input_type -> completion.ListEndpointsRequest
+	14, // 12: completion.CompletionAdmin.AddEndpoint:input_type -> completion.EndpointSpec
+	15, // 13: completion.CompletionAdmin.RemoveEndpoint:input_type -> completion.EndpointName
+	16, // 14: completion.CompletionAdmin.Reweight:input_type -> completion.ReweightRequest
+	17, // 15: completion.CompletionAdmin.SetEnabled:input_type -> completion.SetEnabledRequest
+	15, // 16: completion.CompletionAdmin.ResetBreaker:input_type -> completion.EndpointName
+	5,  // 17: completion.CompletionService.GetStream:output_type -> completion.CompletionChunk
+	7,  // 18: completion.CompletionService.PoolStats:output_type -> completion.PoolStatsResponse
+	10, // 19: completion.CompletionService.ListModels:output_type -> completion.ListModelsResponse
+	12, // 20: completion.CompletionAdmin.ListEndpoints:output_type -> completion.ListEndpointsResponse
+	18, // 21: completion.CompletionAdmin.AddEndpoint:output_type -> completion.AdminAck
+	18, // 22: completion.CompletionAdmin.RemoveEndpoint:output_type -> completion.AdminAck
+	18, // 23: completion.CompletionAdmin.Reweight:output_type -> completion.AdminAck
+	18, // 24: completion.CompletionAdmin.SetEnabled:output_type -> completion.AdminAck
+	18, // 25: completion.CompletionAdmin.ResetBreaker:output_type -> completion.AdminAck
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_completion_proto_completion_proto_init() }
@@ -950,13 +1584,14 @@ func file_completion_proto_completion_proto_init() {
 	if File_completion_proto_completion_proto != nil {
 		return
 	}
+	file_completion_proto_completion_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_completion_proto_completion_proto_rawDesc), len(file_completion_proto_completion_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CompletionService_GetStream_FullMethodName = "/completion.CompletionService/GetStream"
-	CompletionService_PoolStats_FullMethodName = "/completion.CompletionService/PoolStats"
+	CompletionService_GetStream_FullMethodName  = "/completion.CompletionService/GetStream"
+	CompletionService_PoolStats_FullMethodName  = "/completion.CompletionService/PoolStats"
+	CompletionService_ListModels_FullMethodName = "/completion.CompletionService/ListModels"
 )
 
 // CompletionServiceClient is the client API for CompletionService service.
@@ -29,6 +30,7 @@ const (
 type CompletionServiceClient interface {
 	GetStream(ctx context.Context, in *CompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CompletionChunk], error)
 	PoolStats(ctx context.Context, in *PoolStatsRequest, opts ...grpc.CallOption) (*PoolStatsResponse, error)
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
 }
 
 type completionServiceClient struct {
@@ -68,12 +70,23 @@ func (c *completionServiceClient) PoolStats(ctx context.Context, in *PoolStatsRe
 	return out, nil
 }
 
+func (c *completionServiceClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, CompletionService_ListModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CompletionServiceServer is the server API for CompletionService service.
 // All implementations must embed UnimplementedCompletionServiceServer
 // for forward compatibility.
 type CompletionServiceServer interface {
 	GetStream(*CompletionRequest, grpc.ServerStreamingServer[CompletionChunk]) error
 	PoolStats(context.Context, *PoolStatsRequest) (*PoolStatsResponse, error)
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
 	mustEmbedUnimplementedCompletionServiceServer()
 }
 
@@ -90,6 +103,9 @@ func (UnimplementedCompletionServiceServer) GetStream(*CompletionRequest, grpc.S
 func (UnimplementedCompletionServiceServer) PoolStats(context.Context, *PoolStatsRequest) (*PoolStatsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method PoolStats not implemented")
 }
+func (UnimplementedCompletionServiceServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListModels not implemented")
+}
 func (UnimplementedCompletionServiceServer) mustEmbedUnimplementedCompletionServiceServer() {}
 func (UnimplementedCompletionServiceServer) testEmbeddedByValue()                           {}
 
@@ -140,6 +156,24 @@ func _CompletionService_PoolStats_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CompletionService_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompletionServiceServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CompletionService_ListModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompletionServiceServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CompletionService_ServiceDesc is the grpc.ServiceDesc for CompletionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,6 +185,10 @@ var CompletionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PoolStats",
 			Handler:    _CompletionService_PoolStats_Handler,
 		},
+		{
+			MethodName: "ListModels",
+			Handler:    _CompletionService_ListModels_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
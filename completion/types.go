@@ -1,18 +1,83 @@
 package completion
 
+import "encoding/json"
+
+// CompletionRequest carries the client's sampling parameters. Pointer fields
+// are nil when the client did not set them, so an explicit zero (e.g.
+// temperature 0) reaches the upstream instead of being dropped.
 type CompletionRequest struct {
-	Model       string
-	Question    string
-	Temperature float64
-	MaxTokens   int
-	Stream      bool
+	Model               string
+	Messages            []Message
+	Temperature         *float64
+	TopP                *float64
+	MaxTokens           int
+	MaxCompletionTokens *int
+	Stop                []string
+	PresencePenalty     *float64
+	FrequencyPenalty    *float64
+	Seed                *int64
+	LogitBias           map[string]float64
+	User                string
+	ResponseFormat      json.RawMessage // {"type":"json_object"} etc.; forwarded verbatim
+	Stream              bool
+	Tools               []Tool
+	ToolChoice          json.RawMessage // "auto" | "none" | "required" | {"type":"function",...}; forwarded verbatim
+	ParallelToolCalls   *bool
+}
+
+// Message is one turn of the conversation, forwarded to the upstream as-is so
+// system prompts and assistant turns keep their role boundaries.
+type Message struct {
+	Role       string
+	Content    string
+	Parts      []ContentPart // multimodal content; when non-nil it is sent instead of Content
+	Name       string
+	ToolCalls  []ToolCall // assistant turns that invoked tools
+	ToolCallID string     // tool turns: the call this message answers
+}
+
+// ContentPart is one element of an array-form message content. Only the
+// fields for Type are set.
+type ContentPart struct {
+	Type        string // "text" | "image_url" | "input_audio"
+	Text        string
+	ImageURL    string
+	ImageDetail string // "auto" | "low" | "high"; empty = provider default
+	AudioData   string // base64
+	AudioFormat string // "wav" | "mp3"
+}
+
+// Tool is a function the model may call. Parameters is the JSON Schema object
+// exactly as the client sent it.
+type Tool struct {
+	Type        string
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+// ToolCall is a function invocation. In a CompletionChunk it is an incremental
+// delta: Index identifies the call, ID/Type/Name arrive once and Arguments is
+// a fragment to append. In a Message it is the complete call and Index is unused.
+type ToolCall struct {
+	Index     int
+	ID        string
+	Type      string
+	Name      string
+	Arguments string
 }
 
 type CompletionChunk struct {
 	Content          string
+	ToolCalls        []ToolCall
 	Error            error
 	Done             bool
-	TokenUsage       int // total_tokens; kept as-is so existing readers (cache writeback) stay unchanged
+	FinishReason     string // set on the Done chunk: "stop", "length", "tool_calls", "content_filter"; empty if the upstream sent none
+	TokenUsage       int    // total_tokens; kept as-is so existing readers (cache writeback) stay unchanged
 	PromptTokens     int
 	CompletionTokens int
+	// CachedPromptTokens is the part of PromptTokens the provider served from
+	// its prompt cache (usage.prompt_tokens_details.cached_tokens).
+	CachedPromptTokens int
+	Endpoint           string // set on the Done chunk by the pool: the endpoint that served the stream
 }
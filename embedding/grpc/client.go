@@ -43,6 +43,25 @@ func (c *Client) Get(ctx context.Context, text string) ([]float32, error) {
 	return resp.Embedding, nil
 }
 
+func (c *Client) GetBatch(ctx context.Context, texts []string) (embedding.BatchResult, error) {
+	resp, err := c.client.GetEmbeddings(ctx, &pb.BatchEmbeddingRequest{
+		Texts: texts,
+	})
+	if err != nil {
+		return embedding.BatchResult{}, fmt.Errorf("failed to get embeddings: %w", err)
+	}
+
+	if resp.Error != "" {
+		return embedding.BatchResult{}, fmt.Errorf("embedding service error: %s", resp.Error)
+	}
+
+	vectors := make([][]float32, 0, len(resp.Embeddings))
+	for _, v := range resp.Embeddings {
+		vectors = append(vectors, v.Values)
+	}
+	return embedding.BatchResult{Vectors: vectors, PromptTokens: int(resp.PromptTokens)}, nil
+}
+
 func (c *Client) Info(ctx context.Context) (embedding.Info, error) {
 	resp, err := c.client.Info(ctx, &emptypb.Empty{})
 	if err != nil {
@@ -33,6 +33,38 @@ func (s *Server) GetEmbedding(ctx context.Context, req *pb.EmbeddingRequest) (*p
 	}, nil
 }
 
+// GetEmbeddings embeds every text in order. Providers implementing
+// embedding.BatchEmbedder get a single upstream call; others are called once
+// per text and report no token usage.
+func (s *Server) GetEmbeddings(ctx context.Context, req *pb.BatchEmbeddingRequest) (*pb.BatchEmbeddingResponse, error) {
+	var result embedding.BatchResult
+	if batcher, ok := s.embeddingService.(embedding.BatchEmbedder); ok {
+		r, err := batcher.GetBatch(ctx, req.Texts)
+		if err != nil {
+			return &pb.BatchEmbeddingResponse{Error: err.Error()}, nil
+		}
+		result = r
+	} else {
+		result.Vectors = make([][]float32, 0, len(req.Texts))
+		for _, text := range req.Texts {
+			vec, err := s.embeddingService.Get(ctx, text)
+			if err != nil {
+				return &pb.BatchEmbeddingResponse{Error: err.Error()}, nil
+			}
+			result.Vectors = append(result.Vectors, vec)
+		}
+	}
+
+	resp := &pb.BatchEmbeddingResponse{
+		Embeddings:   make([]*pb.Vector, 0, len(result.Vectors)),
+		PromptTokens: int32(result.PromptTokens),
+	}
+	for _, vec := range result.Vectors {
+		resp.Embeddings = append(resp.Embeddings, &pb.Vector{Values: vec})
+	}
+	return resp, nil
+}
+
 func (s *Server) Info(ctx context.Context, _ *emptypb.Empty) (*pb.InfoResponse, error) {
 	info, err := s.embeddingService.Info(ctx)
 	if err != nil {
@@ -13,3 +13,24 @@ type Info struct {
 	Model      string
 	Dimensions int
 }
+
+// BatchResult holds one vector per input, in input order, plus the prompt-token
+// count reported by the upstream (0 when the provider does not report usage).
+type BatchResult struct {
+	Vectors      [][]float32
+	PromptTokens int
+}
+
+// BatchEmbedder is implemented by providers that can embed several inputs in a
+// single upstream call. The gRPC server falls back to per-input Get calls for
+// providers that do not implement it.
+type BatchEmbedder interface {
+	GetBatch(ctx context.Context, inputs []string) (BatchResult, error)
+}
+
+// BatchService is what the gateway's public /v1/embeddings endpoint needs:
+// the model metadata from Info plus batched embedding. The gRPC client implements it.
+type BatchService interface {
+	Service
+	BatchEmbedder
+}
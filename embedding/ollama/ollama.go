@@ -101,7 +101,32 @@ func (s *Service) Info(ctx context.Context) (embedding.Info, error) {
 	}, nil
 }
 
+// GetBatch implements embedding.BatchEmbedder; /api/embed accepts an input
+// array and reports prompt_eval_count for the whole batch.
+func (s *Service) GetBatch(ctx context.Context, inputs []string) (embedding.BatchResult, error) {
+	decoded, err := s.fetch(ctx, inputs)
+	if err != nil {
+		return embedding.BatchResult{}, err
+	}
+	if len(decoded.Embeddings) != len(inputs) {
+		return embedding.BatchResult{}, fmt.Errorf("embedding response has %d vectors for %d inputs", len(decoded.Embeddings), len(inputs))
+	}
+	return embedding.BatchResult{Vectors: decoded.Embeddings, PromptTokens: decoded.PromptEvalCount}, nil
+}
+
 func (s *Service) fetchEmbedding(ctx context.Context, input string) ([]float32, error) {
+	decoded, err := s.fetch(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded.Embeddings) == 0 || len(decoded.Embeddings[0]) == 0 {
+		return nil, fmt.Errorf("empty embedding response")
+	}
+	return decoded.Embeddings[0], nil
+}
+
+// fetch performs one /api/embed call; input is a string or a []string.
+func (s *Service) fetch(ctx context.Context, input any) (*EmbedResponse, error) {
 	ctx, span := tracing.Tracer("embedding.ollama").Start(ctx, "embedding.upstream.http")
 	defer span.End()
 
@@ -141,8 +166,5 @@ func (s *Service) fetchEmbedding(ctx context.Context, input string) ([]float32,
 	if err := json.Unmarshal(respBody, &decoded); err != nil {
 		return nil, fmt.Errorf("fail to unmarshal embedding response: %w", err)
 	}
-	if len(decoded.Embeddings) == 0 || len(decoded.Embeddings[0]) == 0 {
-		return nil, fmt.Errorf("empty embedding response")
-	}
-	return decoded.Embeddings[0], nil
+	return &decoded, nil
 }
@@ -88,8 +88,40 @@ func (s *Service) Info(ctx context.Context) (embedding.Info, error) {
 	}, nil
 }
 
+// GetBatch implements embedding.BatchEmbedder with a single upstream call
+// carrying every input.
+func (s *Service) GetBatch(ctx context.Context, inputs []string) (embedding.BatchResult, error) {
+	respBody, err := s.fetch(ctx, inputs)
+	if err != nil {
+		return embedding.BatchResult{}, err
+	}
+	if len(respBody.Data) != len(inputs) {
+		return embedding.BatchResult{}, fmt.Errorf("embedding response has %d vectors for %d inputs", len(respBody.Data), len(inputs))
+	}
+	vectors := make([][]float32, len(inputs))
+	for _, d := range respBody.Data {
+		if int(d.Index) < 0 || int(d.Index) >= len(vectors) {
+			return embedding.BatchResult{}, fmt.Errorf("embedding response index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return embedding.BatchResult{Vectors: vectors, PromptTokens: int(respBody.Usage.PromptTokens)}, nil
+}
+
 // getEmbedding gets embedding vector from OpenAI API
 func (s *Service) getEmbedding(ctx context.Context, input string) ([]float32, error) {
+	respBody, err := s.fetch(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) == 0 {
+		return nil, fmt.Errorf("empty embedding response data")
+	}
+	return respBody.Data[0].Embedding, nil
+}
+
+// fetch performs one upstream call; input is a string or a []string.
+func (s *Service) fetch(ctx context.Context, input any) (*EmbeddingResponse, error) {
 	ctx, span := tracing.Tracer("embedding.openai").Start(ctx, "embedding.upstream.http")
 	defer span.End()
 
@@ -132,8 +164,5 @@ func (s *Service) getEmbedding(ctx context.Context, input string) ([]float32, er
 	if err := json.Unmarshal(body, &respBody); err != nil {
 		return nil, fmt.Errorf("fail to unmarshal embedding response: %w", err)
 	}
-	if len(respBody.Data) == 0 {
-		return nil, fmt.Errorf("empty embedding response data")
-	}
-	return respBody.Data[0].Embedding, nil
+	return &respBody, nil
 }
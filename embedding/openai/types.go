@@ -3,7 +3,7 @@ package openai
 // EmbeddingRequest represents the request body for OpenAI embedding API
 type EmbeddingRequest struct {
 	Model          string `json:"model"`
-	Input          string `json:"input"`
+	Input          any    `json:"input"` // string or []string
 	EncodingFormat string `json:"encoding_format"`
 	Dimensions     int32  `json:"dimensions"`
 }
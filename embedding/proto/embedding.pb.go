@@ -118,6 +118,154 @@ func (x *EmbeddingResponse) GetError() string {
 	return ""
 }
 
+type BatchEmbeddingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Texts         []string               `protobuf:"bytes,1,rep,name=texts,proto3" json:"texts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEmbeddingRequest) Reset() {
+	*x = BatchEmbeddingRequest{}
+	mi := &file_embedding_proto_embedding_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEmbeddingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEmbeddingRequest) ProtoMessage() {}
+
+func (x *BatchEmbeddingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_embedding_proto_embedding_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEmbeddingRequest.ProtoReflect.Descriptor instead.
+func (*BatchEmbeddingRequest) Descriptor() ([]byte, []int) {
+	return file_embedding_proto_embedding_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchEmbeddingRequest) GetTexts() []string {
+	if x != nil {
+		return x.Texts
+	}
+	return nil
+}
+
+type Vector struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []float32              `protobuf:"fixed32,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vector) Reset() {
+	*x = Vector{}
+	mi := &file_embedding_proto_embedding_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vector) ProtoMessage() {}
+
+func (x *Vector) ProtoReflect() protoreflect.Message {
+	mi := &file_embedding_proto_embedding_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vector.ProtoReflect.Descriptor instead.
+func (*Vector) Descriptor() ([]byte, []int) {
+	return file_embedding_proto_embedding_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Vector) GetValues() []float32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type BatchEmbeddingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Embeddings    []*Vector              `protobuf:"bytes,1,rep,name=embeddings,proto3" json:"embeddings,omitempty"`
+	PromptTokens  int32                  `protobuf:"varint,2,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEmbeddingResponse) Reset() {
+	*x = BatchEmbeddingResponse{}
+	mi := &file_embedding_proto_embedding_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEmbeddingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEmbeddingResponse) ProtoMessage() {}
+
+func (x *BatchEmbeddingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_embedding_proto_embedding_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEmbeddingResponse.ProtoReflect.Descriptor instead.
+func (*BatchEmbeddingResponse) Descriptor() ([]byte, []int) {
+	return file_embedding_proto_embedding_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchEmbeddingResponse) GetEmbeddings() []*Vector {
+	if x != nil {
+		return x.Embeddings
+	}
+	return nil
+}
+
+func (x *BatchEmbeddingResponse) GetPromptTokens() int32 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *BatchEmbeddingResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type InfoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
@@ -129,7 +277,7 @@ type InfoResponse struct {
 
 func (x *InfoResponse) Reset() {
 	*x = InfoResponse{}
-	mi := &file_embedding_proto_embedding_proto_msgTypes[2]
+	mi := &file_embedding_proto_embedding_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -141,7 +289,7 @@ func (x *InfoResponse) String() string {
 func (*InfoResponse) ProtoMessage() {}
 
 func (x *InfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_embedding_proto_embedding_proto_msgTypes[2]
+	mi := &file_embedding_proto_embedding_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -154,7 +302,7 @@ func (x *InfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
 func (*InfoResponse) Descriptor() ([]byte, []int) {
-	return file_embedding_proto_embedding_proto_rawDescGZIP(), []int{2}
+	return file_embedding_proto_embedding_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *InfoResponse) GetProvider() string {
@@ -187,15 +335,26 @@ const file_embedding_proto_embedding_proto_rawDesc = "" +
 	"\x04text\x18\x01 \x01(\tR\x04text\"G\n" +
 	"\x11EmbeddingResponse\x12\x1c\n" +
 	"\tembedding\x18\x01 \x03(\x02R\tembedding\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"`\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"-\n" +
+	"\x15BatchEmbeddingRequest\x12\x14\n" +
+	"\x05texts\x18\x01 \x03(\tR\x05texts\" \n" +
+	"\x06Vector\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\x02R\x06values\"\x86\x01\n" +
+	"\x16BatchEmbeddingResponse\x121\n" +
+	"\n" +
+	"embeddings\x18\x01 \x03(\v2\x11.embedding.VectorR\n" +
+	"embeddings\x12#\n" +
+	"\rprompt_tokens\x18\x02 \x01(\x05R\fpromptTokens\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"`\n" +
 	"\fInfoResponse\x12\x1a\n" +
 	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x14\n" +
 	"\x05model\x18\x02 \x01(\tR\x05model\x12\x1e\n" +
 	"\n" +
 	"dimensions\x18\x03 \x01(\x05R\n" +
-	"dimensions2\x96\x01\n" +
+	"dimensions2\xec\x01\n" +
 	"\x10EmbeddingService\x12I\n" +
-	"\fGetEmbedding\x12\x1b.embedding.EmbeddingRequest\x1a\x1c.embedding.EmbeddingResponse\x127\n" +
+	"\fGetEmbedding\x12\x1b.embedding.EmbeddingRequest\x1a\x1c.embedding.EmbeddingResponse\x12T\n" +
+	"\rGetEmbeddings\x12 .embedding.BatchEmbeddingRequest\x1a!.embedding.BatchEmbeddingResponse\x127\n" +
 	"\x04Info\x12\x16.google.protobuf.Empty\x1a\x17.embedding.InfoResponseB\x11Z\x0fembedding/protob\x06proto3"
 
 var (
@@ -210,23 +369,29 @@ func file_embedding_proto_embedding_proto_rawDescGZIP() []byte {
 	return file_embedding_proto_embedding_proto_rawDescData
 }
 
-var file_embedding_proto_embedding_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_embedding_proto_embedding_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_embedding_proto_embedding_proto_goTypes = []any{
-	(*EmbeddingRequest)(nil),  // 0: embedding.EmbeddingRequest
-	(*EmbeddingResponse)(nil), // 1: embedding.EmbeddingResponse
-	(*InfoResponse)(nil),      // 2: embedding.InfoResponse
-	(*emptypb.Empty)(nil),     // 3: google.protobuf.Empty
+	(*EmbeddingRequest)(nil),       // 0: embedding.EmbeddingRequest
+	(*EmbeddingResponse)(nil),      // 1: embedding.EmbeddingResponse
+	(*BatchEmbeddingRequest)(nil),  // 2: embedding.BatchEmbeddingRequest
+	(*Vector)(nil),                 // 3: embedding.Vector
+	(*BatchEmbeddingResponse)(nil), // 4: embedding.BatchEmbeddingResponse
+	(*InfoResponse)(nil),           // 5: embedding.InfoResponse
+	(*emptypb.Empty)(nil),          // 6: google.protobuf.Empty
 }
 var file_embedding_proto_embedding_proto_depIdxs = []int32{
-	0, // 0: embedding.EmbeddingService.GetEmbedding:input_type -> embedding.EmbeddingRequest
-	3, // 1: embedding.EmbeddingService.Info:input_type -> google.protobuf.Empty
-	1, // 2: embedding.EmbeddingService.GetEmbedding:output_type -> embedding.EmbeddingResponse
-	2, // 3: embedding.EmbeddingService.Info:output_type -> embedding.InfoResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3, // 0: embedding.BatchEmbeddingResponse.embeddings:type_name -> embedding.Vector
+	0, // 1: embedding.EmbeddingService.GetEmbedding:input_type -> embedding.EmbeddingRequest
+	2, // 2: embedding.EmbeddingService.GetEmbeddings:input_type -> embedding.BatchEmbeddingRequest
+	6, // 3: embedding.EmbeddingService.Info:input_type -> google.protobuf.Empty
+	1, // 4: embedding.EmbeddingService.GetEmbedding:output_type -> embedding.EmbeddingResponse
+	4, // 5: embedding.EmbeddingService.GetEmbeddings:output_type -> embedding.BatchEmbeddingResponse
+	5, // 6: embedding.EmbeddingService.Info:output_type -> embedding.InfoResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_embedding_proto_embedding_proto_init() }
@@ -240,7 +405,7 @@ func file_embedding_proto_embedding_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_embedding_proto_embedding_proto_rawDesc), len(file_embedding_proto_embedding_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,8 +20,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EmbeddingService_GetEmbedding_FullMethodName = "/embedding.EmbeddingService/GetEmbedding"
-	EmbeddingService_Info_FullMethodName         = "/embedding.EmbeddingService/Info"
+	EmbeddingService_GetEmbedding_FullMethodName  = "/embedding.EmbeddingService/GetEmbedding"
+	EmbeddingService_GetEmbeddings_FullMethodName = "/embedding.EmbeddingService/GetEmbeddings"
+	EmbeddingService_Info_FullMethodName          = "/embedding.EmbeddingService/Info"
 )
 
 // EmbeddingServiceClient is the client API for EmbeddingService service.
@@ -29,6 +30,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type EmbeddingServiceClient interface {
 	GetEmbedding(ctx context.Context, in *EmbeddingRequest, opts ...grpc.CallOption) (*EmbeddingResponse, error)
+	GetEmbeddings(ctx context.Context, in *BatchEmbeddingRequest, opts ...grpc.CallOption) (*BatchEmbeddingResponse, error)
 	Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*InfoResponse, error)
 }
 
@@ -50,6 +52,16 @@ func (c *embeddingServiceClient) GetEmbedding(ctx context.Context, in *Embedding
 	return out, nil
 }
 
+func (c *embeddingServiceClient) GetEmbeddings(ctx context.Context, in *BatchEmbeddingRequest, opts ...grpc.CallOption) (*BatchEmbeddingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchEmbeddingResponse)
+	err := c.cc.Invoke(ctx, EmbeddingService_GetEmbeddings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *embeddingServiceClient) Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*InfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(InfoResponse)
@@ -65,6 +77,7 @@ func (c *embeddingServiceClient) Info(ctx context.Context, in *emptypb.Empty, op
 // for forward compatibility.
 type EmbeddingServiceServer interface {
 	GetEmbedding(context.Context, *EmbeddingRequest) (*EmbeddingResponse, error)
+	GetEmbeddings(context.Context, *BatchEmbeddingRequest) (*BatchEmbeddingResponse, error)
 	Info(context.Context, *emptypb.Empty) (*InfoResponse, error)
 	mustEmbedUnimplementedEmbeddingServiceServer()
 }
@@ -79,6 +92,9 @@ type UnimplementedEmbeddingServiceServer struct{}
 func (UnimplementedEmbeddingServiceServer) GetEmbedding(context.Context, *EmbeddingRequest) (*EmbeddingResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetEmbedding not implemented")
 }
+func (UnimplementedEmbeddingServiceServer) GetEmbeddings(context.Context, *BatchEmbeddingRequest) (*BatchEmbeddingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEmbeddings not implemented")
+}
 func (UnimplementedEmbeddingServiceServer) Info(context.Context, *emptypb.Empty) (*InfoResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Info not implemented")
 }
@@ -121,6 +137,24 @@ func _EmbeddingService_GetEmbedding_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmbeddingService_GetEmbeddings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEmbeddingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmbeddingServiceServer).GetEmbeddings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmbeddingService_GetEmbeddings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmbeddingServiceServer).GetEmbeddings(ctx, req.(*BatchEmbeddingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EmbeddingService_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -150,6 +184,10 @@ var EmbeddingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEmbedding",
 			Handler:    _EmbeddingService_GetEmbedding_Handler,
 		},
+		{
+			MethodName: "GetEmbeddings",
+			Handler:    _EmbeddingService_GetEmbeddings_Handler,
+		},
 		{
 			MethodName: "Info",
 			Handler:    _EmbeddingService_Info_Handler,
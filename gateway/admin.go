@@ -2,57 +2,92 @@ package gateway
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"llm_gateway/auth"
 	"llm_gateway/rag"
 )
 
 func (s *Server) AdminHandler() http.Handler {
 	mux := http.NewServeMux()
 	s.RegisterAdminRoutes(mux)
-	return chain(mux, adminCheckMiddleware)
+	return chain(mux, s.adminCheckMiddleware(mux))
+}
+
+type adminRoute struct {
+	pattern string
+	role    string // enforced by adminCheckMiddleware
+	handler http.HandlerFunc
+}
+
+// adminRoutes maps every admin route to the role it requires: reads are for
+// viewers, writes for the admin role of their group.
+func (s *Server) adminRoutes() []adminRoute {
+	return []adminRoute{
+		{"POST /admin/create", RoleTokenAdmin, s.handleRedisCreate},
+		{"POST /admin/get", RoleViewer, s.handleRedisGet},
+		{"POST /admin/delete", RoleTokenAdmin, s.handleRedisDelete},
+		{"GET /admin/tokens", RoleViewer, s.handleListTokens},
+		{"POST /admin/tokens/rotate", RoleTokenAdmin, s.handleRotateToken},
+		{"POST /admin/tokens/revoke", RoleTokenAdmin, s.handleRevokeToken},
+		{"POST /admin/tokens/revoke-alias", RoleTokenAdmin, s.handleRevokeAliasTokens},
+		{"GET /admin/budget", RoleViewer, s.handleGetBudget},
+		{"POST /admin/budget", RoleTokenAdmin, s.handleSetBudget},
+		{"DELETE /admin/budget", RoleTokenAdmin, s.handleDeleteBudget},
+		{"POST /admin/budget/reset", RoleTokenAdmin, s.handleResetBudgetSpend},
+		{"POST /admin/rag/ingest", RoleRAGAdmin, s.handleRAGIngest},
+		{"POST /admin/rag/ingest/text", RoleRAGAdmin, s.handleRAGIngestText},
+		{"DELETE /admin/rag/doc", RoleRAGAdmin, s.handleRAGDeleteDoc},
+		{"GET /admin/completion/stats", RoleViewer, s.handleCompletionStats},
+		{"GET /admin/completion/endpoints", RoleViewer, s.handleListCompletionEndpoints},
+		{"POST /admin/completion/endpoint", RolePoolAdmin, s.handleAddCompletionEndpoint},
+		{"DELETE /admin/completion/endpoint", RolePoolAdmin, s.handleRemoveCompletionEndpoint},
+		{"POST /admin/completion/endpoint/weight", RolePoolAdmin, s.handleReweightCompletionEndpoint},
+		{"POST /admin/completion/endpoint/enabled", RolePoolAdmin, s.handleSetCompletionEndpointEnabled},
+		{"POST /admin/completion/breaker/reset", RolePoolAdmin, s.handleResetCompletionBreaker},
+		{"GET /admin/usage", RoleViewer, s.handleGetUsage},
+		{"GET /admin/usage/export", RoleViewer, s.handleExportUsage},
+	}
 }
 
 func (s *Server) RegisterAdminRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("POST /admin/create", s.handleRedisCreate)
-	mux.HandleFunc("POST /admin/get", s.handleRedisGet)
-	mux.HandleFunc("POST /admin/delete", s.handleRedisDelete)
-	mux.HandleFunc("POST /admin/rag/ingest", s.handleRAGIngest)
-	mux.HandleFunc("POST /admin/rag/ingest/text", s.handleRAGIngestText)
-	mux.HandleFunc("DELETE /admin/rag/doc", s.handleRAGDeleteDoc)
-	mux.HandleFunc("GET /admin/completion/stats", s.handleCompletionStats)
-	mux.HandleFunc("GET /admin/completion/endpoints", s.handleListCompletionEndpoints)
-	mux.HandleFunc("POST /admin/completion/endpoint", s.handleAddCompletionEndpoint)
-	mux.HandleFunc("DELETE /admin/completion/endpoint", s.handleRemoveCompletionEndpoint)
-	mux.HandleFunc("POST /admin/completion/endpoint/weight", s.handleReweightCompletionEndpoint)
-	mux.HandleFunc("POST /admin/completion/endpoint/enabled", s.handleSetCompletionEndpointEnabled)
-	mux.HandleFunc("POST /admin/completion/breaker/reset", s.handleResetCompletionBreaker)
+	for _, rt := range s.adminRoutes() {
+		mux.HandleFunc(rt.pattern, rt.handler)
+	}
 }
 
+// POST /admin/create  -- body: {"alias":"...","expires_at":"RFC 3339","scopes":[...],"models":[...],"rag_collections":[...],"labels":{...},"budget":{...}}
+// -- everything but alias is optional; see auth.TokenInfo for the defaults
 func (s *Server) handleRedisCreate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	var req map[string]interface{}
-	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+	var info auth.TokenInfo
+	if err := bindJSON(r, &info); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
-
-	alias, ok := req["alias"].(string)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Invalid alias type"})
+	if err := info.Validate(); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.validateBudget(info.Budget); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	if info.Expired(time.Now()) {
+		writeAdminError(w, http.StatusBadRequest, errors.New("expires_at is in the past"))
 		return
 	}
+	alias := info.Alias
 
-	token, err := s.services.Auth.Create(r.Context(), alias)
+	token, err := s.services.Auth.Create(r.Context(), info)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		slog.ErrorContext(r.Context(), "auth token create failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Fail to create auth token"})
+		writeErrorJSON(w, http.StatusInternalServerError, "", "Fail to create auth token")
 		return
 	}
 
@@ -60,7 +95,7 @@ func (s *Server) handleRedisCreate(w http.ResponseWriter, r *http.Request) {
 	// Never log the token itself — it is a bearer secret. Log only that a
 	// token was created and its length, which is enough to confirm shape
 	// in operational triage.
-	slog.DebugContext(r.Context(), "auth token created", "alias", alias, "token_len", len(token))
+	slog.DebugContext(r.Context(), "auth token created", "alias", alias, "token_len", len(token), "admin", adminName(r.Context()))
 	_ = json.NewEncoder(w).Encode(map[string]string{"token": token, "alias": alias})
 }
 
@@ -68,50 +103,49 @@ func (s *Server) handleRedisGet(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var req map[string]interface{}
 	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
 
 	token, ok := req["token"].(string)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Invalid token type"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Invalid token type")
 		return
 	}
 
-	valid, alias, err := s.services.Auth.Get(r.Context(), token)
+	valid, info, err := s.services.Auth.Get(r.Context(), token)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		slog.ErrorContext(r.Context(), "auth token query failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Fail to query token"})
+		writeErrorJSON(w, http.StatusInternalServerError, "", "Fail to query token")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{"valide": valid, "token": token, "alias": alias})
+	_ = json.NewEncoder(w).Encode(struct {
+		Valid   bool   `json:"valide"`
+		Token   string `json:"token"`
+		Expired bool   `json:"expired"`
+		auth.TokenInfo
+	}{valid, token, valid && info.Expired(time.Now()), info})
 }
 
 func (s *Server) handleRedisDelete(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var req map[string]interface{}
 	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
 
 	token, ok := req["token"].(string)
 	if !ok || token == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Invalid or missing token"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Invalid or missing token")
 		return
 	}
 
 	if err := s.services.Auth.Delete(r.Context(), token); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		slog.ErrorContext(r.Context(), "auth token delete failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Fail to delete token"})
+		writeErrorJSON(w, http.StatusInternalServerError, "", "Fail to delete token")
 		return
 	}
 
@@ -135,8 +169,7 @@ func (s *Server) handleRAGIngest(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if s.services.RAG == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "RAG service not configured"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "RAG service not configured")
 		return
 	}
 
@@ -150,18 +183,15 @@ func (s *Server) handleRAGIngest(w http.ResponseWriter, r *http.Request) {
 		} `json:"chunks"`
 	}
 	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
 	if req.Collection == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "collection is required"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "collection is required")
 		return
 	}
 	if len(req.Chunks) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "chunks must not be empty"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "chunks must not be empty")
 		return
 	}
 
@@ -178,9 +208,8 @@ func (s *Server) handleRAGIngest(w http.ResponseWriter, r *http.Request) {
 
 	docID, count, err := s.services.RAG.Ingest(r.Context(), ragChunks)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		slog.ErrorContext(r.Context(), "rag ingest failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Ingest failed"})
+		writeErrorJSON(w, http.StatusInternalServerError, "", "Ingest failed")
 		return
 	}
 
@@ -208,8 +237,7 @@ func (s *Server) handleRAGIngestText(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if s.services.RAG == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "RAG service not configured"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "RAG service not configured")
 		return
 	}
 
@@ -221,18 +249,15 @@ func (s *Server) handleRAGIngestText(w http.ResponseWriter, r *http.Request) {
 		ChunkOverlap int    `json:"chunk_overlap"`
 	}
 	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
 	if req.Collection == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "collection is required"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "collection is required")
 		return
 	}
 	if strings.TrimSpace(req.Text) == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "text must not be empty"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "text must not be empty")
 		return
 	}
 
@@ -245,8 +270,7 @@ func (s *Server) handleRAGIngestText(w http.ResponseWriter, r *http.Request) {
 
 	contents := rag.ChunkText(req.Text, req.ChunkSize, req.ChunkOverlap)
 	if len(contents) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "text produced zero chunks after processing"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "text produced zero chunks after processing")
 		return
 	}
 	total := int32(len(contents))
@@ -284,8 +308,7 @@ func (s *Server) handleRAGIngestText(w http.ResponseWriter, r *http.Request) {
 		job.Err = "worker queue full"
 		s.ingestWorker.mu.Unlock()
 
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "ingest queue is full, try again later"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "ingest queue is full, try again later")
 		return
 	}
 
@@ -303,8 +326,7 @@ func (s *Server) handleRAGIngestText(w http.ResponseWriter, r *http.Request) {
 // Request body: {"doc_id": "uuid", "collection": "team-a"}
 func (s *Server) handleRAGDeleteDoc(w http.ResponseWriter, r *http.Request) {
 	if s.services.RAG == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "RAG service not configured"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "RAG service not configured")
 		return
 	}
 
@@ -313,20 +335,17 @@ func (s *Server) handleRAGDeleteDoc(w http.ResponseWriter, r *http.Request) {
 		Collection string `json:"collection"`
 	}
 	if err := bindJSON(r, &req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Failed to parse request"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "Failed to parse request")
 		return
 	}
 	if req.DocID == "" || req.Collection == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "doc_id and collection are required"})
+		writeErrorJSON(w, http.StatusBadRequest, "", "doc_id and collection are required")
 		return
 	}
 
 	if err := s.services.RAG.DeleteDoc(r.Context(), req.DocID, req.Collection); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		slog.ErrorContext(r.Context(), "rag delete doc failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Delete failed"})
+		writeErrorJSON(w, http.StatusInternalServerError, "", "Delete failed")
 		return
 	}
 
@@ -0,0 +1,255 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"llm_gateway/auth"
+	"llm_gateway/auth/redis"
+)
+
+const (
+	envAdminCredentialsFile = "ADMIN_CREDENTIALS_FILE"
+	envAdminCredentials     = "ADMIN_CREDENTIALS"
+
+	// adminSecretName is the identity of whoever presents ADMIN_SECRET.
+	adminSecretName = "admin"
+	// minAdminSecretLen keeps named credentials out of guessing range.
+	minAdminSecretLen = 16
+)
+
+// Admin roles. Each admin route requires one (see RegisterAdminRoutes).
+// Every role may call the viewer routes.
+const (
+	RoleViewer     = "viewer"      // every read: stats, endpoint and token lists, token lookup, budgets, usage
+	RoleTokenAdmin = "token-admin" // create, rotate and revoke tokens; set budgets
+	RoleRAGAdmin   = "rag-admin"   // ingest and delete RAG documents
+	RolePoolAdmin  = "pool-admin"  // add, remove and tune completion endpoints
+	RoleAdmin      = "admin"       // everything; held by ADMIN_SECRET
+)
+
+var adminRoles = []string{RoleViewer, RoleTokenAdmin, RoleRAGAdmin, RolePoolAdmin, RoleAdmin}
+
+// AdminCredential is a named admin secret, presented in X-Admin-Secret.
+type AdminCredential struct {
+	Name   string   `json:"name"`
+	Secret string   `json:"secret"`
+	Roles  []string `json:"roles"`
+}
+
+// LoadAdminCredentialsFromEnv reads named admin credentials from
+// ADMIN_CREDENTIALS_FILE, then from inline ADMIN_CREDENTIALS JSON, as a list
+// of {"name","secret","roles"}. Returns nil (ADMIN_SECRET only) when neither
+// is set.
+func LoadAdminCredentialsFromEnv() ([]AdminCredential, error) {
+	var raw []byte
+	if path := strings.TrimSpace(os.Getenv(envAdminCredentialsFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("admin credentials: read %s: %w", path, err)
+		}
+		raw = b
+	} else if inline := strings.TrimSpace(os.Getenv(envAdminCredentials)); inline != "" {
+		raw = []byte(inline)
+	} else {
+		return nil, nil
+	}
+
+	var creds []AdminCredential
+	if err := json.Unmarshal(raw, &creds); err != nil {
+		return nil, fmt.Errorf("admin credentials: parse: %w", err)
+	}
+	if err := validateAdminCredentials(creds); err != nil {
+		return nil, fmt.Errorf("admin credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func validateAdminCredentials(creds []AdminCredential) error {
+	names := make(map[string]bool, len(creds))
+	secrets := make(map[string]bool, len(creds))
+	for i, c := range creds {
+		switch {
+		case c.Name == "":
+			return fmt.Errorf("credential %d: name required", i)
+		case c.Name == adminSecretName:
+			return fmt.Errorf("credential %q: name is reserved for ADMIN_SECRET", c.Name)
+		case names[c.Name]:
+			return fmt.Errorf("credential %q: duplicate name", c.Name)
+		case len(c.Secret) < minAdminSecretLen:
+			return fmt.Errorf("credential %q: secret must be at least %d characters", c.Name, minAdminSecretLen)
+		case secrets[c.Secret]:
+			return fmt.Errorf("credential %q: secret shared with another credential", c.Name)
+		case len(c.Roles) == 0:
+			return fmt.Errorf("credential %q: at least one role required", c.Name)
+		}
+		for _, role := range c.Roles {
+			if !slices.Contains(adminRoles, role) {
+				return fmt.Errorf("credential %q: unknown role %q", c.Name, role)
+			}
+		}
+		names[c.Name], secrets[c.Secret] = true, true
+	}
+	return nil
+}
+
+// AdminIdentity is who made an admin request. Handlers read it with
+// AdminIdentityFrom, e.g. to record who changed what.
+type AdminIdentity struct {
+	// Name is the credential's name, "admin" for ADMIN_SECRET, or
+	// "token:<alias>" for an API token with the admin-read scope.
+	Name  string
+	Roles []string
+	// viaToken marks an admin-read API token rather than a secret.
+	viaToken bool
+}
+
+// Has reports whether the identity may call routes requiring role.
+func (id AdminIdentity) Has(role string) bool {
+	if role == RoleViewer && len(id.Roles) > 0 {
+		return true
+	}
+	return slices.Contains(id.Roles, role) || slices.Contains(id.Roles, RoleAdmin)
+}
+
+type adminIdentityKey struct{}
+
+// AdminIdentityFrom returns the identity adminCheckMiddleware admitted the
+// request under.
+func AdminIdentityFrom(ctx context.Context) (AdminIdentity, bool) {
+	id, ok := ctx.Value(adminIdentityKey{}).(AdminIdentity)
+	return id, ok
+}
+
+// adminName is the identity's name for logs, "" outside an admin request.
+func adminName(ctx context.Context) string {
+	id, _ := AdminIdentityFrom(ctx)
+	return id.Name
+}
+
+// adminSecret is a credential as held in memory: only the SHA-256 of the
+// secret, so every comparison is between equal-length digests.
+type adminSecret struct {
+	digest [sha256.Size]byte
+	id     AdminIdentity
+}
+
+// adminSecrets collects ADMIN_SECRET, when set, and the named credentials.
+func adminSecrets(creds []AdminCredential) []adminSecret {
+	var out []adminSecret
+	if secret := os.Getenv("ADMIN_SECRET"); secret != "" {
+		out = append(out, adminSecret{
+			digest: sha256.Sum256([]byte(secret)),
+			id:     AdminIdentity{Name: adminSecretName, Roles: []string{RoleAdmin}},
+		})
+	}
+	for _, c := range creds {
+		out = append(out, adminSecret{
+			digest: sha256.Sum256([]byte(c.Secret)),
+			id:     AdminIdentity{Name: c.Name, Roles: c.Roles},
+		})
+	}
+	return out
+}
+
+// matchAdminSecret compares presented against every secret in constant time,
+// without stopping at a match, so timing reveals neither which credential
+// matched nor how much of one did.
+func matchAdminSecret(secrets []adminSecret, presented string) (AdminIdentity, bool) {
+	digest := sha256.Sum256([]byte(presented))
+	var id AdminIdentity
+	found := 0
+	for _, s := range secrets {
+		eq := subtle.ConstantTimeCompare(digest[:], s.digest[:])
+		if eq == 1 {
+			id = s.id
+		}
+		found |= eq
+	}
+	return id, found == 1
+}
+
+// adminCheckMiddleware admits requests carrying an admin secret in
+// X-Admin-Secret: ADMIN_SECRET, which holds every role, or one of
+// Dependencies.AdminCredentials. Without one, an unexpired API token holding
+// the admin-read scope is admitted as a viewer, so dashboards can read stats
+// and usage. The request must then hold the role its route requires in
+// RegisterAdminRoutes; mux resolves the route.
+func (s *Server) adminCheckMiddleware(mux *http.ServeMux) Middleware {
+	secrets := adminSecrets(s.services.AdminCredentials)
+	routeRoles := make(map[string]string)
+	for _, rt := range s.adminRoutes() {
+		routeRoles[rt.pattern] = rt.role
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var id AdminIdentity
+			var ok bool
+			if presented := r.Header.Get("X-Admin-Secret"); presented != "" {
+				if id, ok = matchAdminSecret(secrets, presented); !ok {
+					writeErrorJSON(w, http.StatusForbidden, "", "Forbidden")
+					return
+				}
+			} else if id, ok = s.adminTokenIdentity(w, r); !ok {
+				return
+			}
+
+			_, pattern := mux.Handler(r)
+			if role, ok := routeRoles[pattern]; ok && !id.Has(role) {
+				slog.WarnContext(r.Context(), "admin role missing", "admin", id.Name, "role", role, "route", pattern)
+				code := ""
+				if id.viaToken {
+					code = errorCodeMissingScope
+				}
+				writeErrorJSON(w, http.StatusForbidden, code, fmt.Sprintf("Admin role '%s' required.", role))
+				return
+			}
+			if r.Method != http.MethodGet {
+				slog.InfoContext(r.Context(), "admin request", "admin", id.Name, "method", r.Method, "path", r.URL.Path)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminIdentityKey{}, id)))
+		})
+	}
+}
+
+// adminTokenIdentity admits an API token with the admin-read scope as a
+// viewer, writing the rejection itself otherwise.
+func (s *Server) adminTokenIdentity(w http.ResponseWriter, r *http.Request) (AdminIdentity, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		writeErrorJSON(w, http.StatusForbidden, "", "Forbidden")
+		return AdminIdentity{}, false
+	}
+	if !redis.CheckTokenFormat(tokenPrefix, tokenEntropyLen, token) {
+		writeErrorJSON(w, http.StatusUnauthorized, errorCodeInvalidAPIKey, "Invalid token format")
+		return AdminIdentity{}, false
+	}
+	valid, info, err := s.services.Auth.Get(r.Context(), token)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "admin token lookup failed", "err", err)
+		writeErrorJSON(w, http.StatusServiceUnavailable, errorCodeServiceUnavailable, "Authentication service unavailable")
+		return AdminIdentity{}, false
+	}
+	switch {
+	case !valid:
+		writeErrorJSON(w, http.StatusUnauthorized, errorCodeInvalidAPIKey, "Invalid or revoked token")
+	case info.Expired(time.Now()):
+		writeErrorJSON(w, http.StatusUnauthorized, errorCodeTokenExpired,
+			fmt.Sprintf("Token expired at %s", info.ExpiresAt.UTC().Format(time.RFC3339)))
+	case !info.HasScope(auth.ScopeAdminRead):
+		writeErrorJSON(w, http.StatusForbidden, errorCodeMissingScope,
+			fmt.Sprintf("This token does not have the '%s' scope.", auth.ScopeAdminRead))
+	default:
+		return AdminIdentity{Name: "token:" + info.Alias, Roles: []string{RoleViewer}, viaToken: true}, true
+	}
+	return AdminIdentity{}, false
+}
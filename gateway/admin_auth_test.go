@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testAdminCredentials = []AdminCredential{
+	{Name: "dash", Secret: "viewer-secret-0123456789", Roles: []string{RoleViewer}},
+	{Name: "ops", Secret: "pool-secret-0123456789ab", Roles: []string{RolePoolAdmin}},
+	{Name: "support", Secret: "token-secret-0123456789a", Roles: []string{RoleTokenAdmin, RoleRAGAdmin}},
+}
+
+func doAdminSecretRequest(s *Server, secret, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("X-Admin-Secret", secret)
+	rec := httptest.NewRecorder()
+	s.AdminHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminCheck_RolesGateRouteGroups(t *testing.T) {
+	t.Setenv("ADMIN_SECRET", "s3cret")
+	s := NewServer(Dependencies{Auth: fakeAuth{}, AdminCredentials: testAdminCredentials})
+
+	cases := []struct {
+		secret, method, path string
+		allowed              bool
+	}{
+		{"viewer-secret-0123456789", http.MethodGet, "/admin/usage", true},
+		{"viewer-secret-0123456789", http.MethodPost, "/admin/budget/reset", false},
+		{"viewer-secret-0123456789", http.MethodPost, "/admin/create", false},
+		{"viewer-secret-0123456789", http.MethodPost, "/admin/get", true},
+		{"pool-secret-0123456789ab", http.MethodGet, "/admin/completion/endpoints", true},
+		{"pool-secret-0123456789ab", http.MethodPost, "/admin/completion/breaker/reset", true},
+		{"pool-secret-0123456789ab", http.MethodPost, "/admin/create", false},
+		{"pool-secret-0123456789ab", http.MethodDelete, "/admin/rag/doc", false},
+		{"token-secret-0123456789a", http.MethodPost, "/admin/tokens/revoke", true},
+		{"token-secret-0123456789a", http.MethodDelete, "/admin/rag/doc", true},
+		{"token-secret-0123456789a", http.MethodDelete, "/admin/completion/endpoint", false},
+		{"s3cret", http.MethodDelete, "/admin/completion/endpoint", true},
+	}
+	for _, c := range cases {
+		rec := doAdminSecretRequest(s, c.secret, c.method, c.path)
+		if denied := rec.Code == http.StatusForbidden; denied == c.allowed {
+			t.Errorf("%s %s as %s: status %d, allowed want %v", c.method, c.path, c.secret[:5], rec.Code, c.allowed)
+		}
+	}
+}
+
+func TestAdminCheck_UnknownSecretForbidden(t *testing.T) {
+	t.Setenv("ADMIN_SECRET", "")
+	s := NewServer(Dependencies{Auth: fakeAuth{}, AdminCredentials: testAdminCredentials})
+
+	if rec := doAdminSecretRequest(s, "viewer-secret-012345678", http.MethodGet, "/admin/usage"); rec.Code != http.StatusForbidden {
+		t.Fatalf("status: got %d, want 403", rec.Code)
+	}
+}
+
+func TestAdminCheck_IdentityReachesHandler(t *testing.T) {
+	s := NewServer(Dependencies{AdminCredentials: testAdminCredentials})
+	mux := http.NewServeMux()
+	s.RegisterAdminRoutes(mux)
+	var got AdminIdentity
+	h := s.adminCheckMiddleware(mux)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = AdminIdentityFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/tokens/rotate", nil)
+	req.Header.Set("X-Admin-Secret", "token-secret-0123456789a")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Name != "support" || !got.Has(RoleTokenAdmin) || got.Has(RolePoolAdmin) {
+		t.Errorf("identity: got %+v", got)
+	}
+}
+
+func TestLoadAdminCredentialsFromEnv(t *testing.T) {
+	t.Setenv(envAdminCredentials, `[{"name":"dash","secret":"viewer-secret-0123456789","roles":["viewer"]}]`)
+	creds, err := LoadAdminCredentialsFromEnv()
+	if err != nil || len(creds) != 1 || creds[0].Name != "dash" {
+		t.Fatalf("got %+v, %v", creds, err)
+	}
+
+	for _, bad := range []string{
+		`[{"name":"dash","secret":"short","roles":["viewer"]}]`,
+		`[{"name":"dash","secret":"viewer-secret-0123456789","roles":["root"]}]`,
+		`[{"name":"admin","secret":"viewer-secret-0123456789","roles":["viewer"]}]`,
+		`[{"name":"a","secret":"viewer-secret-0123456789","roles":["viewer"]},{"name":"b","secret":"viewer-secret-0123456789","roles":["viewer"]}]`,
+	} {
+		t.Setenv(envAdminCredentials, bad)
+		if _, err := LoadAdminCredentialsFromEnv(); err == nil {
+			t.Errorf("%s: want error", bad)
+		}
+	}
+}
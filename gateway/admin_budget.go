@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"llm_gateway/auth"
+)
+
+// GET /admin/budget?id=...
+func (s *Server) handleGetBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("id required"))
+		return
+	}
+	info, err := s.services.Auth.Lookup(r.Context(), id)
+	if !s.budgetTokenFound(w, r, id, err) {
+		return
+	}
+	s.writeBudgetStatus(w, r, info)
+}
+
+// POST /admin/budget  -- body: {"id":"...","period":"day|month","soft_limit_tokens":N,"hard_limit_tokens":N,"soft_limit_usd":X,"hard_limit_usd":X}
+func (s *Server) handleSetBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var body struct {
+		ID string `json:"id"`
+		auth.Budget
+	}
+	if err := bindJSON(r, &body); err != nil || body.ID == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("id required"))
+		return
+	}
+	if err := s.validateBudget(&body.Budget); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := s.services.Auth.SetBudget(r.Context(), body.ID, &body.Budget)
+	if !s.budgetTokenFound(w, r, body.ID, err) {
+		return
+	}
+	slog.DebugContext(r.Context(), "budget set", "alias", info.Alias, "id", body.ID, "admin", adminName(r.Context()))
+	s.writeBudgetStatus(w, r, info)
+}
+
+// DELETE /admin/budget  -- body: {"id":"..."}; the spend is kept
+func (s *Server) handleDeleteBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, ok := bindBudgetID(w, r)
+	if !ok {
+		return
+	}
+	_, err := s.services.Auth.SetBudget(r.Context(), id, nil)
+	if !s.budgetTokenFound(w, r, id, err) {
+		return
+	}
+	writeAdminOK(w)
+}
+
+// POST /admin/budget/reset  -- body: {"id":"..."}; zeroes the current day's and month's spend
+func (s *Server) handleResetBudgetSpend(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, ok := bindBudgetID(w, r)
+	if !ok {
+		return
+	}
+	info, err := s.services.Auth.Lookup(r.Context(), id)
+	if !s.budgetTokenFound(w, r, id, err) {
+		return
+	}
+	if err := s.services.Budgets.ResetSpend(r.Context(), id); err != nil {
+		slog.ErrorContext(r.Context(), "budget reset failed", "id", id, "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeBudgetStatus(w, r, info)
+}
+
+// validateBudget checks b before it is stored on a token. Without a price
+// list every request costs $0, so a USD limit would never be reached.
+func (s *Server) validateBudget(b *auth.Budget) error {
+	if b == nil {
+		return nil
+	}
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if b.HasUSDLimit() && s.services.Pricing == nil {
+		return errors.New("USD limits need per-model prices; set PRICING")
+	}
+	return nil
+}
+
+func bindBudgetID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := bindJSON(r, &body); err != nil || body.ID == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("id required"))
+		return "", false
+	}
+	return body.ID, true
+}
+
+// budgetTokenFound writes the error response for a failed token lookup or
+// update and reports whether err was nil.
+func (s *Server) budgetTokenFound(w http.ResponseWriter, r *http.Request, id string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, auth.ErrTokenNotFound):
+		writeAdminError(w, http.StatusNotFound, err)
+	default:
+		slog.ErrorContext(r.Context(), "budget token update failed", "id", id, "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+	}
+	return false
+}
+
+func (s *Server) writeBudgetStatus(w http.ResponseWriter, r *http.Request, info auth.TokenInfo) {
+	spend, err := s.services.Budgets.Spend(r.Context(), info.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "budget status failed", "id", info.ID, "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(newBudgetStatus(info, spend, time.Now()))
+}
@@ -15,16 +15,14 @@ func (s *Server) handleCompletionStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if s.services.CompletionStats == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "completion stats not available"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "completion stats not available")
 		return
 	}
 
 	snapshots, err := s.services.CompletionStats.PoolStats(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusBadGateway)
 		slog.ErrorContext(r.Context(), "pool stats failed", "err", err)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "PoolStats failed"})
+		writeErrorJSON(w, http.StatusBadGateway, "", "PoolStats failed")
 		return
 	}
 
@@ -34,16 +32,14 @@ func (s *Server) handleCompletionStats(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) adminPoolAvailable(w http.ResponseWriter) bool {
 	if s.services.CompletionAdmin == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "completion admin not available"})
+		writeErrorJSON(w, http.StatusServiceUnavailable, "", "completion admin not available")
 		return false
 	}
 	return true
 }
 
 func writeAdminError(w http.ResponseWriter, code int, err error) {
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	writeErrorJSON(w, code, "", err.Error())
 }
 
 func writeAdminOK(w http.ResponseWriter) {
@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm_gateway/auth"
+)
+
+// GET /admin/tokens?alias=...&label=key=value&cursor=...&limit=N
+// -- label may repeat; every label must match
+func (s *Server) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	q := r.URL.Query()
+	f := auth.ListFilter{Alias: q.Get("alias"), Cursor: q.Get("cursor")}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("limit must be a positive integer, got %q", raw))
+			return
+		}
+		f.Limit = n
+	}
+	for _, raw := range q["label"] {
+		k, v, ok := strings.Cut(raw, "=")
+		if !ok || k == "" {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("label must be key=value, got %q", raw))
+			return
+		}
+		if f.Labels == nil {
+			f.Labels = make(map[string]string)
+		}
+		f.Labels[k] = v
+	}
+
+	page, err := s.services.Auth.List(r.Context(), f)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth token list failed", "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// POST /admin/tokens/rotate  -- body: {"id":"...","grace":"24h"}
+// -- grace defaults to auth.DefaultRotationGrace; "0s" revokes the old token at once
+func (s *Server) handleRotateToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var body struct {
+		ID    string `json:"id"`
+		Grace string `json:"grace"`
+	}
+	if err := bindJSON(r, &body); err != nil || body.ID == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("id required"))
+		return
+	}
+	grace := auth.DefaultRotationGrace
+	if body.Grace != "" {
+		d, err := time.ParseDuration(body.Grace)
+		if err != nil || d < 0 || d%time.Second != 0 {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("grace must be a non-negative whole number of seconds, got %q", body.Grace))
+			return
+		}
+		grace = d
+	}
+
+	token, info, err := s.services.Auth.Rotate(r.Context(), body.ID, grace)
+	switch {
+	case errors.Is(err, auth.ErrTokenNotFound):
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, auth.ErrTokenExpired):
+		writeAdminError(w, http.StatusConflict, err)
+		return
+	case err != nil && token == "":
+		slog.ErrorContext(r.Context(), "auth token rotate failed", "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	case err != nil:
+		// The replacement exists but the old token was not retired. Return
+		// the new token anyway: it cannot be fetched again.
+		slog.ErrorContext(r.Context(), "auth token rotate left old token live", "id", body.ID, "err", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	slog.DebugContext(r.Context(), "auth token rotated", "alias", info.Alias, "old_id", body.ID, "new_id", info.ID, "admin", adminName(r.Context()))
+	resp := struct {
+		Token string `json:"token"`
+		auth.TokenInfo
+		Warning string `json:"warning,omitempty"`
+	}{Token: token, TokenInfo: info}
+	if err != nil {
+		resp.Warning = err.Error()
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// POST /admin/tokens/revoke  -- body: {"id":"..."}
+func (s *Server) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := bindJSON(r, &body); err != nil || body.ID == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("id required"))
+		return
+	}
+	err := s.services.Auth.Revoke(r.Context(), body.ID)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth token revoke failed", "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAdminOK(w)
+}
+
+// POST /admin/tokens/revoke-alias  -- body: {"alias":"..."}; revokes every token of alias
+func (s *Server) handleRevokeAliasTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var body struct {
+		Alias string `json:"alias"`
+	}
+	if err := bindJSON(r, &body); err != nil || body.Alias == "" {
+		writeAdminError(w, http.StatusBadRequest, errBadJSON("alias required"))
+		return
+	}
+	n, err := s.services.Auth.RevokeByAlias(r.Context(), body.Alias)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth token revoke by alias failed", "alias", body.Alias, "revoked", n, "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]int{"revoked": n})
+}
@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+)
+
+// mockTokenAdmin records the token-management calls made by admin handlers.
+type mockTokenAdmin struct {
+	fakeAuth
+	listFilter  auth.ListFilter
+	page        auth.TokenPage
+	rotateID    string
+	rotateGrace time.Duration
+	budget      *auth.Budget // of token "known"
+	revoked     []string
+	aliasTokens int
+}
+
+func (m *mockTokenAdmin) List(_ context.Context, f auth.ListFilter) (auth.TokenPage, error) {
+	m.listFilter = f
+	return m.page, nil
+}
+
+func (m *mockTokenAdmin) Rotate(_ context.Context, id string, grace time.Duration) (string, auth.TokenInfo, error) {
+	if id == "expired" {
+		return "", auth.TokenInfo{}, auth.ErrTokenExpired
+	}
+	if id != "known" {
+		return "", auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	m.rotateID, m.rotateGrace = id, grace
+	return "sk-new", auth.TokenInfo{Alias: "alice", ID: "new-id"}, nil
+}
+
+func (m *mockTokenAdmin) Lookup(_ context.Context, id string) (auth.TokenInfo, error) {
+	if id != "known" {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	return auth.TokenInfo{Alias: "alice", ID: id, Budget: m.budget}, nil
+}
+
+func (m *mockTokenAdmin) SetBudget(ctx context.Context, id string, budget *auth.Budget) (auth.TokenInfo, error) {
+	if id != "known" {
+		return auth.TokenInfo{}, auth.ErrTokenNotFound
+	}
+	m.budget = budget
+	return m.Lookup(ctx, id)
+}
+
+func (m *mockTokenAdmin) Revoke(_ context.Context, id string) error {
+	if id != "known" {
+		return auth.ErrTokenNotFound
+	}
+	m.revoked = append(m.revoked, id)
+	return nil
+}
+
+func (m *mockTokenAdmin) RevokeByAlias(context.Context, string) (int, error) {
+	return m.aliasTokens, nil
+}
+
+func TestAdminTokens_ListPassesFilter(t *testing.T) {
+	m := &mockTokenAdmin{page: auth.TokenPage{
+		Tokens:     []auth.TokenInfo{{Alias: "alice", ID: "id1", Prefix: "sk-abcdef"}},
+		NextCursor: "1",
+	}}
+	_, mux := newAdminTestServer(t, Dependencies{Auth: m})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/tokens?alias=alice&label=team=ml&label=env=prod&limit=10&cursor=5", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d: %s", rec.Code, rec.Body)
+	}
+	f := m.listFilter
+	if f.Alias != "alice" || f.Limit != 10 || f.Cursor != "5" || f.Labels["team"] != "ml" || f.Labels["env"] != "prod" {
+		t.Errorf("filter: got %+v", f)
+	}
+	var page auth.TokenPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tokens) != 1 || page.Tokens[0].ID != "id1" || page.NextCursor != "1" {
+		t.Errorf("page: got %+v", page)
+	}
+}
+
+func TestAdminTokens_ListRejectsBadLabel(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/tokens?label=team", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want 400", rec.Code)
+	}
+}
+
+func TestAdminTokens_RotateGrace(t *testing.T) {
+	cases := []struct {
+		body string
+		want time.Duration
+	}{
+		{`{"id":"known"}`, auth.DefaultRotationGrace},
+		{`{"id":"known","grace":"1h"}`, time.Hour},
+		{`{"id":"known","grace":"0s"}`, 0},
+	}
+	for _, c := range cases {
+		m := &mockTokenAdmin{}
+		_, mux := newAdminTestServer(t, Dependencies{Auth: m})
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/rotate", strings.NewReader(c.body)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", c.body, rec.Code, rec.Body)
+		}
+		if m.rotateGrace != c.want {
+			t.Errorf("%s: grace got %v, want %v", c.body, m.rotateGrace, c.want)
+		}
+		var resp struct {
+			Token string `json:"token"`
+			ID    string `json:"id"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Token != "sk-new" || resp.ID != "new-id" {
+			t.Errorf("%s: response %s", c.body, rec.Body)
+		}
+	}
+}
+
+func TestAdminTokens_RotateRejectsBadGrace(t *testing.T) {
+	for _, grace := range []string{"500ms", "1.5s", "-1s", "soon"} {
+		m := &mockTokenAdmin{}
+		_, mux := newAdminTestServer(t, Dependencies{Auth: m})
+		rec := httptest.NewRecorder()
+		body := `{"id":"known","grace":"` + grace + `"}`
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/rotate", strings.NewReader(body)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status got %d, want 400", grace, rec.Code)
+		}
+		if m.rotateID != "" {
+			t.Errorf("%s: Rotate called", grace)
+		}
+	}
+}
+
+func TestAdminTokens_RotateExpiredIs409(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/rotate", strings.NewReader(`{"id":"expired"}`)))
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want 409", rec.Code)
+	}
+}
+
+func TestAdminTokens_RotateUnknownIs404(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/rotate", strings.NewReader(`{"id":"nope"}`)))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminTokens_Revoke(t *testing.T) {
+	m := &mockTokenAdmin{}
+	_, mux := newAdminTestServer(t, Dependencies{Auth: m})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/revoke", strings.NewReader(`{"id":"known"}`)))
+	if rec.Code != http.StatusOK || len(m.revoked) != 1 {
+		t.Fatalf("known: status %d, revoked %v", rec.Code, m.revoked)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/revoke", strings.NewReader(`{"id":"nope"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown: status %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminTokens_RevokeAliasReportsCount(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{aliasTokens: 3}})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/tokens/revoke-alias", strings.NewReader(`{"alias":"alice"}`)))
+
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"revoked":3}` {
+		t.Fatalf("got %d %s", rec.Code, rec.Body)
+	}
+}
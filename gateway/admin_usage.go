@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultUsageWindow is the report range when the caller gives no "from".
+	defaultUsageWindow = 7 * 24 * time.Hour
+
+	usageFormatCSV   = "csv"
+	usageFormatJSONL = "jsonl"
+)
+
+var usageCSVHeader = []string{
+	"timestamp", "request_id", "alias", "model", "endpoint", "path",
+	"prompt_tokens", "cached_prompt_tokens", "completion_tokens", "total_tokens",
+	"cost_usd", "cache_hit", "rag_used", "latency_ms", "status",
+}
+
+type usageReport struct {
+	From    time.Time  `json:"from"`
+	To      time.Time  `json:"to"`
+	GroupBy []string   `json:"group_by"`
+	Rows    []UsageRow `json:"rows"`
+}
+
+// GET /admin/usage?from=...&to=...&alias=...&model=...&group_by=alias,model,day
+func (s *Server) handleGetUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f, err := usageFilterFromQuery(r, time.Now())
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	groupBy, err := parseUsageGroupBy(r.URL.Query().Get("group_by"))
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	rows, err := summarizeUsage(r.Context(), s.services.Usage, f, groupBy)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "usage report failed", "err", err)
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if groupBy == nil {
+		groupBy = []string{}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(usageReport{From: f.From, To: f.To, GroupBy: groupBy, Rows: rows})
+}
+
+// GET /admin/usage/export?format=csv|jsonl&from=...&to=...&alias=...&model=...
+// -- one line per request, oldest first
+func (s *Server) handleExportUsage(w http.ResponseWriter, r *http.Request) {
+	f, err := usageFilterFromQuery(r, time.Now())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = usageFormatCSV
+	}
+	if format != usageFormatCSV && format != usageFormatJSONL {
+		w.Header().Set("Content-Type", "application/json")
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("format must be %q or %q", usageFormatCSV, usageFormatJSONL))
+		return
+	}
+
+	cw := csv.NewWriter(w)
+	enc := json.NewEncoder(w)
+	// Headers go out with the first line, so a ledger that fails before
+	// producing anything still gets a JSON error.
+	started := false
+	start := func() {
+		started = true
+		contentType := "text/csv"
+		if format == usageFormatJSONL {
+			contentType = "application/x-ndjson"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s-%s.%s"`,
+			f.From.Format("20060102T150405Z"), f.To.Format("20060102T150405Z"), format))
+		w.WriteHeader(http.StatusOK)
+		if format == usageFormatCSV {
+			_ = cw.Write(usageCSVHeader)
+		}
+	}
+	err = s.services.Usage.Scan(r.Context(), f, func(e UsageEntry) error {
+		if !started {
+			start()
+		}
+		if format == usageFormatCSV {
+			return cw.Write(usageCSVRecord(e))
+		}
+		return enc.Encode(e)
+	})
+	switch {
+	case err != nil && !started:
+		slog.ErrorContext(r.Context(), "usage export failed", "err", err)
+		w.Header().Set("Content-Type", "application/json")
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	case err != nil:
+		// Too late for an error status; the client sees a truncated file.
+		slog.ErrorContext(r.Context(), "usage export failed mid-stream", "err", err)
+	case !started:
+		start()
+	}
+	cw.Flush()
+}
+
+func usageCSVRecord(e UsageEntry) []string {
+	return []string{
+		e.Timestamp.UTC().Format(time.RFC3339Nano),
+		e.RequestID,
+		e.Alias,
+		e.Model,
+		e.Endpoint,
+		e.Path,
+		strconv.Itoa(e.PromptTokens),
+		strconv.Itoa(e.CachedPromptTokens),
+		strconv.Itoa(e.CompletionTokens),
+		strconv.Itoa(e.TotalTokens),
+		formatCost(e.CostUSD),
+		strconv.FormatBool(e.CacheHit),
+		strconv.FormatBool(e.RAGUsed),
+		strconv.FormatInt(e.LatencyMS, 10),
+		strconv.Itoa(e.Status),
+	}
+}
+
+// usageFilterFromQuery reads from, to, alias and model. Times are RFC 3339 or
+// a UTC date ("2026-10-17" is that day's midnight); to is exclusive and
+// defaults to now, from defaults to seven days before to.
+func usageFilterFromQuery(r *http.Request, now time.Time) (UsageFilter, error) {
+	q := r.URL.Query()
+	f := UsageFilter{To: now.UTC(), Alias: q.Get("alias"), Model: q.Get("model")}
+	if raw := q.Get("to"); raw != "" {
+		t, err := parseUsageTime(raw)
+		if err != nil {
+			return UsageFilter{}, fmt.Errorf("to: %w", err)
+		}
+		f.To = t
+	}
+	f.From = f.To.Add(-defaultUsageWindow)
+	if raw := q.Get("from"); raw != "" {
+		t, err := parseUsageTime(raw)
+		if err != nil {
+			return UsageFilter{}, fmt.Errorf("from: %w", err)
+		}
+		f.From = t
+	}
+	if !f.From.Before(f.To) {
+		return UsageFilter{}, errors.New("from must be before to")
+	}
+	return f, nil
+}
+
+func parseUsageTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC 3339 or YYYY-MM-DD, got %q", raw)
+	}
+	return t, nil
+}
@@ -0,0 +1,273 @@
+package gateway
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"llm_gateway/auth"
+	"llm_gateway/auth/redis"
+	"llm_gateway/internal/metrics"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	envAuthCacheTTL         = "AUTH_CACHE_TTL"
+	envAuthCacheNegativeTTL = "AUTH_CACHE_NEGATIVE_TTL"
+	envAuthCacheSize        = "AUTH_CACHE_SIZE"
+
+	defaultAuthCacheTTL         = 30 * time.Second
+	defaultAuthCacheNegativeTTL = 5 * time.Second
+	defaultAuthCacheSize        = 10_000
+)
+
+// AuthCacheConfig sizes the gateway's cache of auth.Service lookups. A zero
+// TTL disables the cache.
+type AuthCacheConfig struct {
+	TTL         time.Duration // how long a valid token's lookup is reused
+	NegativeTTL time.Duration // how long an unknown token stays rejected
+	Size        int           // entries kept; the oldest are dropped first
+}
+
+// LoadAuthCacheConfigFromEnv reads AUTH_CACHE_TTL and AUTH_CACHE_NEGATIVE_TTL
+// (Go durations, "0s" disables) and AUTH_CACHE_SIZE. Defaults: 30s, 5s and
+// 10000 entries.
+func LoadAuthCacheConfigFromEnv() (AuthCacheConfig, error) {
+	cfg := AuthCacheConfig{TTL: defaultAuthCacheTTL, NegativeTTL: defaultAuthCacheNegativeTTL, Size: defaultAuthCacheSize}
+	for _, d := range []struct {
+		env string
+		dst *time.Duration
+	}{{envAuthCacheTTL, &cfg.TTL}, {envAuthCacheNegativeTTL, &cfg.NegativeTTL}} {
+		raw := strings.TrimSpace(os.Getenv(d.env))
+		if raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < 0 {
+			return AuthCacheConfig{}, fmt.Errorf("auth cache: %s must be a non-negative duration, got %q", d.env, raw)
+		}
+		*d.dst = v
+	}
+	if raw := strings.TrimSpace(os.Getenv(envAuthCacheSize)); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return AuthCacheConfig{}, fmt.Errorf("auth cache: %s must be a positive integer, got %q", envAuthCacheSize, raw)
+		}
+		cfg.Size = n
+	}
+	return cfg, nil
+}
+
+// CachedAuth is an auth.Service that remembers Get results so most requests
+// skip the auth service round trip. Tokens are keyed by their SHA-256, never
+// held in clear. Lookup errors are not cached.
+//
+// A token revoked or changed through this replica is dropped at once; other
+// replicas learn of it from the auth service's invalidation channel (see
+// WatchInvalidations) or, failing that, when their entry's TTL runs out.
+type CachedAuth struct {
+	auth.Service
+	cfg AuthCacheConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	byID    map[string][sha256.Size]byte
+	order   *list.List // of *authCacheEntry, oldest first
+	// gen counts invalidations. A lookup that raced one is not cached, as
+	// it may predate the change.
+	gen uint64
+}
+
+type authCacheEntry struct {
+	key     [sha256.Size]byte
+	valid   bool
+	info    auth.TokenInfo
+	expires time.Time
+}
+
+func NewCachedAuth(svc auth.Service, cfg AuthCacheConfig) *CachedAuth {
+	return &CachedAuth{
+		Service: svc,
+		cfg:     cfg,
+		now:     time.Now,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		byID:    make(map[string][sha256.Size]byte),
+		order:   list.New(),
+	}
+}
+
+func (c *CachedAuth) Get(ctx context.Context, token string) (bool, auth.TokenInfo, error) {
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*authCacheEntry)
+		if c.now().Before(e.expires) {
+			c.mu.Unlock()
+			if e.valid {
+				metrics.AuthCacheLookups.WithLabelValues("hit").Inc()
+			} else {
+				metrics.AuthCacheLookups.WithLabelValues("negative_hit").Inc()
+			}
+			return e.valid, e.info, nil
+		}
+		c.removeLocked(el)
+	}
+	gen := c.gen
+	c.mu.Unlock()
+	metrics.AuthCacheLookups.WithLabelValues("miss").Inc()
+
+	valid, info, err := c.Service.Get(ctx, token)
+	if err != nil {
+		return valid, info, err
+	}
+	ttl := c.cfg.TTL
+	if !valid {
+		ttl = c.cfg.NegativeTTL
+	}
+	if ttl > 0 {
+		c.put(gen, &authCacheEntry{key: key, valid: valid, info: info, expires: c.now().Add(ttl)})
+	}
+	return valid, info, nil
+}
+
+func (c *CachedAuth) put(gen uint64, e *authCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.removeLocked(el)
+	}
+	for c.order.Len() >= c.cfg.Size {
+		c.removeLocked(c.order.Front())
+	}
+	c.entries[e.key] = c.order.PushBack(e)
+	if e.valid && e.info.ID != "" {
+		c.byID[e.info.ID] = e.key
+	}
+}
+
+func (c *CachedAuth) removeLocked(el *list.Element) {
+	e := c.order.Remove(el).(*authCacheEntry)
+	delete(c.entries, e.key)
+	if e.info.ID != "" && c.byID[e.info.ID] == e.key {
+		delete(c.byID, e.info.ID)
+	}
+}
+
+// Invalidate drops the cached lookup of the token with id, if any.
+func (c *CachedAuth) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if key, ok := c.byID[id]; ok {
+		if el, ok := c.entries[key]; ok {
+			c.removeLocked(el)
+			metrics.AuthCacheInvalidations.Inc()
+		}
+	}
+}
+
+// Flush drops every cached lookup.
+func (c *CachedAuth) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.entries)
+	clear(c.byID)
+	c.order.Init()
+}
+
+func (c *CachedAuth) invalidateAlias(alias string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*authCacheEntry); e.valid && e.info.Alias == alias {
+			c.removeLocked(el)
+		}
+		el = next
+	}
+}
+
+// The mutating methods drop the local entry after the auth service call, so
+// a lookup racing the call cannot re-cache what it replaced.
+
+func (c *CachedAuth) Delete(ctx context.Context, token string) error {
+	err := c.Service.Delete(ctx, token)
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	c.gen++
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *CachedAuth) Revoke(ctx context.Context, id string) error {
+	err := c.Service.Revoke(ctx, id)
+	c.Invalidate(id)
+	return err
+}
+
+func (c *CachedAuth) RevokeByAlias(ctx context.Context, alias string) (int, error) {
+	n, err := c.Service.RevokeByAlias(ctx, alias)
+	c.invalidateAlias(alias)
+	return n, err
+}
+
+func (c *CachedAuth) SetBudget(ctx context.Context, id string, budget *auth.Budget) (auth.TokenInfo, error) {
+	info, err := c.Service.SetBudget(ctx, id, budget)
+	c.Invalidate(id)
+	return info, err
+}
+
+func (c *CachedAuth) Rotate(ctx context.Context, id string, grace time.Duration) (string, auth.TokenInfo, error) {
+	token, info, err := c.Service.Rotate(ctx, id, grace)
+	c.Invalidate(id)
+	return token, info, err
+}
+
+// WatchInvalidations drops cached lookups as the auth service announces
+// revoked or changed tokens on redis.InvalidationChannel, until ctx ends.
+// Announcements sent while the subscription is down are lost, so the whole
+// cache is flushed every time it is (re)established.
+func (c *CachedAuth) WatchInvalidations(ctx context.Context, client goredis.UniversalClient) {
+	sub := client.Subscribe(ctx, redis.InvalidationChannel)
+	defer sub.Close()
+	for {
+		msg, err := sub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "auth cache invalidation subscription interrupted", "err", err)
+			c.Flush()
+			// Receive reconnects on the next call; don't spin while Redis is down.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *goredis.Subscription:
+			c.Flush()
+		case *goredis.Message:
+			c.Invalidate(m.Payload)
+		}
+	}
+}
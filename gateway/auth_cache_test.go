@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+)
+
+// countingAuth knows the tokens in valid and counts Get calls.
+type countingAuth struct {
+	fakeAuth
+	valid map[string]auth.TokenInfo
+	err   error
+	gets  int
+}
+
+func (a *countingAuth) Get(_ context.Context, token string) (bool, auth.TokenInfo, error) {
+	a.gets++
+	if a.err != nil {
+		return false, auth.TokenInfo{}, a.err
+	}
+	info, ok := a.valid[token]
+	return ok, info, nil
+}
+
+func newTestAuthCache(svc auth.Service, size int) (*CachedAuth, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	c := NewCachedAuth(svc, AuthCacheConfig{TTL: 30 * time.Second, NegativeTTL: 5 * time.Second, Size: size})
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCachedAuth_HitsWithinTTL(t *testing.T) {
+	svc := &countingAuth{valid: map[string]auth.TokenInfo{"sk-a": {Alias: "alice", ID: "id-a"}}}
+	c, now := newTestAuthCache(svc, 10)
+	ctx := context.Background()
+
+	for range 3 {
+		if ok, info, err := c.Get(ctx, "sk-a"); !ok || err != nil || info.Alias != "alice" {
+			t.Fatalf("get: %v %+v %v", ok, info, err)
+		}
+	}
+	if svc.gets != 1 {
+		t.Fatalf("upstream gets: got %d, want 1", svc.gets)
+	}
+
+	*now = now.Add(31 * time.Second)
+	c.Get(ctx, "sk-a")
+	if svc.gets != 2 {
+		t.Fatalf("after TTL: upstream gets %d, want 2", svc.gets)
+	}
+}
+
+func TestCachedAuth_NegativeCaching(t *testing.T) {
+	svc := &countingAuth{}
+	c, now := newTestAuthCache(svc, 10)
+	ctx := context.Background()
+
+	c.Get(ctx, "sk-unknown")
+	if ok, _, _ := c.Get(ctx, "sk-unknown"); ok {
+		t.Fatal("unknown token must stay invalid")
+	}
+	if svc.gets != 1 {
+		t.Fatalf("upstream gets: got %d, want 1", svc.gets)
+	}
+	*now = now.Add(6 * time.Second)
+	c.Get(ctx, "sk-unknown")
+	if svc.gets != 2 {
+		t.Fatalf("after negative TTL: upstream gets %d, want 2", svc.gets)
+	}
+}
+
+func TestCachedAuth_ErrorsNotCached(t *testing.T) {
+	svc := &countingAuth{err: errors.New("down")}
+	c, _ := newTestAuthCache(svc, 10)
+	ctx := context.Background()
+
+	if _, _, err := c.Get(ctx, "sk-a"); err == nil {
+		t.Fatal("want error")
+	}
+	c.Get(ctx, "sk-a")
+	if svc.gets != 2 {
+		t.Fatalf("upstream gets: got %d, want 2", svc.gets)
+	}
+}
+
+func TestCachedAuth_InvalidateByID(t *testing.T) {
+	svc := &countingAuth{valid: map[string]auth.TokenInfo{"sk-a": {Alias: "alice", ID: "id-a"}}}
+	c, _ := newTestAuthCache(svc, 10)
+	ctx := context.Background()
+
+	c.Get(ctx, "sk-a")
+	delete(svc.valid, "sk-a") // revoked in the auth service
+	c.Invalidate("id-a")
+
+	if ok, _, _ := c.Get(ctx, "sk-a"); ok {
+		t.Fatal("revoked token still valid after invalidation")
+	}
+}
+
+func TestCachedAuth_RevokeByAliasDropsLocalEntries(t *testing.T) {
+	svc := &countingAuth{valid: map[string]auth.TokenInfo{
+		"sk-a": {Alias: "alice", ID: "id-a"},
+		"sk-b": {Alias: "bob", ID: "id-b"},
+	}}
+	c, _ := newTestAuthCache(svc, 10)
+	ctx := context.Background()
+	c.Get(ctx, "sk-a")
+	c.Get(ctx, "sk-b")
+
+	if _, err := c.RevokeByAlias(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	c.Get(ctx, "sk-a")
+	c.Get(ctx, "sk-b")
+	if svc.gets != 3 {
+		t.Fatalf("upstream gets: got %d, want 3 (only alice refetched)", svc.gets)
+	}
+}
+
+func TestCachedAuth_EvictsOldestWhenFull(t *testing.T) {
+	svc := &countingAuth{valid: map[string]auth.TokenInfo{
+		"sk-a": {Alias: "a", ID: "1"},
+		"sk-b": {Alias: "b", ID: "2"},
+		"sk-c": {Alias: "c", ID: "3"},
+	}}
+	c, _ := newTestAuthCache(svc, 2)
+	ctx := context.Background()
+	c.Get(ctx, "sk-a")
+	c.Get(ctx, "sk-b")
+	c.Get(ctx, "sk-c") // evicts sk-a
+
+	c.Get(ctx, "sk-c")
+	if svc.gets != 3 {
+		t.Fatalf("sk-c should be cached: upstream gets %d", svc.gets)
+	}
+	c.Get(ctx, "sk-a")
+	if svc.gets != 4 {
+		t.Fatalf("sk-a should have been evicted: upstream gets %d", svc.gets)
+	}
+}
+
+func TestLoadAuthCacheConfigFromEnv(t *testing.T) {
+	t.Setenv(envAuthCacheTTL, "0s")
+	t.Setenv(envAuthCacheSize, "")
+	cfg, err := LoadAuthCacheConfigFromEnv()
+	if err != nil || cfg.TTL != 0 || cfg.NegativeTTL != defaultAuthCacheNegativeTTL || cfg.Size != defaultAuthCacheSize {
+		t.Fatalf("got %+v, %v", cfg, err)
+	}
+
+	t.Setenv(envAuthCacheNegativeTTL, "soon")
+	if _, err := LoadAuthCacheConfigFromEnv(); err == nil {
+		t.Fatal("want error for a bad duration")
+	}
+}
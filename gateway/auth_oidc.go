@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"llm_gateway/auth/oidc"
+)
+
+const (
+	envOIDCAuthFile = "OIDC_AUTH_FILE"
+	envOIDCAuth     = "OIDC_AUTH"
+)
+
+// LoadOIDCConfigFromEnv reads JWT bearer auth settings from OIDC_AUTH_FILE,
+// then from inline OIDC_AUTH JSON. Returns nil (API tokens only) when
+// neither is set.
+func LoadOIDCConfigFromEnv() (*oidc.Config, error) {
+	var raw []byte
+	if path := strings.TrimSpace(os.Getenv(envOIDCAuthFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("oidc auth: read %s: %w", path, err)
+		}
+		raw = b
+	} else if inline := strings.TrimSpace(os.Getenv(envOIDCAuth)); inline != "" {
+		raw = []byte(inline)
+	} else {
+		return nil, nil
+	}
+
+	var cfg oidc.Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("oidc auth: parse: %w", err)
+	}
+	return &cfg, nil
+}
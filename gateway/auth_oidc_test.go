@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"llm_gateway/auth"
+	"llm_gateway/completion"
+)
+
+// stubProvider accepts "jwt.<subject>" bearer tokens; "jwt.expired" and
+// "jwt.bad" are turned away.
+type stubProvider struct {
+	err error
+}
+
+func (stubProvider) Name() string { return "stub" }
+
+func (stubProvider) Recognizes(token string) bool { return strings.HasPrefix(token, "jwt.") }
+
+func (p stubProvider) Authenticate(_ context.Context, token string) (auth.TokenInfo, error) {
+	switch token {
+	case "jwt.expired":
+		return auth.TokenInfo{}, fmt.Errorf("%w: exp in the past", auth.ErrCredentialExpired)
+	case "jwt.bad":
+		return auth.TokenInfo{}, fmt.Errorf("%w: bad signature", auth.ErrInvalidCredential)
+	}
+	if p.err != nil {
+		return auth.TokenInfo{}, p.err
+	}
+	return auth.TokenInfo{Alias: strings.TrimPrefix(token, "jwt."), Labels: map[string]string{"groups": "ml"}}, nil
+}
+
+func doChatRequestWithToken(s *Server, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+		strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	s.CompletionHandler(rec, req)
+	return rec
+}
+
+func TestAuthValidate_ProviderTokenSetsSubjectAndLabels(t *testing.T) {
+	gw := newTestGatewayContext(Dependencies{Auth: fakeAuth{}, AuthProviders: []auth.Provider{stubProvider{}}})
+	gw.Request.Header.Set("Authorization", "Bearer jwt.alice")
+
+	if res := handleTokenExtractStage(gw); res.Action != ActionContinue {
+		t.Fatalf("extract: %+v", res)
+	}
+	if res := handleAuthValidateStage(gw); res.Action != ActionContinue {
+		t.Fatalf("validate: %+v", res)
+	}
+	if gw.Auth.Subject != "alice" || gw.Auth.Token.Labels["groups"] != "ml" || gw.Auth.Provider == nil {
+		t.Errorf("auth state: %+v", gw.Auth)
+	}
+}
+
+func TestCompletionHandler_APITokensWorkBesideProviders(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Content: "ok"}, {Done: true}}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, AuthProviders: []auth.Provider{stubProvider{}}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCompletionHandler_ProviderRejections(t *testing.T) {
+	cases := []struct {
+		token    string
+		provider stubProvider
+		code     string
+	}{
+		{"jwt.expired", stubProvider{}, errorCodeTokenExpired},
+		{"jwt.bad", stubProvider{}, errorCodeInvalidAPIKey},
+		{"jwt.alice", stubProvider{err: fmt.Errorf("jwks down")}, errorCodeInvalidAPIKey},
+		{"not-a-token", stubProvider{}, errorCodeInvalidAPIKey},
+	}
+	for _, c := range cases {
+		compl := &fakeCompletion{}
+		s := NewServer(Dependencies{Auth: fakeAuth{}, AuthProviders: []auth.Provider{c.provider}, Cache: &fakeCache{}, Completion: compl})
+
+		rec := doChatRequestWithToken(s, c.token)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: status %d, want 401", c.token, rec.Code)
+		}
+		if body := decodeErrorBody(t, rec.Body.Bytes()); body.Code == nil || *body.Code != c.code {
+			t.Errorf("%s: error %+v, want code %q", c.token, body, c.code)
+		}
+		if compl.got != nil {
+			t.Fatalf("%s: upstream must not be called", c.token)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"llm_gateway/auth"
+	"llm_gateway/internal/metrics"
+)
+
+// budgetRecordTimeout bounds recording one request's usage. It runs after the
+// response, detached from the (possibly cancelled) request context.
+const budgetRecordTimeout = time.Second
+
+// BudgetSpend is what a token has used in the current UTC day and month.
+// Usage is recorded for every API token, budget or not.
+type BudgetSpend struct {
+	DayTokens    int64   `json:"day_tokens"`
+	MonthTokens  int64   `json:"month_tokens"`
+	DayCostUSD   float64 `json:"day_cost_usd"`
+	MonthCostUSD float64 `json:"month_cost_usd"`
+}
+
+// BudgetUsage is what one request adds to its token's spend. CostUSD is zero
+// for models without a price.
+type BudgetUsage struct {
+	Tokens  int64
+	CostUSD float64
+}
+
+// BudgetStatus is a token's budget and spend as of one read.
+type BudgetStatus struct {
+	ID     string       `json:"id"`
+	Alias  string       `json:"alias"`
+	Budget *auth.Budget `json:"budget"` // nil when the token has none
+	Spend  BudgetSpend  `json:"spend"`
+
+	// Set only with a budget: the spend in the budget's period, and when
+	// that period ends.
+	UsedTokens  int64      `json:"used_tokens,omitempty"`
+	UsedCostUSD float64    `json:"used_cost_usd,omitempty"`
+	ResetsAt    *time.Time `json:"resets_at,omitempty"`
+}
+
+func newBudgetStatus(info auth.TokenInfo, spend BudgetSpend, now time.Time) BudgetStatus {
+	st := BudgetStatus{ID: info.ID, Alias: info.Alias, Budget: info.Budget, Spend: spend}
+	if info.Budget == nil {
+		return st
+	}
+	period := info.Budget.PeriodOrDefault()
+	resetsAt := budgetPeriodEnd(period, now)
+	st.ResetsAt = &resetsAt
+	st.UsedTokens, st.UsedCostUSD = spend.MonthTokens, spend.MonthCostUSD
+	if period == auth.BudgetPeriodDay {
+		st.UsedTokens, st.UsedCostUSD = spend.DayTokens, spend.DayCostUSD
+	}
+	return st
+}
+
+// hardLimitUsage describes the hard limit the token has spent, e.g. "120 of
+// 100 tokens", or returns "" when none is.
+func (st BudgetStatus) hardLimitUsage() string {
+	if st.Budget == nil {
+		return ""
+	}
+	if st.Budget.HardLimitTokens > 0 && st.UsedTokens >= st.Budget.HardLimitTokens {
+		return fmt.Sprintf("%d of %d tokens", st.UsedTokens, st.Budget.HardLimitTokens)
+	}
+	if st.Budget.HardLimitUSD > 0 && st.UsedCostUSD >= st.Budget.HardLimitUSD {
+		return fmt.Sprintf("$%.2f of $%.2f", st.UsedCostUSD, st.Budget.HardLimitUSD)
+	}
+	return ""
+}
+
+func (st BudgetStatus) hardLimitReached() bool {
+	return st.hardLimitUsage() != ""
+}
+
+func (st BudgetStatus) softLimitReached() bool {
+	if st.Budget == nil {
+		return false
+	}
+	return (st.Budget.SoftLimitTokens > 0 && st.UsedTokens >= st.Budget.SoftLimitTokens) ||
+		(st.Budget.SoftLimitUSD > 0 && st.UsedCostUSD >= st.Budget.SoftLimitUSD)
+}
+
+// setHeaders writes the x-budget-* headers for a key with a budget.
+func (st BudgetStatus) setHeaders(h http.Header) {
+	h.Set("x-budget-used-tokens", strconv.FormatInt(st.UsedTokens, 10))
+	if st.Budget.SoftLimitTokens > 0 {
+		h.Set("x-budget-soft-limit-tokens", strconv.FormatInt(st.Budget.SoftLimitTokens, 10))
+	}
+	if st.Budget.HardLimitTokens > 0 {
+		h.Set("x-budget-hard-limit-tokens", strconv.FormatInt(st.Budget.HardLimitTokens, 10))
+	}
+	if st.Budget.SoftLimitUSD > 0 || st.Budget.HardLimitUSD > 0 {
+		h.Set("x-budget-used-usd", formatCost(st.UsedCostUSD))
+	}
+	if st.Budget.SoftLimitUSD > 0 {
+		h.Set("x-budget-soft-limit-usd", formatCost(st.Budget.SoftLimitUSD))
+	}
+	if st.Budget.HardLimitUSD > 0 {
+		h.Set("x-budget-hard-limit-usd", formatCost(st.Budget.HardLimitUSD))
+	}
+	h.Set("x-budget-reset", st.ResetsAt.Format(time.RFC3339))
+	if st.softLimitReached() {
+		h.Set("x-budget-warning", "soft_limit_reached")
+	}
+}
+
+// budgetPeriodStart returns the start of the UTC day or month containing now.
+func budgetPeriodStart(period string, now time.Time) time.Time {
+	now = now.UTC()
+	if period == auth.BudgetPeriodDay {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func budgetPeriodEnd(period string, now time.Time) time.Time {
+	start := budgetPeriodStart(period, now)
+	if period == auth.BudgetPeriodDay {
+		return start.AddDate(0, 0, 1)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// budgetPeriodIDs names the UTC day and month containing now, e.g.
+// "2026-10-17" and "2026-10"; spend counters are keyed by them.
+func budgetPeriodIDs(now time.Time) (day, month string) {
+	now = now.UTC()
+	return now.Format("2006-01-02"), now.Format("2006-01")
+}
+
+// BudgetStore keeps per-token spend, keyed by token ID; the budgets
+// themselves live on the tokens (auth.TokenInfo.Budget). Implementations:
+// MemoryBudgetStore (per process, lost on restart) and RedisBudgetStore
+// (shared by every gateway replica).
+type BudgetStore interface {
+	Spend(ctx context.Context, id string) (BudgetSpend, error)
+	// ResetSpend zeroes the token's spend for the current day and month.
+	ResetSpend(ctx context.Context, id string) error
+	RecordUsage(ctx context.Context, id string, usage BudgetUsage) error
+}
+
+// MemoryBudgetStore keeps spend in process: each replica counts only what it
+// served, and a restart forgets it.
+type MemoryBudgetStore struct {
+	mu    sync.Mutex
+	spend map[string]*memorySpend
+}
+
+type memorySpend struct {
+	day, month             string
+	dayTokens, monthTokens int64
+	dayUSD, monthUSD       float64
+}
+
+func NewMemoryBudgetStore() *MemoryBudgetStore {
+	return &MemoryBudgetStore{spend: make(map[string]*memorySpend)}
+}
+
+func (s *MemoryBudgetStore) Spend(_ context.Context, id string) (BudgetSpend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp := s.current(id, time.Now())
+	return BudgetSpend{DayTokens: sp.dayTokens, MonthTokens: sp.monthTokens, DayCostUSD: sp.dayUSD, MonthCostUSD: sp.monthUSD}, nil
+}
+
+func (s *MemoryBudgetStore) ResetSpend(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.spend, id)
+	return nil
+}
+
+func (s *MemoryBudgetStore) RecordUsage(_ context.Context, id string, usage BudgetUsage) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp := s.current(id, now)
+	sp.dayTokens += usage.Tokens
+	sp.monthTokens += usage.Tokens
+	sp.dayUSD += usage.CostUSD
+	sp.monthUSD += usage.CostUSD
+	return nil
+}
+
+// current returns the counters of token id, zeroing any that belong to a
+// past day or month. Callers hold s.mu.
+func (s *MemoryBudgetStore) current(id string, now time.Time) *memorySpend {
+	day, month := budgetPeriodIDs(now)
+	sp := s.spend[id]
+	if sp == nil {
+		sp = &memorySpend{day: day, month: month}
+		s.spend[id] = sp
+	}
+	if sp.day != day {
+		sp.day, sp.dayTokens, sp.dayUSD = day, 0, 0
+	}
+	if sp.month != month {
+		sp.month, sp.monthTokens, sp.monthUSD = month, 0, 0
+	}
+	return sp
+}
+
+// handleBudgetCheckStage rejects a token whose hard budget is spent and
+// flags one past its soft limit. The budget comes with the token; only the
+// spend is read, and only for tokens that have a budget. The check reads
+// spend recorded by earlier requests, so requests already in flight can
+// overshoot the hard limit. A store error lets the request through: an
+// unreachable Redis should not take the gateway down with it.
+func handleBudgetCheckStage(gw *GatewayContext) StageResult {
+	token := gw.Auth.Token
+	if token.Budget == nil || token.ID == "" {
+		return StageResult{Action: ActionContinue}
+	}
+	spend, err := gw.Services.Budgets.Spend(gw.Context, token.ID)
+	if err != nil {
+		metrics.BudgetChecks.WithLabelValues("error").Inc()
+		slog.WarnContext(gw.Context, "budget check failed, allowing request", "err", err)
+		return StageResult{Action: ActionContinue}
+	}
+	st := newBudgetStatus(token, spend, time.Now())
+	st.setHeaders(gw.Response.Header)
+
+	if st.hardLimitReached() {
+		metrics.BudgetChecks.WithLabelValues("rejected").Inc()
+		usage := st.hardLimitUsage()
+		slog.WarnContext(gw.Context, "budget exhausted", "used", usage)
+		message := fmt.Sprintf("You exceeded your current quota: %s used this %s. The budget resets at %s.",
+			usage, st.Budget.PeriodOrDefault(), st.ResetsAt.Format(time.RFC3339))
+		gw.Response.DirectResponse = insufficientQuotaResponse(message)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusTooManyRequests, Message: "budget exhausted"}
+	}
+	result := "allowed"
+	if st.softLimitReached() {
+		result = "soft_limit"
+	}
+	metrics.BudgetChecks.WithLabelValues(result).Inc()
+	return StageResult{Action: ActionContinue}
+}
+
+// handleBudgetRecordStage adds the tokens the upstream reported, and their
+// cost, to the token's spend. Cache hits and failed requests report none;
+// credentials from an auth provider have no token ID and no budget, and are
+// not recorded.
+func handleBudgetRecordStage(gw *GatewayContext) StageResult {
+	id := gw.Auth.Token.ID
+	if id == "" || gw.Stream.TokenUsage <= 0 {
+		return StageResult{Action: ActionContinue}
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(gw.Context), budgetRecordTimeout)
+	defer cancel()
+	usage := BudgetUsage{Tokens: int64(gw.Stream.TokenUsage), CostUSD: gw.Stream.CostUSD}
+	if err := gw.Services.Budgets.RecordUsage(ctx, id, usage); err != nil {
+		slog.ErrorContext(gw.Context, "budget usage record failed", "tokens", usage.Tokens, "cost_usd", usage.CostUSD, "err", err)
+	}
+	return StageResult{Action: ActionContinue}
+}
+
+func insufficientQuotaResponse(message string) *DirectResponse {
+	return newJSONDirectResponse(http.StatusTooManyRequests,
+		newErrorBody(errorTypeInsufficientQuota, errorCodeInsufficientQuota, "", message))
+}
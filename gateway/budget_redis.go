@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"llm_gateway/auth"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	budgetKeyPrefix = "budget:"
+
+	// redisBudgetTimeout bounds the budget read on the request path.
+	redisBudgetTimeout = 100 * time.Millisecond
+	// budgetSpendGrace keeps a spend counter past its period so replicas
+	// with slightly skewed clocks still find it.
+	budgetSpendGrace = 24 * time.Hour
+)
+
+// RedisBudgetStore keeps spend in Redis, shared by every gateway replica.
+// Counters expire shortly after their day or month ends.
+type RedisBudgetStore struct {
+	client goredis.UniversalClient
+}
+
+func NewRedisBudgetStore(client goredis.UniversalClient) *RedisBudgetStore {
+	return &RedisBudgetStore{client: client}
+}
+
+func (s *RedisBudgetStore) Spend(ctx context.Context, id string) (BudgetSpend, error) {
+	day, month := budgetPeriodIDs(time.Now())
+	ctx, cancel := context.WithTimeout(ctx, redisBudgetTimeout)
+	defer cancel()
+	vals, err := s.client.MGet(ctx,
+		budgetSpendKey(id, day), budgetSpendKey(id, month),
+		budgetCostKey(id, day), budgetCostKey(id, month)).Result()
+	if err != nil {
+		return BudgetSpend{}, fmt.Errorf("budget: read %s: %w", id, err)
+	}
+
+	var spend BudgetSpend
+	if spend.DayTokens, err = parseRedisCounter(vals[0]); err != nil {
+		return BudgetSpend{}, fmt.Errorf("budget: day spend of %s: %w", id, err)
+	}
+	if spend.MonthTokens, err = parseRedisCounter(vals[1]); err != nil {
+		return BudgetSpend{}, fmt.Errorf("budget: month spend of %s: %w", id, err)
+	}
+	if spend.DayCostUSD, err = parseRedisFloat(vals[2]); err != nil {
+		return BudgetSpend{}, fmt.Errorf("budget: day cost of %s: %w", id, err)
+	}
+	if spend.MonthCostUSD, err = parseRedisFloat(vals[3]); err != nil {
+		return BudgetSpend{}, fmt.Errorf("budget: month cost of %s: %w", id, err)
+	}
+	return spend, nil
+}
+
+func (s *RedisBudgetStore) ResetSpend(ctx context.Context, id string) error {
+	day, month := budgetPeriodIDs(time.Now())
+	keys := []string{budgetSpendKey(id, day), budgetSpendKey(id, month), budgetCostKey(id, day), budgetCostKey(id, month)}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("budget: reset %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *RedisBudgetStore) RecordUsage(ctx context.Context, id string, usage BudgetUsage) error {
+	now := time.Now()
+	day, month := budgetPeriodIDs(now)
+	dayEnd := budgetPeriodEnd(auth.BudgetPeriodDay, now).Add(budgetSpendGrace)
+	monthEnd := budgetPeriodEnd(auth.BudgetPeriodMonth, now).Add(budgetSpendGrace)
+	_, err := s.client.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		p.IncrBy(ctx, budgetSpendKey(id, day), usage.Tokens)
+		p.ExpireAt(ctx, budgetSpendKey(id, day), dayEnd)
+		p.IncrBy(ctx, budgetSpendKey(id, month), usage.Tokens)
+		p.ExpireAt(ctx, budgetSpendKey(id, month), monthEnd)
+		if usage.CostUSD > 0 {
+			p.IncrByFloat(ctx, budgetCostKey(id, day), usage.CostUSD)
+			p.ExpireAt(ctx, budgetCostKey(id, day), dayEnd)
+			p.IncrByFloat(ctx, budgetCostKey(id, month), usage.CostUSD)
+			p.ExpireAt(ctx, budgetCostKey(id, month), monthEnd)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("budget: record %s: %w", id, err)
+	}
+	return nil
+}
+
+// parseRedisCounter reads an MGET value that is either nil (no usage yet) or
+// an integer counter.
+func parseRedisCounter(v any) (int64, error) {
+	if v == nil {
+		return 0, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, errors.New("unexpected value type")
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// parseRedisFloat is parseRedisCounter for INCRBYFLOAT counters.
+func parseRedisFloat(v any) (float64, error) {
+	if v == nil {
+		return 0, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, errors.New("unexpected value type")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// budgetSpendKey counts token id's tokens in one period ("2026-10-17" or
+// "2026-10"). The hash tag keeps a token's counters in one slot so one MGET
+// reads them all on Redis Cluster.
+func budgetSpendKey(id, period string) string {
+	return budgetKeyPrefix + "{" + id + "}:tokens:" + period
+}
+
+// budgetCostKey sums token id's cost in USD over one period.
+func budgetCostKey(id, period string) string {
+	return budgetKeyPrefix + "{" + id + "}:usd:" + period
+}
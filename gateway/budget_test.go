@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+	"llm_gateway/completion"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const budgetTestTokenID = "tok-1"
+
+// newBudgetTestServer authenticates every request as token budgetTestTokenID
+// of alias "tester", with budget.
+func newBudgetTestServer(budgets BudgetStore, budget *auth.Budget) *Server {
+	return NewServer(Dependencies{
+		Auth:  fakeAuth{info: &auth.TokenInfo{Alias: "tester", ID: budgetTestTokenID, Budget: budget}},
+		Cache: &fakeCache{},
+		Completion: &fakeCompletion{chunks: []*completion.CompletionChunk{
+			{Content: "hi"},
+			{Done: true, PromptTokens: 3, CompletionTokens: 2, TokenUsage: 5},
+		}},
+		Budgets: budgets,
+	})
+}
+
+const budgetTestBody = `{"model":"m","messages":[{"role":"user","content":"hi"}]}`
+
+func TestBudget_Validate(t *testing.T) {
+	for name, b := range map[string]auth.Budget{
+		"bad period":      {Period: "week", HardLimitTokens: 1},
+		"no limits":       {Period: "day"},
+		"negative":        {SoftLimitTokens: -1, HardLimitTokens: 10},
+		"soft above hard": {SoftLimitTokens: 20, HardLimitTokens: 10},
+		"usd soft above":  {SoftLimitUSD: 20, HardLimitUSD: 10},
+	} {
+		if err := b.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+	if err := (auth.Budget{SoftLimitTokens: 10}).Validate(); err != nil {
+		t.Errorf("soft-only budget rejected: %v", err)
+	}
+}
+
+func TestBudgetPeriodEnd_RollsOverYear(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC)
+	if got, want := budgetPeriodEnd(auth.BudgetPeriodMonth, now), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("month end: got %v, want %v", got, want)
+	}
+	if got, want := budgetPeriodEnd(auth.BudgetPeriodDay, now), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("day end: got %v, want %v", got, want)
+	}
+}
+
+func TestMemoryBudgetStore_RecordAndReset(t *testing.T) {
+	s := NewMemoryBudgetStore()
+	ctx := context.Background()
+	_ = s.RecordUsage(ctx, "a", BudgetUsage{Tokens: 7})
+	_ = s.RecordUsage(ctx, "a", BudgetUsage{Tokens: 3})
+	_ = s.RecordUsage(ctx, "b", BudgetUsage{Tokens: 1})
+
+	if spend, _ := s.Spend(ctx, "a"); spend != (BudgetSpend{DayTokens: 10, MonthTokens: 10}) {
+		t.Fatalf("spend: got %+v", spend)
+	}
+	_ = s.ResetSpend(ctx, "a")
+	if spend, _ := s.Spend(ctx, "a"); spend != (BudgetSpend{}) {
+		t.Fatalf("after reset: got %+v, want zero", spend)
+	}
+	if spend, _ := s.Spend(ctx, "b"); spend.MonthTokens != 1 {
+		t.Fatalf("other token: got %+v, want its spend kept", spend)
+	}
+}
+
+func TestNewBudgetStatus_UsesBudgetPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	spend := BudgetSpend{DayTokens: 10, MonthTokens: 400}
+	info := auth.TokenInfo{Alias: "a", ID: "t", Budget: &auth.Budget{Period: auth.BudgetPeriodDay, HardLimitTokens: 100}}
+
+	st := newBudgetStatus(info, spend, now)
+	if st.UsedTokens != 10 || st.ResetsAt == nil || !st.ResetsAt.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("day budget: got %+v", st)
+	}
+	info.Budget = nil
+	if st := newBudgetStatus(info, spend, now); st.UsedTokens != 0 || st.ResetsAt != nil || st.Spend != spend {
+		t.Fatalf("no budget: got %+v, want only the spend", st)
+	}
+}
+
+func TestCompletionHandler_BudgetRecordsUsage(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	s := newBudgetTestServer(budgets, nil)
+
+	if rec := doChatRequest(t, s, budgetTestBody); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	spend, _ := budgets.Spend(context.Background(), budgetTestTokenID)
+	if spend.MonthTokens != 5 {
+		t.Fatalf("month spend: got %d, want the 5 tokens the upstream reported", spend.MonthTokens)
+	}
+}
+
+func TestCompletionHandler_BudgetSoftLimitWarns(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), budgetTestTokenID, BudgetUsage{Tokens: 5})
+	s := newBudgetTestServer(budgets, &auth.Budget{SoftLimitTokens: 5, HardLimitTokens: 100})
+
+	rec := doChatRequest(t, s, budgetTestBody)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("x-budget-warning"); got != "soft_limit_reached" {
+		t.Errorf("x-budget-warning: got %q", got)
+	}
+	if got := rec.Header().Get("x-budget-hard-limit-tokens"); got != "100" {
+		t.Errorf("x-budget-hard-limit-tokens: got %q", got)
+	}
+}
+
+func TestCompletionHandler_BudgetHardLimitRejects(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), budgetTestTokenID, BudgetUsage{Tokens: 5})
+	s := newBudgetTestServer(budgets, &auth.Budget{HardLimitTokens: 5})
+
+	rec := doChatRequest(t, s, budgetTestBody)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Type != "insufficient_quota" || body.Error.Code == nil || *body.Error.Code != "insufficient_quota" {
+		t.Fatalf("error: got %+v", body.Error)
+	}
+}
+
+func TestCompletionHandler_BudgetStoreDownAllowsRequest(t *testing.T) {
+	rdb := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	s := newBudgetTestServer(NewRedisBudgetStore(rdb), &auth.Budget{HardLimitTokens: 5})
+
+	if rec := doChatRequest(t, s, budgetTestBody); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminBudget_SetGetReset(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), "known", BudgetUsage{Tokens: 40})
+	tokens := &mockTokenAdmin{}
+	_, mux := newAdminTestServer(t, Dependencies{Auth: tokens, Budgets: budgets})
+
+	req := httptest.NewRequest("POST", "/admin/budget", strings.NewReader(`{"id":"known","period":"month","hard_limit_tokens":100}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("set: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if tokens.budget == nil || tokens.budget.HardLimitTokens != 100 {
+		t.Fatalf("set: token budget got %+v", tokens.budget)
+	}
+
+	req = httptest.NewRequest("GET", "/admin/budget?id=known", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var st BudgetStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.ID != "known" || st.Alias != "alice" || st.Budget == nil || st.Budget.HardLimitTokens != 100 || st.UsedTokens != 40 {
+		t.Fatalf("get: got %+v", st)
+	}
+
+	req = httptest.NewRequest("POST", "/admin/budget/reset", strings.NewReader(`{"id":"known"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if spend, _ := budgets.Spend(context.Background(), "known"); spend.MonthTokens != 0 {
+		t.Fatalf("after reset: got %+v", spend)
+	}
+
+	req = httptest.NewRequest("DELETE", "/admin/budget", strings.NewReader(`{"id":"known"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || tokens.budget != nil {
+		t.Fatalf("delete: status=%d, token budget %+v", w.Code, tokens.budget)
+	}
+}
+
+func TestAdminBudget_UnknownTokenIs404(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/admin/budget?id=nope", nil),
+		httptest.NewRequest("POST", "/admin/budget", strings.NewReader(`{"id":"nope","hard_limit_tokens":1}`)),
+		httptest.NewRequest("POST", "/admin/budget/reset", strings.NewReader(`{"id":"nope"}`)),
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: status=%d body=%s", req.Method, req.URL, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAdminBudget_RejectsInvalidBudget(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+
+	req := httptest.NewRequest("POST", "/admin/budget", strings.NewReader(`{"id":"known","period":"week","hard_limit_tokens":1}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminBudget_USDLimitsNeedPricing(t *testing.T) {
+	body := `{"id":"known","hard_limit_usd":50}`
+
+	_, mux := newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/budget", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unpriced: status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	_, mux = newAdminTestServer(t, Dependencies{Auth: &mockTokenAdmin{}, Pricing: Pricing{"m": {Input: 1}}})
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/budget", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("priced: status=%d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestCompletionHandler_BudgetUSDHardLimitRejects(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), budgetTestTokenID, BudgetUsage{Tokens: 10, CostUSD: 1.25})
+	s := newBudgetTestServer(budgets, &auth.Budget{Period: auth.BudgetPeriodDay, HardLimitUSD: 1})
+
+	rec := doChatRequest(t, s, budgetTestBody)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "$1.25 of $1.00") {
+		t.Errorf("body: got %s, want the USD spend in the message", rec.Body.String())
+	}
+	if got := rec.Header().Get("x-budget-used-usd"); got != "1.25" {
+		t.Errorf("x-budget-used-usd: got %q", got)
+	}
+}
+
+func TestCompletionHandler_BudgetIsPerToken(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), "other-token", BudgetUsage{Tokens: 100})
+	s := newBudgetTestServer(budgets, &auth.Budget{HardLimitTokens: 5})
+
+	if rec := doChatRequest(t, s, budgetTestBody); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s; another token's spend must not count", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminCreate_AcceptsBudget(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: fakeAuth{}})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/create", strings.NewReader(`{"alias":"a","budget":{"hard_limit_tokens":1000}}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/create", strings.NewReader(`{"alias":"a","budget":{"hard_limit_usd":5}}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unpriced USD budget: status=%d body=%s", w.Code, w.Body.String())
+	}
+}
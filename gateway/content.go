@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"llm_gateway/completion"
+)
+
+const (
+	contentPartText       = "text"
+	contentPartImageURL   = "image_url"
+	contentPartInputAudio = "input_audio"
+)
+
+// MessageContent is a message's content in either OpenAI form: a plain string
+// (Text) or an array of content parts (Parts, non-nil). JSON null decodes to
+// the empty string form, as sent on assistant turns that only call tools.
+type MessageContent struct {
+	Text  string
+	Parts []ContentPart
+}
+
+func (c *MessageContent) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		*c = MessageContent{}
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		*c = MessageContent{}
+		return json.Unmarshal(b, &c.Text)
+	case len(b) > 0 && b[0] == '[':
+		var parts []ContentPart
+		if err := json.Unmarshal(b, &parts); err != nil {
+			return err
+		}
+		for i, p := range parts {
+			if err := p.validate(); err != nil {
+				return fmt.Errorf("content[%d]: %w", i, err)
+			}
+		}
+		*c = MessageContent{Parts: parts}
+		return nil
+	default:
+		return fmt.Errorf("content must be a string or an array of content parts")
+	}
+}
+
+func (c MessageContent) MarshalJSON() ([]byte, error) {
+	if c.Parts != nil {
+		return json.Marshal(c.Parts)
+	}
+	return json.Marshal(c.Text)
+}
+
+// text returns the string content, or the text parts joined by a space. Image
+// and audio parts contribute nothing.
+func (c MessageContent) text() string {
+	if c.Parts == nil {
+		return c.Text
+	}
+	var texts []string
+	for _, p := range c.Parts {
+		if p.Type == contentPartText && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// hasMedia reports whether any part is something other than text.
+func (c MessageContent) hasMedia() bool {
+	for _, p := range c.Parts {
+		if p.Type != contentPartText {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ContentPart) validate() error {
+	switch p.Type {
+	case contentPartText:
+		return nil
+	case contentPartImageURL:
+		if p.ImageURL == nil || p.ImageURL.URL == "" {
+			return fmt.Errorf("image_url part requires image_url.url")
+		}
+	case contentPartInputAudio:
+		if p.InputAudio == nil || p.InputAudio.Data == "" {
+			return fmt.Errorf("input_audio part requires input_audio.data")
+		}
+	default:
+		return fmt.Errorf("unsupported content part type %q", p.Type)
+	}
+	return nil
+}
+
+// hasMedia reports whether any message carries image or audio parts. The
+// cache key is text only, so two prompts about different images would collide.
+func (gw *GatewayContext) hasMedia() bool {
+	if gw.Request.Chat == nil {
+		return false
+	}
+	for _, m := range gw.Request.Chat.Messages {
+		if m.Content.hasMedia() {
+			return true
+		}
+	}
+	return false
+}
+
+func buildUpstreamParts(parts []ContentPart) []completion.ContentPart {
+	if parts == nil {
+		return nil
+	}
+	out := make([]completion.ContentPart, 0, len(parts))
+	for _, p := range parts {
+		part := completion.ContentPart{Type: p.Type, Text: p.Text}
+		if p.ImageURL != nil {
+			part.ImageURL = p.ImageURL.URL
+			part.ImageDetail = p.ImageURL.Detail
+		}
+		if p.InputAudio != nil {
+			part.AudioData = p.InputAudio.Data
+			part.AudioFormat = p.InputAudio.Format
+		}
+		out = append(out, part)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"llm_gateway/completion"
+)
+
+func TestMessageContent_RoundTripsBothForms(t *testing.T) {
+	in := `[{"role":"user","content":"hi"},{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"https://x/cat.png","detail":"low"}}]}]`
+
+	var msgs []Message
+	if err := json.Unmarshal([]byte(in), &msgs); err != nil {
+		t.Fatal(err)
+	}
+	if msgs[0].Content.Text != "hi" || msgs[0].Content.Parts != nil {
+		t.Errorf("string form: got %+v", msgs[0].Content)
+	}
+	if len(msgs[1].Content.Parts) != 2 || msgs[1].Content.Parts[1].ImageURL.Detail != "low" {
+		t.Errorf("parts form: got %+v", msgs[1].Content)
+	}
+
+	out, err := json.Marshal(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("round trip:\n got %s\nwant %s", out, in)
+	}
+}
+
+func TestMessageContent_RejectsUnknownPartType(t *testing.T) {
+	var c MessageContent
+	if err := json.Unmarshal([]byte(`[{"type":"video","video":{}}]`), &c); err == nil {
+		t.Fatal("expected error for unsupported part type")
+	}
+}
+
+func TestCompletionHandler_ForwardsImagePartsAndBypassesCache(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Content: "a cat"}, {Done: true}}}
+	cache := &fakeCache{hit: true, answer: "stale"}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: cache, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":[
+		{"type":"text","text":"what is this?"},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA","detail":"high"}},
+		{"type":"input_audio","input_audio":{"data":"UklGRg==","format":"wav"}}
+	]}]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if compl.got == nil {
+		t.Fatal("upstream not called; cache must be bypassed for media requests")
+	}
+	want := []completion.ContentPart{
+		{Type: "text", Text: "what is this?"},
+		{Type: "image_url", ImageURL: "data:image/png;base64,AAAA", ImageDetail: "high"},
+		{Type: "input_audio", AudioData: "UklGRg==", AudioFormat: "wav"},
+	}
+	got := compl.got.Messages[0].Parts
+	if len(got) != len(want) {
+		t.Fatalf("parts: got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("part[%d]: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if len(cache.sets) != 0 {
+		t.Errorf("media turn must not be cached, got %d sets", len(cache.sets))
+	}
+}
+
+func TestCompletionHandler_TextOnlyPartsKeyCacheOnText(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Content: "hello"}, {Done: true}}}
+	cache := &fakeCache{}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: cache, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"text","text":"there"}]}]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(cache.sets) != 1 || cache.sets[0].UserPrompt != "hi there" {
+		t.Errorf("cache writes: got %+v", cache.sets)
+	}
+}
@@ -10,16 +10,26 @@ import (
 	"llm_gateway/auth"
 	"llm_gateway/cache"
 	"llm_gateway/completion"
+	"llm_gateway/embedding"
 	"llm_gateway/rag"
 )
 
 type Dependencies struct {
-	Auth            auth.Service
-	Cache           cache.Service
-	Completion      completion.Service
-	CompletionStats completion.StatsProvider // nil = admin stats endpoint returns 503
-	CompletionAdmin completion.Admin         // nil = admin pool-mgmt endpoints return 503
-	RAG             rag.Service              // nil = RAG disabled
+	Auth             auth.Service
+	AuthProviders    []auth.Provider // tried, in order, for bearer tokens that are not API tokens
+	Cache            cache.Service
+	Completion       completion.Service
+	CompletionStats  completion.StatsProvider // nil = admin stats endpoint returns 503
+	CompletionAdmin  completion.Admin         // nil = admin pool-mgmt endpoints return 503
+	CompletionModels completion.ModelLister   // nil = /v1/models returns 503
+	ModelAllowlist   ModelAllowlist           // nil = every token may use every model
+	RateLimiter      RateLimiter              // nil = NewServer uses a LocalRateLimiter with the built-in defaults
+	Budgets          BudgetStore              // nil = NewServer uses a MemoryBudgetStore
+	Pricing          Pricing                  // nil = requests are not priced
+	Usage            UsageLedger              // nil = NewServer uses a MemoryUsageLedger
+	Embedding        embedding.BatchService   // nil = /v1/embeddings returns 503
+	RAG              rag.Service              // nil = RAG disabled
+	AdminCredentials []AdminCredential        // nil = ADMIN_SECRET is the only admin secret
 }
 
 type GatewayContext struct {
@@ -47,8 +57,11 @@ type RequestState struct {
 	RemoteAddr    string
 	BodyBytes     []byte
 	Chat          *ChatCompleteionRequest
-	PromptText    string
-	NormalizedKey string
+	Embeddings    *EmbeddingsRequest   // set by the embeddings pipeline only
+	Inputs        []string             // embedding inputs, normalised from the string or array form
+	Messages      []completion.Message // structured turns sent upstream; RAG context is injected here
+	PromptText    string               // flattened text used for RAG retrieval and audit only
+	NormalizedKey string               // semantic-cache key, derived once from the original messages
 }
 
 type AuthState struct {
@@ -56,6 +69,8 @@ type AuthState struct {
 	Subject      string
 	Valid        bool
 	RejectReason string
+	Token        auth.TokenInfo // what the token grants; set with Valid
+	Provider     auth.Provider  // nil for API tokens
 }
 
 type RouteState struct {
@@ -75,7 +90,18 @@ type StreamState struct {
 	ChunkIndex   int
 	CurrentChunk *completion.CompletionChunk
 	FullAnswer   strings.Builder
+	ToolCalls    []completion.ToolCall // assembled from streamed deltas, ordered by index
+	FinishReason string                // as reported by the upstream on the Done chunk
 	TokenUsage   int
+
+	// Reported by the upstream on the Done chunk.
+	PromptTokens       int
+	CachedPromptTokens int
+	CompletionTokens   int
+	Endpoint           string // pool endpoint that served the stream
+
+	CostUSD float64 // set by priceUsage; meaningful only when Priced
+	Priced  bool
 }
 
 type ResponseState struct {
@@ -85,10 +111,12 @@ type ResponseState struct {
 	DirectResponse *DirectResponse
 	StreamStarted  bool
 	FromCache      bool
+	CompletionID   string // chatcmpl-*; assigned on first use and shared by every chunk
 }
 
 type RuntimeState struct {
-	ParallelSlotAcquired bool
+	RateLimitRelease func()           // frees the caller's concurrency slot; nil until rate_limit_handler admits the request
+	TokenReconcile   func(actual int) // settles the token pre-charge; nil unless token_limit_handler charged one
 }
 
 type DirectResponseKind string
@@ -132,20 +160,67 @@ func newGatewayContext(w http.ResponseWriter, r *http.Request, services Dependen
 	}
 }
 
+// completionID returns the id shared by every chunk (or the single body) of
+// this request's chat completion.
+func (gw *GatewayContext) completionID() string {
+	if gw.Response.CompletionID == "" {
+		gw.Response.CompletionID = newChatCompletionID()
+	}
+	return gw.Response.CompletionID
+}
+
+// wantsStream reports whether the client asked for an SSE response. Requests
+// rejected before the body is decoded never reach a stream/JSON branch, so a
+// nil Chat is treated as non-streaming.
+func (gw *GatewayContext) wantsStream() bool {
+	return gw.Request.Chat != nil && gw.Request.Chat.Stream
+}
+
+// cacheBypassReason names why the semantic cache must neither serve nor store
+// this request, or returns "" when it may.
+func (gw *GatewayContext) cacheBypassReason() string {
+	switch {
+	case gw.usesTools():
+		return "tools"
+	case gw.hasMedia():
+		return "media"
+	}
+	return ""
+}
+
 func buildPromptText(messages []Message) string {
 	var builder strings.Builder
 	for _, message := range messages {
-		if message.Content == "" {
+		text := message.Content.text()
+		if text == "" {
 			continue
 		}
 		if builder.Len() > 0 {
 			builder.WriteByte(' ')
 		}
-		builder.WriteString(message.Content)
+		builder.WriteString(text)
 	}
 	return builder.String()
 }
 
+// buildUpstreamMessages copies the client's turns into the transport-neutral
+// shape, preserving role, content (string or parts), name and tool-call fields
+// for every message.
+func buildUpstreamMessages(messages []Message) []completion.Message {
+	out := make([]completion.Message, 0, len(messages))
+	for _, message := range messages {
+		out = append(out, completion.Message{
+			Role:       message.Role,
+			Content:    message.Content.Text,
+			Parts:      buildUpstreamParts(message.Content.Parts),
+			Name:       message.Name,
+			ToolCalls:  fromToolCalls(message.ToolCalls),
+			ToolCallID: message.ToolCallID,
+		})
+	}
+	return out
+}
+
 func newRequestID() string {
 	return fmt.Sprintf("req-%d", time.Now().UnixNano())
 }
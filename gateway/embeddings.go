@@ -0,0 +1,219 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+)
+
+const (
+	encodingFormatFloat  = "float"
+	encodingFormatBase64 = "base64"
+)
+
+// defaultEmbeddingsPipeline guards /v1/embeddings with the same CORS, token,
+// rate-limit, token-limit and budget checks as chat completions. The body is
+// decoded into EmbeddingsRequest; prompt building, RAG, mock and cache stages
+// do not apply.
+func defaultEmbeddingsPipeline() *Pipeline {
+	return NewPipeline(
+		newStageHandler("cors_handler", []StageName{StageRequestReceived}, handleCORSStage),
+		newStageHandler("token_extract_handler", []StageName{StageRequestReceived}, handleTokenExtractStage),
+		newStageHandler("embeddings_decode_handler", []StageName{StageRequestDecoded}, handleEmbeddingsDecodeStage),
+		newStageHandler("auth_validate_handler", []StageName{StageBeforeUpstream}, handleAuthValidateStage),
+		newStageHandler("rate_limit_handler", []StageName{StageBeforeUpstream}, handleRateLimitStage),
+		newStageHandler("embeddings_model_handler", []StageName{StageBeforeUpstream}, handleEmbeddingsModelStage),
+		newStageHandler("budget_check_handler", []StageName{StageBeforeUpstream}, handleBudgetCheckStage),
+		newStageHandler("token_limit_handler", []StageName{StageBeforeUpstream}, handleEmbeddingsTokenLimitStage),
+		newStageHandler("token_reconcile_handler", []StageName{StageResponseComplete}, handleTokenReconcileStage),
+		newStageHandler("budget_record_handler", []StageName{StageResponseComplete}, handleBudgetRecordStage),
+		newStageHandler("usage_metrics_handler", []StageName{StageResponseComplete}, handleUsageMetricsStage),
+		newStageHandler("usage_record_handler", []StageName{StageResponseComplete}, handleUsageRecordStage),
+		newStageHandler("access_log_handler", []StageName{StageResponseComplete}, handleAccessLogStage),
+	)
+}
+
+func handleEmbeddingsDecodeStage(gw *GatewayContext) StageResult {
+	bodyBytes, err := io.ReadAll(gw.Request.Raw.Body)
+	if err != nil {
+		slog.ErrorContext(gw.Context, "read request body failed", "err", err)
+		return rejectInvalidRequest(gw, "Failed to parse user request", "", err)
+	}
+	defer gw.Request.Raw.Body.Close()
+
+	gw.Request.BodyBytes = bodyBytes
+
+	var req EmbeddingsRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		slog.ErrorContext(gw.Context, "parse request body failed", "err", err)
+		return rejectInvalidRequest(gw, "Failed to parse user request", "", err)
+	}
+
+	inputs, err := parseEmbeddingInput(req.Input)
+	if err != nil {
+		return rejectInvalidRequest(gw, err.Error(), "input", nil)
+	}
+
+	switch req.EncodingFormat {
+	case "", encodingFormatFloat, encodingFormatBase64:
+	default:
+		return rejectInvalidRequest(gw, fmt.Sprintf("encoding_format must be %q or %q", encodingFormatFloat, encodingFormatBase64), "encoding_format", nil)
+	}
+
+	gw.Request.Embeddings = &req
+	gw.Request.Inputs = inputs
+	gw.Route.Model = req.Model
+
+	slog.DebugContext(gw.Context, "embeddings request parsed",
+		"model", req.Model,
+		"inputs", len(inputs),
+		"encoding_format", req.EncodingFormat,
+	)
+	return StageResult{Action: ActionContinue}
+}
+
+// handleEmbeddingsModelStage resolves the model the embedding service runs and
+// checks the request against it, so the token windows, usage and cost are
+// keyed by the real model even when the request omits "model".
+func handleEmbeddingsModelStage(gw *GatewayContext) StageResult {
+	if gw.Services.Embedding == nil {
+		gw.Response.DirectResponse = newErrorResponse(http.StatusServiceUnavailable, errorCodeServiceUnavailable, "embeddings not available")
+		return StageResult{Action: ActionReject, StatusCode: http.StatusServiceUnavailable, Message: "embeddings not available"}
+	}
+
+	info, err := gw.Services.Embedding.Info(gw.Context)
+	if err != nil {
+		slog.ErrorContext(gw.Context, "embedding info failed", "err", err)
+		gw.Response.DirectResponse = newErrorResponse(http.StatusBadGateway, errorCodeUpstreamError, "Failed to get embeddings")
+		return StageResult{Action: ActionReject, StatusCode: http.StatusBadGateway, Message: "Failed to get embeddings", Err: err}
+	}
+
+	req := gw.Request.Embeddings
+	if req.Model != "" && req.Model != info.Model {
+		gw.Response.DirectResponse = modelNotFoundResponse(req.Model)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusNotFound, Message: "model not found"}
+	}
+	// Checked on the resolved model, so omitting "model" does not bypass a
+	// token's model list. Embedding models are not in /v1/models, hence 403
+	// rather than chat's 404.
+	if !gw.Services.ModelAllowlist.Allows(gw.Auth.Subject, info.Model) || !gw.Auth.Token.AllowsModel(info.Model) {
+		slog.WarnContext(gw.Context, "embedding model not allowed for token", "alias", gw.Auth.Subject, "model", info.Model)
+		gw.Response.DirectResponse = newErrorResponse(http.StatusForbidden, errorCodeModelNotAllowed,
+			fmt.Sprintf("This token may not use the model '%s'.", info.Model))
+		return StageResult{Action: ActionReject, StatusCode: http.StatusForbidden, Message: "model not allowed"}
+	}
+	if req.Dimensions != 0 && req.Dimensions != info.Dimensions {
+		message := fmt.Sprintf("dimensions %d not supported by %s (produces %d)", req.Dimensions, info.Model, info.Dimensions)
+		return rejectInvalidRequest(gw, message, "dimensions", nil)
+	}
+
+	gw.Route.Model = info.Model
+	return StageResult{Action: ActionContinue}
+}
+
+// parseEmbeddingInput accepts a string or an array of strings. Token-id
+// arrays are rejected: the embedding service only takes text.
+func parseEmbeddingInput(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("input is required")
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if single == "" {
+			return nil, fmt.Errorf("input must not be empty")
+		}
+		return []string{single}, nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, fmt.Errorf("input must be a string or an array of strings")
+	}
+	if len(many) == 0 {
+		return nil, fmt.Errorf("input must not be empty")
+	}
+	for i, s := range many {
+		if s == "" {
+			return nil, fmt.Errorf("input[%d] must not be empty", i)
+		}
+	}
+	return many, nil
+}
+
+func rejectInvalidRequest(gw *GatewayContext, message, param string, err error) StageResult {
+	gw.Response.DirectResponse = invalidRequestResponse(message, param)
+	return StageResult{Action: ActionReject, StatusCode: http.StatusBadRequest, Message: message, Err: err}
+}
+
+func invalidRequestResponse(message, param string) *DirectResponse {
+	return newJSONDirectResponse(http.StatusBadRequest, newErrorBody(errorTypeInvalidRequest, "", param, message))
+}
+
+// EmbeddingsHandler serves POST /v1/embeddings. All inputs go to the embedding
+// service in one batch; the reported model is the one the service actually
+// runs, resolved by embeddings_model_handler.
+func (s *Server) EmbeddingsHandler(w http.ResponseWriter, r *http.Request) {
+	gw := newGatewayContext(w, r, s.services)
+	defer s.finishGatewayRequest(gw, s.embeddingsPipeline)
+
+	if result, terminal := s.runPreUpstreamStages(gw, s.embeddingsPipeline); terminal {
+		s.writeTerminalStageResponse(gw, result)
+		return
+	}
+
+	req := gw.Request.Embeddings
+	result, err := s.services.Embedding.GetBatch(gw.Context, gw.Request.Inputs)
+	if err != nil {
+		slog.ErrorContext(gw.Context, "embedding batch failed", "err", err, "inputs", len(gw.Request.Inputs))
+		gw.Upstream.Error = err
+		gw.Response.DirectResponse = newErrorResponse(http.StatusBadGateway, errorCodeUpstreamError, "Failed to get embeddings")
+		s.writeDirectResponse(gw)
+		return
+	}
+	// A served batch keeps its token pre-charge even if the service reports
+	// no usage; a failed one is refunded, as a chat request without a stream.
+	gw.Upstream.Started = true
+
+	// Counted against the token's budget and the alias's token windows,
+	// and priced as input tokens.
+	gw.Stream.TokenUsage = result.PromptTokens
+	gw.Stream.PromptTokens = result.PromptTokens
+	gw.priceUsage()
+	resp := EmbeddingsResponse{
+		Object: "list",
+		Data:   make([]EmbeddingData, 0, len(result.Vectors)),
+		Model:  gw.Route.Model,
+		Usage:  EmbeddingsUsage{PromptTokens: result.PromptTokens, TotalTokens: result.PromptTokens},
+	}
+	for i, vec := range result.Vectors {
+		var encoded any = vec
+		if req.EncodingFormat == encodingFormatBase64 {
+			encoded = encodeEmbeddingBase64(vec)
+		}
+		resp.Data = append(resp.Data, EmbeddingData{Object: "embedding", Index: i, Embedding: encoded})
+	}
+
+	slog.DebugContext(gw.Context, "embeddings served",
+		"model", gw.Route.Model,
+		"inputs", len(resp.Data),
+		"prompt_tokens", result.PromptTokens,
+	)
+	gw.Response.DirectResponse = newJSONDirectResponse(http.StatusOK, resp)
+	s.writeDirectResponse(gw)
+}
+
+// encodeEmbeddingBase64 packs vec as little-endian float32s, matching what the
+// OpenAI SDKs decode for encoding_format=base64.
+func encodeEmbeddingBase64(vec []float32) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
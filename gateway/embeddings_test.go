@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"llm_gateway/auth"
+	"llm_gateway/embedding"
+)
+
+type fakeEmbedding struct {
+	got []string
+}
+
+func (f *fakeEmbedding) Get(context.Context, string) ([]float32, error) { return nil, nil }
+
+func (f *fakeEmbedding) Info(context.Context) (embedding.Info, error) {
+	return embedding.Info{Provider: "fake", Model: "embed-small", Dimensions: 2}, nil
+}
+
+// GetBatch returns [i, i+0.5] for input i so ordering is observable.
+func (f *fakeEmbedding) GetBatch(_ context.Context, inputs []string) (embedding.BatchResult, error) {
+	f.got = inputs
+	res := embedding.BatchResult{PromptTokens: 3 * len(inputs)}
+	for i := range inputs {
+		res.Vectors = append(res.Vectors, []float32{float32(i), float32(i) + 0.5})
+	}
+	return res, nil
+}
+
+func doEmbeddingsRequest(t *testing.T, s *Server, body string, withToken bool) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	s.RegisterPublicRoutes(mux)
+	req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body))
+	if withToken {
+		req.Header.Set("Authorization", "Bearer "+newTestToken(t))
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEmbeddingsHandler_BatchedInput(t *testing.T) {
+	emb := &fakeEmbedding{}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: emb})
+
+	rec := doEmbeddingsRequest(t, s, `{"model":"embed-small","input":["a","b"]}`, true)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if len(emb.got) != 2 || emb.got[0] != "a" || emb.got[1] != "b" {
+		t.Fatalf("inputs: got %v", emb.got)
+	}
+	var resp struct {
+		Object string `json:"object"`
+		Model  string `json:"model"`
+		Data   []struct {
+			Object    string    `json:"object"`
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage EmbeddingsUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Object != "list" || resp.Model != "embed-small" || len(resp.Data) != 2 {
+		t.Fatalf("response: got %+v", resp)
+	}
+	if resp.Data[1].Object != "embedding" || resp.Data[1].Index != 1 || resp.Data[1].Embedding[1] != 1.5 {
+		t.Errorf("data[1]: got %+v", resp.Data[1])
+	}
+	if resp.Usage.PromptTokens != 6 || resp.Usage.TotalTokens != 6 {
+		t.Errorf("usage: got %+v", resp.Usage)
+	}
+}
+
+func TestEmbeddingsHandler_Base64Encoding(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: &fakeEmbedding{}})
+
+	rec := doEmbeddingsRequest(t, s, `{"input":"hello","encoding_format":"base64"}`, true)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data []struct {
+			Embedding string `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(resp.Data[0].Embedding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 8 {
+		t.Fatalf("decoded length: got %d", len(raw))
+	}
+	if v := math.Float32frombits(binary.LittleEndian.Uint32(raw[4:])); v != 0.5 {
+		t.Errorf("decoded[1]: got %v", v)
+	}
+}
+
+func TestEmbeddingsHandler_RejectsTokenArrays(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: &fakeEmbedding{}})
+
+	rec := doEmbeddingsRequest(t, s, `{"input":[1,2,3]}`, true)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"param":"input"`) {
+		t.Errorf("body: got %s", rec.Body.String())
+	}
+}
+
+func TestEmbeddingsHandler_UnknownModelIs404(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: &fakeEmbedding{}})
+
+	rec := doEmbeddingsRequest(t, s, `{"model":"other","input":"x"}`, true)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestEmbeddingsHandler_ModelOutsideTokenModelsForbidden(t *testing.T) {
+	for _, body := range []string{`{"model":"embed-small","input":"x"}`, `{"input":"x"}`} {
+		emb := &fakeEmbedding{}
+		info := auth.TokenInfo{Alias: "tester", Models: []string{"gpt-4o"}}
+		s := NewServer(Dependencies{Auth: fakeAuth{info: &info}, Embedding: emb})
+
+		rec := doEmbeddingsRequest(t, s, body, true)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: status %d, want 403: %s", body, rec.Code, rec.Body)
+		}
+		if b := decodeErrorBody(t, rec.Body.Bytes()); b.Code == nil || *b.Code != errorCodeModelNotAllowed {
+			t.Errorf("%s: error %+v", body, b)
+		}
+		if emb.got != nil {
+			t.Fatalf("%s: embedding service must not be called", body)
+		}
+	}
+}
+
+func TestEmbeddingsHandler_ModelAllowlistApplies(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: &fakeEmbedding{}, ModelAllowlist: ModelAllowlist{"tester": {"gpt-4o"}}})
+
+	if rec := doEmbeddingsRequest(t, s, `{"input":"x"}`, true); rec.Code != http.StatusForbidden {
+		t.Fatalf("status: got %d, want 403", rec.Code)
+	}
+}
+
+func TestEmbeddingsHandler_RequiresToken(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Embedding: &fakeEmbedding{}})
+
+	rec := doEmbeddingsRequest(t, s, `{"input":"x"}`, false)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestEmbeddingsHandler_TokenLimitReconcilesAgainstUsage(t *testing.T) {
+	emb := &fakeEmbedding{}
+	s := NewServer(Dependencies{
+		Auth:        fakeAuth{},
+		Embedding:   emb,
+		RateLimiter: NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{TokensPerMinute: 100}}),
+	})
+	body := func(bytes int) string {
+		return `{"input":"` + strings.Repeat("x", bytes) + `"}`
+	}
+
+	// 200 bytes pre-charge 50 tokens; the service reports 3.
+	rec := doEmbeddingsRequest(t, s, body(200), true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("x-ratelimit-remaining-tokens"); got != "50" {
+		t.Errorf("x-ratelimit-remaining-tokens: got %q, want the pre-charge reflected", got)
+	}
+
+	// 97 tokens only fit because the first request was reconciled down to 3.
+	if rec := doEmbeddingsRequest(t, s, body(388), true); rec.Code != http.StatusOK {
+		t.Fatalf("second: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	emb.got = nil
+	rec = doEmbeddingsRequest(t, s, body(400), true)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third: status %d, want 429", rec.Code)
+	}
+	if b := decodeErrorBody(t, rec.Body.Bytes()); b.Code == nil || *b.Code != errorCodeRateLimitExceeded {
+		t.Errorf("third: error %+v", b)
+	}
+	if emb.got != nil {
+		t.Error("embedding service must not be called past the token limit")
+	}
+}
+
+// The model window applies to the model the service runs, whether or not the
+// request names it.
+func TestEmbeddingsHandler_ModelTokenLimitWithoutModelField(t *testing.T) {
+	s := NewServer(Dependencies{
+		Auth:      fakeAuth{},
+		Embedding: &fakeEmbedding{},
+		RateLimiter: NewLocalRateLimiter(RateLimitConfig{
+			Models: map[string]ModelRateLimit{"embed-small": {TokensPerMinute: 10}},
+		}),
+	})
+
+	rec := doEmbeddingsRequest(t, s, `{"input":"`+strings.Repeat("x", 80)+`"}`, true)
+
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "embed-small tokens") {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+}
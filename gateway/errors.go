@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"llm_gateway/completion"
+)
+
+// Error types follow the OpenAI API so SDKs map them to their own exception
+// classes. The HTTP status picks the type unless the upstream supplied one.
+const (
+	errorTypeInvalidRequest    = "invalid_request_error"
+	errorTypePermission        = "permission_error"
+	errorTypeRateLimit         = "rate_limit_error"
+	errorTypeInsufficientQuota = "insufficient_quota"
+	errorTypeServer            = "server_error"
+)
+
+// Error codes the gateway itself emits; upstream codes pass through as-is.
+const (
+	errorCodeInsufficientQuota   = "insufficient_quota"
+	errorCodeInvalidAPIKey       = "invalid_api_key"
+	errorCodeMissingScope        = "missing_scope"
+	errorCodeModelNotFound       = "model_not_found"
+	errorCodeModelNotAllowed     = "model_not_allowed"
+	errorCodeRAGCollectionDenied = "rag_collection_not_allowed"
+	errorCodeRateLimitExceeded   = "rate_limit_exceeded"
+	errorCodeServiceUnavailable  = "service_unavailable"
+	errorCodeTokenExpired        = "token_expired"
+	errorCodeUpstreamError       = "upstream_error"
+)
+
+func errorTypeForStatus(statusCode int) string {
+	switch {
+	case statusCode == http.StatusForbidden:
+		return errorTypePermission
+	case statusCode == http.StatusTooManyRequests:
+		return errorTypeRateLimit
+	case statusCode >= 500:
+		return errorTypeServer
+	}
+	return errorTypeInvalidRequest
+}
+
+// newErrorBody builds the single error model; empty code and param encode as
+// null, as OpenAI does.
+func newErrorBody(errType, code, param, message string) ErrorResponse {
+	body := ErrorResponse{Error: ErrorBody{Message: message, Type: errType}}
+	if code != "" {
+		body.Error.Code = &code
+	}
+	if param != "" {
+		body.Error.Param = &param
+	}
+	return body
+}
+
+func newErrorResponse(statusCode int, code, message string) *DirectResponse {
+	return newJSONDirectResponse(statusCode, newErrorBody(errorTypeForStatus(statusCode), code, "", message))
+}
+
+// writeErrorJSON is newErrorResponse for handlers that write to w directly.
+func writeErrorJSON(w http.ResponseWriter, statusCode int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(newErrorBody(errorTypeForStatus(statusCode), code, "", message))
+}
+
+// upstreamErrorResponse answers a failed completion call. Upstream rejections
+// of the request itself keep their status and error fields: a prompt that is
+// too long is the client's to fix, not a gateway fault. Everything else is 502.
+func upstreamErrorResponse(err error) *DirectResponse {
+	var upstreamErr *completion.Error
+	if errors.As(err, &upstreamErr) && (upstreamErr.ClientFault() || upstreamErr.StatusCode == http.StatusTooManyRequests) {
+		errType := upstreamErr.Type
+		if errType == "" {
+			errType = errorTypeForStatus(upstreamErr.StatusCode)
+		}
+		return newJSONDirectResponse(
+			upstreamErr.StatusCode,
+			newErrorBody(errType, upstreamErr.Code, upstreamErr.Param, upstreamErr.Message),
+		)
+	}
+	return newErrorResponse(http.StatusBadGateway, errorCodeUpstreamError, "Failed to get stream")
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"llm_gateway/completion"
+)
+
+func decodeErrorBody(t *testing.T, raw []byte) ErrorBody {
+	t.Helper()
+	var resp ErrorResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, raw)
+	}
+	return resp.Error
+}
+
+func TestCompletionHandler_UpstreamClientErrorKeepsStatus(t *testing.T) {
+	compl := &fakeCompletion{err: &completion.Error{
+		StatusCode: http.StatusBadRequest,
+		Type:       "invalid_request_error",
+		Code:       "context_length_exceeded",
+		Param:      "messages",
+		Message:    "This model's maximum context length is 8192 tokens.",
+	}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	body := decodeErrorBody(t, rec.Body.Bytes())
+	if body.Type != "invalid_request_error" || body.Code == nil || *body.Code != "context_length_exceeded" ||
+		body.Param == nil || *body.Param != "messages" || body.Message != "This model's maximum context length is 8192 tokens." {
+		t.Errorf("error: got %+v", body)
+	}
+}
+
+// Without stream the upstream answer is buffered, so an error arriving on the
+// stream must keep its status just like one returned by GetStream.
+func TestCompletionHandler_NonStreamUpstreamClientErrorKeepsStatus(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Error: &completion.Error{
+		StatusCode: http.StatusBadRequest,
+		Type:       "invalid_request_error",
+		Code:       "context_length_exceeded",
+		Message:    "This model's maximum context length is 8192 tokens.",
+	}}}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":false,"messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	body := decodeErrorBody(t, rec.Body.Bytes())
+	if body.Type != "invalid_request_error" || body.Code == nil || *body.Code != "context_length_exceeded" {
+		t.Errorf("error: got %+v", body)
+	}
+}
+
+func TestCompletionHandler_UpstreamRateLimitIs429(t *testing.T) {
+	compl := &fakeCompletion{err: &completion.Error{StatusCode: http.StatusTooManyRequests, Message: "slow down"}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if body := decodeErrorBody(t, rec.Body.Bytes()); body.Type != errorTypeRateLimit {
+		t.Errorf("type: got %q", body.Type)
+	}
+}
+
+func TestCompletionHandler_UpstreamServerErrorIs502(t *testing.T) {
+	for _, err := range []error{
+		&completion.Error{StatusCode: http.StatusServiceUnavailable, Message: "overloaded"},
+		&completion.Error{StatusCode: http.StatusUnauthorized, Message: "bad upstream key"},
+		errors.New("pool: no eligible endpoint"),
+	} {
+		s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: &fakeCompletion{err: err}})
+
+		rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+		if rec.Code != http.StatusBadGateway {
+			t.Fatalf("%v: status: got %d, want 502", err, rec.Code)
+		}
+		body := decodeErrorBody(t, rec.Body.Bytes())
+		if body.Type != errorTypeServer || body.Code == nil || *body.Code != errorCodeUpstreamError {
+			t.Errorf("%v: error: got %+v", err, body)
+		}
+	}
+}
+
+func TestErrorBody_EmptyCodeAndParamAreNull(t *testing.T) {
+	raw, err := json.Marshal(newErrorBody(errorTypeInvalidRequest, "", "", "bad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"error":{"message":"bad","type":"invalid_request_error","param":null,"code":null}}`
+	if string(raw) != want {
+		t.Errorf("body: got %s, want %s", raw, want)
+	}
+}
+
+func TestAdminCheck_ForbiddenUsesErrorModel(t *testing.T) {
+	t.Setenv("ADMIN_SECRET", "s3cret")
+	s := NewServer(Dependencies{Auth: fakeAuth{}})
+	req := httptest.NewRequest(http.MethodPost, "/admin/get", nil)
+	rec := httptest.NewRecorder()
+	s.AdminHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status: got %d", rec.Code)
+	}
+	if body := decodeErrorBody(t, rec.Body.Bytes()); body.Type != errorTypePermission || body.Message != "Forbidden" {
+		t.Errorf("error: got %+v", body)
+	}
+}
@@ -5,30 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	"llm_gateway/internal/metrics"
 
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/time/rate"
 )
 
 const tokenPrefix = "sk"
 const tokenEntropyLen = 32
 
-const tokenGenSpeed = 100
-const tokenCapacity = 200
-const parallelCount = 50
-
 type Middleware func(http.Handler) http.Handler
 
-var (
-	rateLimiter       = rate.NewLimiter(rate.Limit(tokenGenSpeed), tokenCapacity)
-	parallelSemaphore = make(chan struct{}, parallelCount)
-)
-
 func chain(h http.Handler, middlewares ...Middleware) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		h = middlewares[i](h)
@@ -43,9 +32,10 @@ func WithMetricsMiddleware(h http.Handler) http.Handler {
 }
 
 // metricsMiddleware records HTTPInFlight, HTTPRequestsTotal, HTTPDurationSec
-// for every public HTTP request. It uses r.URL.Path verbatim as the `path`
-// label — today the gateway only registers /v1/chat/completions, so there is
-// no high-cardinality risk. Add a whitelist here if new public paths land.
+// for every public HTTP request. The `path` label is the matched ServeMux
+// pattern (e.g. "GET /v1/models/{id...}") rather than r.URL.Path, so
+// client-chosen path segments such as model ids cannot blow up cardinality.
+// Requests that match no route are all counted under "unmatched".
 func metricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Expose trace ID before the handler can flush headers (SSE handlers
@@ -62,7 +52,10 @@ func metricsMiddleware(next http.Handler) http.Handler {
 
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		defer func() {
-			path := r.URL.Path
+			path := r.Pattern
+			if path == "" {
+				path = "unmatched"
+			}
 			metrics.HTTPRequestsTotal.WithLabelValues(path, strconv.Itoa(rec.status)).Inc()
 			metrics.HTTPDurationSec.WithLabelValues(path).Observe(time.Since(start).Seconds())
 		}()
@@ -100,17 +93,6 @@ func (s *statusRecorder) Flush() {
 	}
 }
 
-func adminCheckMiddleware(next http.Handler) http.Handler {
-	secret := os.Getenv("ADMIN_SECRET")
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("X-Admin-Secret") != secret || secret == "" {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
 func bindJSON(r *http.Request, obj interface{}) error {
 	if r.Body == nil {
 		return errors.New("request body is empty")
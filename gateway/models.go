@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	envModelAllowlistFile = "MODEL_ALLOWLIST_FILE"
+	envModelAllowlist     = "MODEL_ALLOWLIST"
+
+	modelOwner = "llm_gateway"
+)
+
+// ModelAllowlist maps a token alias to the models that token may list and
+// call. Aliases without an entry are unrestricted; "*" in a list allows any
+// model. A nil ModelAllowlist disables the check entirely.
+type ModelAllowlist map[string][]string
+
+// LoadModelAllowlistFromEnv reads the allowlist from MODEL_ALLOWLIST_FILE, then
+// from inline MODEL_ALLOWLIST JSON. Returns nil (no restrictions) when neither is set.
+func LoadModelAllowlistFromEnv() (ModelAllowlist, error) {
+	var raw []byte
+	if path := strings.TrimSpace(os.Getenv(envModelAllowlistFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("model allowlist: read %s: %w", path, err)
+		}
+		raw = b
+	} else if inline := strings.TrimSpace(os.Getenv(envModelAllowlist)); inline != "" {
+		raw = []byte(inline)
+	} else {
+		return nil, nil
+	}
+
+	var list ModelAllowlist
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("model allowlist: parse: %w", err)
+	}
+	return list, nil
+}
+
+// Allows reports whether alias may use model.
+func (l ModelAllowlist) Allows(alias, model string) bool {
+	allowed, ok := l[alias]
+	if !ok {
+		return true
+	}
+	for _, m := range allowed {
+		if m == "*" || m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the subset of models alias may see, preserving order.
+func (l ModelAllowlist) filter(alias string, models []string) []string {
+	if _, ok := l[alias]; !ok {
+		return models
+	}
+	out := make([]string, 0, len(models))
+	for _, m := range models {
+		if l.Allows(alias, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+type ModelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type ModelList struct {
+	Object string        `json:"object"`
+	Data   []ModelObject `json:"data"`
+}
+
+func newModelObject(id string) ModelObject {
+	return ModelObject{ID: id, Object: "model", OwnedBy: modelOwner}
+}
+
+// handleModelAccessStage rejects chat requests for models outside the
+// alias's allowlist or the token's own model list. Runs after
+// auth_validate_handler so Auth.Subject and Auth.Token are known.
+func handleModelAccessStage(gw *GatewayContext) StageResult {
+	if gw.Services.ModelAllowlist.Allows(gw.Auth.Subject, gw.Route.Model) && gw.Auth.Token.AllowsModel(gw.Route.Model) {
+		return StageResult{Action: ActionContinue}
+	}
+	slog.WarnContext(gw.Context, "model not in token allowlist", "model", gw.Route.Model)
+	gw.Response.DirectResponse = modelNotFoundResponse(gw.Route.Model)
+	return StageResult{Action: ActionReject, StatusCode: http.StatusNotFound, Message: "model not found"}
+}
+
+func modelNotFoundResponse(model string) *DirectResponse {
+	return newErrorResponse(
+		http.StatusNotFound,
+		errorCodeModelNotFound,
+		fmt.Sprintf("The model '%s' does not exist or you do not have access to it.", model),
+	)
+}
+
+// ModelsHandler serves GET /v1/models and GET /v1/models/{id}. The list is the
+// union of models declared by enabled completion-pool endpoints, narrowed by
+// the caller's allowlist entry.
+func (s *Server) ModelsHandler(w http.ResponseWriter, r *http.Request) {
+	gw := newGatewayContext(w, r, s.services)
+	defer s.finishGatewayRequest(gw, s.modelsPipeline)
+
+	if result, terminal := s.runPreUpstreamStages(gw, s.modelsPipeline); terminal {
+		s.writeTerminalStageResponse(gw, result)
+		return
+	}
+
+	if s.services.CompletionModels == nil {
+		gw.Response.DirectResponse = newErrorResponse(http.StatusServiceUnavailable, errorCodeServiceUnavailable, "model list not available")
+		s.writeDirectResponse(gw)
+		return
+	}
+
+	models, err := s.services.CompletionModels.ListModels(gw.Context)
+	if err != nil {
+		slog.ErrorContext(gw.Context, "list models failed", "err", err)
+		gw.Response.DirectResponse = newErrorResponse(http.StatusBadGateway, errorCodeUpstreamError, "Failed to list models")
+		s.writeDirectResponse(gw)
+		return
+	}
+	models = s.services.ModelAllowlist.filter(gw.Auth.Subject, models)
+	models = slices.DeleteFunc(models, func(m string) bool { return !gw.Auth.Token.AllowsModel(m) })
+
+	if id := r.PathValue("id"); id != "" {
+		for _, m := range models {
+			if m == id {
+				gw.Response.DirectResponse = newJSONDirectResponse(http.StatusOK, newModelObject(m))
+				s.writeDirectResponse(gw)
+				return
+			}
+		}
+		gw.Response.DirectResponse = modelNotFoundResponse(id)
+		s.writeDirectResponse(gw)
+		return
+	}
+
+	list := ModelList{Object: "list", Data: make([]ModelObject, 0, len(models))}
+	for _, m := range models {
+		list.Data = append(list.Data, newModelObject(m))
+	}
+	gw.Response.DirectResponse = newJSONDirectResponse(http.StatusOK, list)
+	s.writeDirectResponse(gw)
+}
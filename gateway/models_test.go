@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeModelLister struct {
+	models []string
+}
+
+func (f fakeModelLister) ListModels(context.Context) ([]string, error) {
+	return f.models, nil
+}
+
+func doModelsRequest(t *testing.T, s *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	s.RegisterPublicRoutes(mux)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestModelsHandler_ListAppliesAllowlist(t *testing.T) {
+	s := NewServer(Dependencies{
+		Auth:             fakeAuth{},
+		CompletionModels: fakeModelLister{models: []string{"a", "b", "c"}},
+		ModelAllowlist:   ModelAllowlist{"tester": {"a", "c"}},
+	})
+
+	rec := doModelsRequest(t, s, "/v1/models")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	var list ModelList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Object != "list" || len(list.Data) != 2 || list.Data[0].ID != "a" || list.Data[1].ID != "c" {
+		t.Fatalf("list: got %+v", list)
+	}
+	if list.Data[0].Object != "model" {
+		t.Errorf("object: got %q", list.Data[0].Object)
+	}
+}
+
+func TestModelsHandler_GetByIDWithSlash(t *testing.T) {
+	s := NewServer(Dependencies{
+		Auth:             fakeAuth{},
+		CompletionModels: fakeModelLister{models: []string{"meta-llama/Llama-3-8b"}},
+	})
+
+	rec := doModelsRequest(t, s, "/v1/models/meta-llama/Llama-3-8b")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	var m ModelObject
+	if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != "meta-llama/Llama-3-8b" {
+		t.Fatalf("id: got %q", m.ID)
+	}
+}
+
+func TestModelsHandler_GetHiddenByAllowlistIs404(t *testing.T) {
+	s := NewServer(Dependencies{
+		Auth:             fakeAuth{},
+		CompletionModels: fakeModelLister{models: []string{"a", "b"}},
+		ModelAllowlist:   ModelAllowlist{"tester": {"a"}},
+	})
+
+	rec := doModelsRequest(t, s, "/v1/models/b")
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want 404", rec.Code)
+	}
+}
+
+func TestModelsHandler_RequiresToken(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, CompletionModels: fakeModelLister{}})
+	mux := http.NewServeMux()
+	s.RegisterPublicRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status: got %d, want 401", rec.Code)
+	}
+}
+
+func TestCompletionHandler_ModelOutsideAllowlistRejected(t *testing.T) {
+	compl := &fakeCompletion{}
+	s := NewServer(Dependencies{
+		Auth:           fakeAuth{},
+		Cache:          &fakeCache{},
+		Completion:     compl,
+		ModelAllowlist: ModelAllowlist{"tester": {"allowed"}},
+	})
+
+	rec := doChatRequest(t, s, `{"model":"other","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want 404", rec.Code)
+	}
+	if compl.got != nil {
+		t.Fatal("upstream must not be called for a disallowed model")
+	}
+}
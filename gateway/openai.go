@@ -1,26 +1,206 @@
 package gateway
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ChatCompleteionRequest is the public chat body. Optional sampling
+// parameters are pointers so an explicit zero can be told apart from unset.
 type ChatCompleteionRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature,omitempty"`
-	MaxTokens   int       `json:"max_tokens,omitempty"`
-	Stream      bool      `json:"stream,omitempty"`
+	Model               string             `json:"model"`
+	Messages            []Message          `json:"messages"`
+	Temperature         *float64           `json:"temperature,omitempty"`
+	TopP                *float64           `json:"top_p,omitempty"`
+	MaxTokens           int                `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int               `json:"max_completion_tokens,omitempty"`
+	Stop                StopSequences      `json:"stop,omitempty"`
+	PresencePenalty     *float64           `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64           `json:"frequency_penalty,omitempty"`
+	Seed                *int64             `json:"seed,omitempty"`
+	LogitBias           map[string]float64 `json:"logit_bias,omitempty"`
+	User                string             `json:"user,omitempty"`
+	ResponseFormat      json.RawMessage    `json:"response_format,omitempty"` // forwarded verbatim
+	Stream              bool               `json:"stream,omitempty"`
+	Tools               []Tool             `json:"tools,omitempty"`
+	ToolChoice          json.RawMessage    `json:"tool_choice,omitempty"` // string or object; forwarded verbatim
+	ParallelToolCalls   *bool              `json:"parallel_tool_calls,omitempty"`
+}
+
+// StopSequences accepts `stop` as either a single string or an array. Null
+// and empty strings are dropped, so `"stop": null` means unset rather than a
+// stop on "", which upstreams reject or misapply.
+type StopSequences []string
+
+func (s *StopSequences) UnmarshalJSON(b []byte) error {
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		var single string
+		if err := json.Unmarshal(b, &single); err != nil {
+			return fmt.Errorf("stop must be a string or an array of strings")
+		}
+		many = []string{single}
+	}
+	*s = nil
+	for _, seq := range many {
+		if seq != "" {
+			*s = append(*s, seq)
+		}
+	}
+	return nil
 }
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type ChatStreamResponse struct {
-	Choices []struct {
-		Delta struct {
-			Content string `json:"content"`
-		} `json:"delta"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage *struct {
-		TotalTokens int `json:"total_tokens"`
-	} `json:"usage"`
+	Role       string         `json:"role"`
+	Content    MessageContent `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []ToolCall     `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// ContentPart is one element of an array-form message content.
+type ContentPart struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	ImageURL   *ImageURL   `json:"image_url,omitempty"`
+	InputAudio *InputAudio `json:"input_audio,omitempty"`
+}
+
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type InputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+type ToolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolCallDelta is one streamed fragment of a tool call. Only the first
+// fragment for a given index carries id, type and function.name.
+type ToolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// ChatCompletionChunk is one SSE event of a streamed completion. Every chunk
+// of a response shares ID, Created and Model so SDKs can merge them.
+type ChatCompletionChunk struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []ChatStreamChoice `json:"choices"`
+	Usage   *Usage             `json:"usage,omitempty"`
+}
+
+// ChatStreamChoice.FinishReason is null on every chunk except the last.
+type ChatStreamChoice struct {
+	Index        int             `json:"index"`
+	Delta        ChatStreamDelta `json:"delta"`
+	FinishReason *string         `json:"finish_reason"`
+}
+
+type ChatStreamDelta struct {
+	Role      string          `json:"role,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ChatCompletionResponse is the non-streaming (`"stream": false`) response
+// body: the whole answer in choices[0].message plus the final usage counts.
+type ChatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   Usage                  `json:"usage"`
+}
+
+type ChatCompletionChoice struct {
+	Index        int              `json:"index"`
+	Message      AssistantMessage `json:"message"`
+	FinishReason string           `json:"finish_reason"`
+}
+
+// AssistantMessage is the generated turn. Content is null when the model
+// answered only with tool calls.
+type AssistantMessage struct {
+	Role      string     `json:"role"`
+	Content   *string    `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// EmbeddingsRequest is the public /v1/embeddings body. Input is kept raw so
+// both the string and the string-array forms can be accepted.
+type EmbeddingsRequest struct {
+	Model          string          `json:"model"`
+	Input          json.RawMessage `json:"input"`
+	EncodingFormat string          `json:"encoding_format,omitempty"`
+	Dimensions     int             `json:"dimensions,omitempty"`
+	User           string          `json:"user,omitempty"`
+}
+
+type EmbeddingsResponse struct {
+	Object string          `json:"object"`
+	Data   []EmbeddingData `json:"data"`
+	Model  string          `json:"model"`
+	Usage  EmbeddingsUsage `json:"usage"`
+}
+
+// EmbeddingData.Embedding is a []float32, or a base64 string of little-endian
+// float32s when encoding_format is "base64".
+type EmbeddingData struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding any    `json:"embedding"`
+}
+
+type EmbeddingsUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// ErrorResponse is the body of every non-2xx gateway answer, admin routes
+// included.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
 }
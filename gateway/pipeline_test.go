@@ -24,7 +24,7 @@ func (h *fakeStageHandler) Handle(*GatewayContext) StageResult {
 // TestRunStage_EmitsCentralEvent asserts that every handler dispatched via
 // RunStage produces a `gateway.stage` event on the active span, carrying
 // stage/handler/action attributes. The central dispatcher is the only place
-// that adds these events — verifying once here covers all 13 production
+// that adds these events — verifying once here covers all 14 production
 // handlers without per-handler boilerplate.
 func TestRunStage_EmitsCentralEvent(t *testing.T) {
 	rec := tracetest.NewSpanRecorder()
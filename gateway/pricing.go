@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm_gateway/internal/metrics"
+)
+
+const (
+	envPricingFile = "PRICING_FILE"
+	envPricing     = "PRICING"
+
+	// costHeader carries a request's cost in USD: a response header on JSON
+	// responses, an HTTP trailer on SSE streams (usage is only known at the end).
+	costHeader = "X-Gateway-Cost"
+
+	tokensPerPriceUnit = 1_000_000
+)
+
+// ModelPrice is what one model costs, in USD per 1M tokens. A zero
+// CachedInput bills cached prompt tokens at the Input price. Endpoints
+// overrides the price for requests served by a named pool endpoint; a zero
+// field in an override falls back to the model's price.
+type ModelPrice struct {
+	Input       float64               `json:"input"`
+	Output      float64               `json:"output"`
+	CachedInput float64               `json:"cached_input,omitempty"`
+	Endpoints   map[string]ModelPrice `json:"endpoints,omitempty"`
+}
+
+func (p ModelPrice) validate() error {
+	if p.Input < 0 || p.Output < 0 || p.CachedInput < 0 {
+		return errors.New("prices must not be negative")
+	}
+	return nil
+}
+
+// forEndpoint returns the price that applies when endpoint served the request.
+func (p ModelPrice) forEndpoint(endpoint string) ModelPrice {
+	override, ok := p.Endpoints[endpoint]
+	if !ok {
+		return p
+	}
+	if override.Input == 0 {
+		override.Input = p.Input
+	}
+	if override.Output == 0 {
+		override.Output = p.Output
+	}
+	if override.CachedInput == 0 {
+		override.CachedInput = p.CachedInput
+	}
+	return override
+}
+
+// Pricing maps a model name to its price. Requests for models without an
+// entry are served but not priced. A nil Pricing prices nothing.
+type Pricing map[string]ModelPrice
+
+// LoadPricingFromEnv reads the price list from PRICING_FILE, then from inline
+// PRICING JSON. Returns nil (no pricing) when neither is set.
+func LoadPricingFromEnv() (Pricing, error) {
+	var raw []byte
+	if path := strings.TrimSpace(os.Getenv(envPricingFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("pricing: read %s: %w", path, err)
+		}
+		raw = b
+	} else if inline := strings.TrimSpace(os.Getenv(envPricing)); inline != "" {
+		raw = []byte(inline)
+	} else {
+		return nil, nil
+	}
+
+	var pricing Pricing
+	if err := json.Unmarshal(raw, &pricing); err != nil {
+		return nil, fmt.Errorf("pricing: parse: %w", err)
+	}
+	if err := pricing.validate(); err != nil {
+		return nil, err
+	}
+	return pricing, nil
+}
+
+func (p Pricing) validate() error {
+	for model, price := range p {
+		if err := price.validate(); err != nil {
+			return fmt.Errorf("pricing: model %q: %w", model, err)
+		}
+		for endpoint, override := range price.Endpoints {
+			if err := override.validate(); err != nil {
+				return fmt.Errorf("pricing: model %q endpoint %q: %w", model, endpoint, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Cost prices one request's usage in USD. cachedPrompt is the part of prompt
+// the provider served from its prompt cache. ok is false when model has no
+// price.
+func (p Pricing) Cost(model, endpoint string, prompt, cachedPrompt, completion int) (float64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+	price = price.forEndpoint(endpoint)
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	cachedPrompt = min(cachedPrompt, prompt)
+	cost := float64(prompt-cachedPrompt)*price.Input +
+		float64(cachedPrompt)*cachedPrice +
+		float64(completion)*price.Output
+	return cost / tokensPerPriceUnit, true
+}
+
+func formatCost(usd float64) string {
+	return strconv.FormatFloat(usd, 'f', -1, 64)
+}
+
+// priceUsage turns the usage recorded in gw.Stream into a cost and sets the
+// X-Gateway-Cost header. Requests for unpriced models are left unpriced.
+func (gw *GatewayContext) priceUsage() {
+	cost, ok := gw.Services.Pricing.Cost(gw.Route.Model, gw.Stream.Endpoint,
+		gw.Stream.PromptTokens, gw.Stream.CachedPromptTokens, gw.Stream.CompletionTokens)
+	if !ok {
+		return
+	}
+	gw.Stream.CostUSD, gw.Stream.Priced = cost, true
+	gw.Response.Header.Set(costHeader, formatCost(cost))
+}
+
+// handlePricingStage prices the request once the upstream reports usage on
+// the Done chunk. It runs after stream_assemble_handler has stored the counts.
+func handlePricingStage(gw *GatewayContext) StageResult {
+	if chunk := gw.Stream.CurrentChunk; chunk != nil && chunk.Done {
+		gw.priceUsage()
+	}
+	return StageResult{Action: ActionContinue}
+}
+
+// handleUsageMetricsStage counts the tokens and cost of a completed request
+// by model and alias. Cache hits and mock responses report no usage.
+func handleUsageMetricsStage(gw *GatewayContext) StageResult {
+	if gw.Auth.Subject == "" || gw.Stream.TokenUsage <= 0 {
+		return StageResult{Action: ActionContinue}
+	}
+	model, alias := gw.Route.Model, metricsAlias(gw)
+	metrics.RequestTokens.WithLabelValues(model, alias, "prompt").Add(float64(gw.Stream.PromptTokens - gw.Stream.CachedPromptTokens))
+	metrics.RequestTokens.WithLabelValues(model, alias, "cached_prompt").Add(float64(gw.Stream.CachedPromptTokens))
+	metrics.RequestTokens.WithLabelValues(model, alias, "completion").Add(float64(gw.Stream.CompletionTokens))
+	if gw.Stream.Priced {
+		metrics.RequestCostUSD.WithLabelValues(model, alias).Add(gw.Stream.CostUSD)
+	}
+	return StageResult{Action: ActionContinue}
+}
+
+// metricsAlias is the alias label for gw's caller. API-token aliases are few
+// and issued by admins; a provider's subjects are one per end user, and
+// personal data, so they are all counted under the provider's name.
+func metricsAlias(gw *GatewayContext) string {
+	if gw.Auth.Provider != nil {
+		return gw.Auth.Provider.Name()
+	}
+	return gw.Auth.Subject
+}
+
+// handleAccessLogStage writes one info line per finished request with who
+// called, what it cost and how it ended. Prompt and answer bodies are never
+// logged; see auditDialog.
+func handleAccessLogStage(gw *GatewayContext) StageResult {
+	attrs := []any{
+		"alias", gw.Auth.Subject,
+		"model", gw.Route.Model,
+		"endpoint", gw.Stream.Endpoint,
+		"status", gw.responseStatus(),
+		"prompt_tokens", gw.Stream.PromptTokens,
+		"cached_prompt_tokens", gw.Stream.CachedPromptTokens,
+		"completion_tokens", gw.Stream.CompletionTokens,
+		"cache_hit", gw.Response.FromCache,
+		"latency_ms", time.Since(gw.StartedAt).Milliseconds(),
+	}
+	if gw.Stream.Priced {
+		attrs = append(attrs, "cost_usd", gw.Stream.CostUSD)
+	}
+	slog.InfoContext(gw.Context, "request completed", attrs...)
+	return StageResult{Action: ActionContinue}
+}
+
+// responseStatus is the status the client was sent: the direct response's,
+// or 200 once a completion started streaming.
+func (gw *GatewayContext) responseStatus() int {
+	if d := gw.Response.DirectResponse; d != nil && d.StatusCode != 0 {
+		return d.StatusCode
+	}
+	return http.StatusOK
+}
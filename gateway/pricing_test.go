@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"llm_gateway/auth"
+	"llm_gateway/completion"
+)
+
+var testPricing = Pricing{
+	"m": {
+		Input: 2, Output: 8, CachedInput: 0.5,
+		Endpoints: map[string]ModelPrice{"batch": {Output: 4}},
+	},
+}
+
+// newPricedCompletion answers with usage that costs $0.0034 under testPricing.
+func newPricedCompletion() *fakeCompletion {
+	return &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "hi"},
+		{Done: true, PromptTokens: 1000, CachedPromptTokens: 400, CompletionTokens: 500, TokenUsage: 1500, Endpoint: "batch"},
+	}}
+}
+
+func newPricingTestServer(budgets BudgetStore) *Server {
+	return NewServer(Dependencies{
+		Auth:       fakeAuth{info: &auth.TokenInfo{Alias: "tester", ID: budgetTestTokenID}},
+		Cache:      &fakeCache{},
+		Completion: newPricedCompletion(),
+		Budgets:    budgets,
+		Pricing:    testPricing,
+	})
+}
+
+func TestPricing_Cost(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		endpoint string
+		want     float64
+	}{
+		// 600 uncached at $2, 400 cached at $0.5, 500 completion at $8 per 1M.
+		{"model price", "", (600*2 + 400*0.5 + 500*8) / 1e6},
+		// The override replaces only the output price.
+		{"endpoint override", "batch", (600*2 + 400*0.5 + 500*4) / 1e6},
+	} {
+		got, ok := testPricing.Cost("m", tc.endpoint, 1000, 400, 500)
+		if !ok || math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%s: got %v (ok=%v), want %v", tc.name, got, ok, tc.want)
+		}
+	}
+	if _, ok := testPricing.Cost("unknown", "", 1, 0, 1); ok {
+		t.Error("unpriced model should not be priced")
+	}
+}
+
+func TestPricing_CachedInputDefaultsToInput(t *testing.T) {
+	p := Pricing{"m": {Input: 1, Output: 1}}
+	if got, _ := p.Cost("m", "", 1_000_000, 1_000_000, 0); got != 1 {
+		t.Fatalf("got %v, want cached tokens billed at the input price", got)
+	}
+}
+
+func TestLoadPricingFromEnv(t *testing.T) {
+	t.Setenv(envPricing, `{"m":{"input":1,"output":2,"endpoints":{"e":{"input":-1}}}}`)
+	if _, err := LoadPricingFromEnv(); err == nil {
+		t.Fatal("expected a negative endpoint price to be rejected")
+	}
+	t.Setenv(envPricing, `{"m":{"input":1,"output":2}}`)
+	p, err := LoadPricingFromEnv()
+	if err != nil || p["m"].Output != 2 {
+		t.Fatalf("got %+v, %v", p, err)
+	}
+}
+
+func TestCompletionHandler_CostHeader(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	s := newPricingTestServer(budgets)
+
+	rec := doChatRequest(t, s, budgetTestBody)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get(costHeader); got != "0.0034" {
+		t.Errorf("%s: got %q, want 0.0034", costHeader, got)
+	}
+	spend, _ := budgets.Spend(context.Background(), budgetTestTokenID)
+	if math.Abs(spend.MonthCostUSD-0.0034) > 1e-12 {
+		t.Errorf("month cost: got %v, want the request's cost recorded", spend.MonthCostUSD)
+	}
+}
+
+func TestCompletionHandler_CostTrailerOnStream(t *testing.T) {
+	s := newPricingTestServer(nil)
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	res := rec.Result()
+	if got := res.Header.Get("Trailer"); got != costHeader {
+		t.Fatalf("Trailer: got %q, want %s declared up front", got, costHeader)
+	}
+	if got := res.Trailer.Get(costHeader); got != "0.0034" {
+		t.Errorf("%s trailer: got %q, want 0.0034", costHeader, got)
+	}
+}
+
+func TestCompletionHandler_UnpricedModelHasNoCost(t *testing.T) {
+	s := newPricingTestServer(nil)
+
+	rec := doChatRequest(t, s, `{"model":"other","messages":[{"role":"user","content":"hi"}]}`)
+	if got := rec.Header().Get(costHeader); got != "" {
+		t.Errorf("%s: got %q, want none for an unpriced model", costHeader, got)
+	}
+}
+
+func TestMetricsAlias_CollapsesProviderSubjects(t *testing.T) {
+	gw := newTestGatewayContext(Dependencies{})
+	gw.Auth.Subject = "team-a"
+	if got := metricsAlias(gw); got != "team-a" {
+		t.Errorf("API token: got %q", got)
+	}
+
+	gw.Auth.Subject, gw.Auth.Provider = "oidc:alice@example.com", stubProvider{}
+	if got := metricsAlias(gw); got != "stub" {
+		t.Errorf("provider subject: got %q, want the provider name", got)
+	}
+}
@@ -3,8 +3,10 @@ package gateway
 import (
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 
+	"llm_gateway/completion"
 	"llm_gateway/internal/tracing"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -47,6 +49,18 @@ func handleRAGRetrieveStage(gw *GatewayContext) StageResult {
 		slog.WarnContext(gw.Context, "rag retrieve skipped: no collection resolvable")
 		return StageResult{Action: ActionContinue}
 	}
+	if !gw.Auth.Token.AllowsRAGCollection(collection) {
+		// Asking for a collection by name is an explicit request the token
+		// cannot make; the alias default is just skipped.
+		if collectionSourceAttr == "header" {
+			span.SetAttributes(attribute.String("result", "collection_denied"))
+			gw.Response.DirectResponse = newErrorResponse(http.StatusForbidden, errorCodeRAGCollectionDenied,
+				"This token may not query the requested RAG collection.")
+			return StageResult{Action: ActionReject, StatusCode: http.StatusForbidden, Message: "rag collection not allowed"}
+		}
+		span.SetAttributes(attribute.String("result", "skipped_collection_denied"))
+		return StageResult{Action: ActionContinue}
+	}
 	span.SetAttributes(attribute.String("collection_source", collectionSourceAttr))
 
 	slog.DebugContext(gw.Context, "rag collection resolved", "source", collectionSourceAttr)
@@ -81,9 +95,10 @@ func handleRAGRetrieveStage(gw *GatewayContext) StageResult {
 		sb.WriteString(fmt.Sprintf("[%d] (Source: %s)\n%s\n\n", i+1, chunk.Source, chunk.Content))
 	}
 	sb.WriteString("---\n\nUser Prompt：\n")
-	sb.WriteString(gw.Request.PromptText)
+	preamble := sb.String()
 
-	gw.Request.PromptText = sb.String()
+	gw.Request.PromptText = preamble + gw.Request.PromptText
+	gw.Request.Messages = injectRAGContext(gw.Request.Messages, preamble)
 
 	gw.Data["rag_chunks_count"] = len(chunks)
 	gw.Data["rag_collection"] = collection
@@ -91,3 +106,25 @@ func handleRAGRetrieveStage(gw *GatewayContext) StageResult {
 	slog.DebugContext(gw.Context, "rag chunks injected", "chunks", len(chunks), "source", collectionSourceAttr)
 	return StageResult{Action: ActionContinue}
 }
+
+// injectRAGContext prepends preamble to the last user turn so the retrieved
+// material sits next to the question it was retrieved for, while system
+// prompts and earlier turns reach the upstream untouched. On a multimodal turn
+// the preamble becomes a leading text part. The slice is copied so the
+// caller's original messages are never mutated.
+func injectRAGContext(messages []completion.Message, preamble string) []completion.Message {
+	out := append([]completion.Message(nil), messages...)
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i].Role == "user" {
+			if out[i].Parts != nil {
+				parts := make([]completion.ContentPart, 0, len(out[i].Parts)+1)
+				parts = append(parts, completion.ContentPart{Type: contentPartText, Text: preamble})
+				out[i].Parts = append(parts, out[i].Parts...)
+			} else {
+				out[i].Content = preamble + out[i].Content
+			}
+			return out
+		}
+	}
+	return append(out, completion.Message{Role: "user", Content: preamble})
+}
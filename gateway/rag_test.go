@@ -3,10 +3,14 @@ package gateway
 import (
 	"context"
 	"errors"
+	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"llm_gateway/auth"
+	"llm_gateway/completion"
 	"llm_gateway/rag"
 )
 
@@ -228,3 +232,91 @@ func TestRAGRetrieve_QueryPassedToService(t *testing.T) {
 		t.Errorf("query passed to RAG: got %q, want %q", gotQuery, "tell me about Go channels")
 	}
 }
+
+func TestRAGRetrieve_Success_InjectsIntoLastUserMessage(t *testing.T) {
+	deps := Dependencies{
+		RAG: &mockRAGService{
+			retrieveFn: func(_ context.Context, _, _ string, _ int32, _ float32) ([]rag.RetrievedChunk, error) {
+				return []rag.RetrievedChunk{{Content: "Paris is the capital of France", Source: "geo.md"}}, nil
+			},
+		},
+	}
+	gw := newTestGatewayContext(deps)
+	gw.Auth.Subject = "user"
+	original := []completion.Message{
+		{Role: "system", Content: "You are terse."},
+		{Role: "user", Content: "Hi"},
+		{Role: "assistant", Content: "Hello"},
+		{Role: "user", Content: "What is the capital of France?"},
+	}
+	gw.Request.Messages = original
+	gw.Request.PromptText = "You are terse. Hi Hello What is the capital of France?"
+
+	handleRAGRetrieveStage(gw)
+
+	msgs := gw.Request.Messages
+	if len(msgs) != 4 {
+		t.Fatalf("messages: got %d, want 4", len(msgs))
+	}
+	for i := 0; i < 3; i++ {
+		if !reflect.DeepEqual(msgs[i], original[i]) {
+			t.Errorf("message[%d] modified: got %+v, want %+v", i, msgs[i], original[i])
+		}
+	}
+	last := msgs[3].Content
+	if !strings.Contains(last, "Paris is the capital of France") || !strings.HasSuffix(last, "What is the capital of France?") {
+		t.Errorf("last user message not augmented: %q", last)
+	}
+	if original[3].Content != "What is the capital of France?" {
+		t.Error("caller's message slice must not be mutated")
+	}
+}
+
+func TestRAGRetrieve_HeaderCollectionNotAllowedRejects(t *testing.T) {
+	called := false
+	deps := Dependencies{
+		RAG: &mockRAGService{
+			retrieveFn: func(context.Context, string, string, int32, float32) ([]rag.RetrievedChunk, error) {
+				called = true
+				return nil, nil
+			},
+		},
+	}
+	gw := newTestGatewayContext(deps)
+	gw.Request.Header.Set("X-RAG-Collection", "finance")
+	gw.Auth.Subject = "alice"
+	gw.Auth.Token = auth.TokenInfo{Alias: "alice", RAGCollections: []string{"alice", "project-docs"}}
+
+	result := handleRAGRetrieveStage(gw)
+
+	if result.Action != ActionReject || result.StatusCode != http.StatusForbidden {
+		t.Fatalf("result: got %+v, want 403 reject", result)
+	}
+	if called {
+		t.Error("RAG service must not be queried for a collection the token may not read")
+	}
+}
+
+func TestRAGRetrieve_AliasCollectionNotAllowedSkips(t *testing.T) {
+	called := false
+	deps := Dependencies{
+		RAG: &mockRAGService{
+			retrieveFn: func(context.Context, string, string, int32, float32) ([]rag.RetrievedChunk, error) {
+				called = true
+				return nil, nil
+			},
+		},
+	}
+	gw := newTestGatewayContext(deps)
+	gw.Auth.Subject = "alice"
+	gw.Auth.Token = auth.TokenInfo{Alias: "alice", RAGCollections: []string{"project-docs"}}
+
+	result := handleRAGRetrieveStage(gw)
+
+	if result.Action != ActionContinue {
+		t.Errorf("action: got %q, want %q", result.Action, ActionContinue)
+	}
+	if called {
+		t.Error("RAG service must not be queried for a collection the token may not read")
+	}
+}
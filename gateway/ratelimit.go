@@ -0,0 +1,302 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"llm_gateway/internal/metrics"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	envRateLimitFile = "RATE_LIMIT_FILE"
+	envRateLimit     = "RATE_LIMIT"
+
+	rateLimitBackendLocal = "local"
+	rateLimitBackendRedis = "redis"
+
+	defaultRequestsPerMinute  = 600
+	defaultConcurrentRequests = 50
+
+	// concurrencyRetryAfter is advertised when a request is turned away for
+	// concurrency: there is no way to know when a stream will finish.
+	concurrencyRetryAfter = time.Second
+)
+
+// RateLimit caps one token alias. A zero field inherits from the defaults;
+// TokensPerMinute has no built-in default, so zero all the way down leaves
+// the alias's tokens unlimited.
+type RateLimit struct {
+	RequestsPerMinute  int `json:"requests_per_minute"`
+	ConcurrentRequests int `json:"concurrent_requests"`
+	TokensPerMinute    int `json:"tokens_per_minute"`
+}
+
+// ModelRateLimit caps one model across every alias, e.g. to stay under the
+// provider's own per-model quota.
+type ModelRateLimit struct {
+	TokensPerMinute int `json:"tokens_per_minute"`
+}
+
+// RateLimitConfig holds the limits every alias gets, per-alias overrides and
+// per-model token budgets.
+type RateLimitConfig struct {
+	Default RateLimit                 `json:"default"`
+	Aliases map[string]RateLimit      `json:"aliases"`
+	Models  map[string]ModelRateLimit `json:"models"`
+}
+
+// LoadRateLimitConfigFromEnv reads limits from RATE_LIMIT_FILE, then from
+// inline RATE_LIMIT JSON. Returns the zero config (built-in defaults for every
+// alias) when neither is set.
+func LoadRateLimitConfigFromEnv() (RateLimitConfig, error) {
+	var raw []byte
+	if path := strings.TrimSpace(os.Getenv(envRateLimitFile)); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return RateLimitConfig{}, fmt.Errorf("rate limit: read %s: %w", path, err)
+		}
+		raw = b
+	} else if inline := strings.TrimSpace(os.Getenv(envRateLimit)); inline != "" {
+		raw = []byte(inline)
+	} else {
+		return RateLimitConfig{}, nil
+	}
+
+	var cfg RateLimitConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return RateLimitConfig{}, fmt.Errorf("rate limit: parse: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return RateLimitConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c RateLimitConfig) validate() error {
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("rate limit: default: %w", err)
+	}
+	for alias, l := range c.Aliases {
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("rate limit: alias %q: %w", alias, err)
+		}
+	}
+	for model, l := range c.Models {
+		if l.TokensPerMinute < 0 {
+			return fmt.Errorf("rate limit: model %q: tokens_per_minute must not be negative", model)
+		}
+	}
+	return nil
+}
+
+func (l RateLimit) validate() error {
+	if l.RequestsPerMinute < 0 {
+		return fmt.Errorf("requests_per_minute must not be negative")
+	}
+	if l.ConcurrentRequests < 0 {
+		return fmt.Errorf("concurrent_requests must not be negative")
+	}
+	if l.TokensPerMinute < 0 {
+		return fmt.Errorf("tokens_per_minute must not be negative")
+	}
+	return nil
+}
+
+// limitFor resolves alias's limits: its override, then Default, then the
+// built-in defaults.
+func (c RateLimitConfig) limitFor(alias string) RateLimit {
+	defaults := c.Default.inherit(RateLimit{
+		RequestsPerMinute:  defaultRequestsPerMinute,
+		ConcurrentRequests: defaultConcurrentRequests,
+	})
+	return c.Aliases[alias].inherit(defaults)
+}
+
+// inherit fills zero fields of l from base.
+func (l RateLimit) inherit(base RateLimit) RateLimit {
+	if l.RequestsPerMinute == 0 {
+		l.RequestsPerMinute = base.RequestsPerMinute
+	}
+	if l.ConcurrentRequests == 0 {
+		l.ConcurrentRequests = base.ConcurrentRequests
+	}
+	if l.TokensPerMinute == 0 {
+		l.TokensPerMinute = base.TokensPerMinute
+	}
+	return l
+}
+
+// RateLimiter admits requests per token alias, so one noisy key cannot starve
+// the others. Implementations: LocalRateLimiter (per process) and
+// RedisRateLimiter (shared by every gateway replica).
+type RateLimiter interface {
+	Acquire(ctx context.Context, alias string) RateLimitDecision
+	// ChargeTokens pre-charges an estimated token cost against alias's and
+	// model's token windows; the decision's Reconcile corrects it once the
+	// real usage is known.
+	ChargeTokens(ctx context.Context, alias, model string, tokens int) TokenRateLimitDecision
+}
+
+// LocalRateLimiter keeps its counters in process, so N gateway replicas
+// together admit N times the configured rate.
+type LocalRateLimiter struct {
+	cfg RateLimitConfig
+
+	mu     sync.Mutex
+	state  map[string]*aliasLimiter
+	models map[string]*tokenWindow
+}
+
+type aliasLimiter struct {
+	limit    RateLimit
+	requests *rate.Limiter // refills RequestsPerMinute/60 per second, bursts to a full minute
+	inFlight int           // guarded by LocalRateLimiter.mu
+	tokens   *tokenWindow  // nil when the alias has no token limit
+}
+
+// NewLocalRateLimiter builds an in-process limiter from cfg; the zero config
+// applies the built-in defaults to every alias.
+func NewLocalRateLimiter(cfg RateLimitConfig) *LocalRateLimiter {
+	return &LocalRateLimiter{
+		cfg:    cfg,
+		state:  make(map[string]*aliasLimiter),
+		models: make(map[string]*tokenWindow),
+	}
+}
+
+// RateLimitDecision is the outcome of one admission check, with everything
+// needed to fill the x-ratelimit-* headers.
+type RateLimitDecision struct {
+	Backend             string // "local" or "redis"
+	Allowed             bool
+	Reason              string // "requests" or "concurrency" when rejected
+	Limit               RateLimit
+	RemainingRequests   int
+	RemainingConcurrent int
+	ResetRequests       time.Duration // until the request bucket is full again
+	RetryAfter          time.Duration // set when rejected
+
+	Release func() // set when allowed; frees the concurrency slot, safe to call more than once
+}
+
+// Acquire admits one request for alias. On success the caller must call the
+// decision's Release when the request finishes, streams included.
+func (l *LocalRateLimiter) Acquire(_ context.Context, alias string) RateLimitDecision {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.aliasState(alias)
+	d := RateLimitDecision{Backend: rateLimitBackendLocal, Limit: st.limit}
+	if st.inFlight >= st.limit.ConcurrentRequests {
+		d.Reason = "concurrency"
+		d.RetryAfter = concurrencyRetryAfter
+		st.fill(&d, now)
+		return d
+	}
+	r := st.requests.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		d.Reason = "requests"
+		d.RetryAfter = delay
+		st.fill(&d, now)
+		return d
+	}
+
+	st.inFlight++
+	d.Allowed = true
+	st.fill(&d, now)
+	var once sync.Once
+	d.Release = func() {
+		once.Do(func() {
+			l.mu.Lock()
+			st.inFlight--
+			l.mu.Unlock()
+		})
+	}
+	return d
+}
+
+// aliasState returns alias's counters, creating them on first use. Callers
+// hold l.mu.
+func (l *LocalRateLimiter) aliasState(alias string) *aliasLimiter {
+	st := l.state[alias]
+	if st == nil {
+		limit := l.cfg.limitFor(alias)
+		st = &aliasLimiter{
+			limit:    limit,
+			requests: rate.NewLimiter(rate.Limit(float64(limit.RequestsPerMinute)/60), limit.RequestsPerMinute),
+		}
+		if limit.TokensPerMinute > 0 {
+			st.tokens = &tokenWindow{limit: limit.TokensPerMinute}
+		}
+		l.state[alias] = st
+	}
+	return st
+}
+
+func (st *aliasLimiter) fill(d *RateLimitDecision, now time.Time) {
+	tokens := st.requests.TokensAt(now)
+	d.RemainingRequests = max(int(math.Floor(tokens)), 0)
+	d.RemainingConcurrent = max(st.limit.ConcurrentRequests-st.inFlight, 0)
+	if missing := float64(st.requests.Burst()) - tokens; missing > 0 {
+		d.ResetRequests = time.Duration(missing / float64(st.requests.Limit()) * float64(time.Second))
+	}
+}
+
+// setHeaders writes the OpenAI-style x-ratelimit-* headers, plus the
+// concurrency pair this gateway adds, and retry-after on rejection.
+func (d RateLimitDecision) setHeaders(h http.Header) {
+	h.Set("x-ratelimit-limit-requests", strconv.Itoa(d.Limit.RequestsPerMinute))
+	h.Set("x-ratelimit-remaining-requests", strconv.Itoa(d.RemainingRequests))
+	h.Set("x-ratelimit-reset-requests", formatRateLimitReset(d.ResetRequests))
+	h.Set("x-ratelimit-limit-concurrency", strconv.Itoa(d.Limit.ConcurrentRequests))
+	h.Set("x-ratelimit-remaining-concurrency", strconv.Itoa(d.RemainingConcurrent))
+	if !d.Allowed {
+		h.Set("retry-after", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+	}
+}
+
+// formatRateLimitReset renders a reset interval the way OpenAI does: "1s",
+// "6m0s", "120ms".
+func formatRateLimitReset(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// handleRateLimitStage applies the caller's limits. Runs after
+// auth_validate_handler so Auth.Subject is known; the concurrency slot is
+// released in finishGatewayRequest.
+func handleRateLimitStage(gw *GatewayContext) StageResult {
+	d := gw.Services.RateLimiter.Acquire(gw.Context, gw.Auth.Subject)
+	d.setHeaders(gw.Response.Header)
+	result := "allowed"
+	if !d.Allowed {
+		result = "rejected_" + d.Reason
+	}
+	metrics.RateLimitDecisions.WithLabelValues(d.Backend, result).Inc()
+	if !d.Allowed {
+		slog.WarnContext(gw.Context, "rate limit hit", "limiter", d.Reason, "retry_after_ms", d.RetryAfter.Milliseconds())
+		message := fmt.Sprintf("Rate limit reached for requests: limit %d per minute", d.Limit.RequestsPerMinute)
+		if d.Reason == "concurrency" {
+			message = fmt.Sprintf("Rate limit reached for concurrent requests: limit %d", d.Limit.ConcurrentRequests)
+		}
+		gw.Response.DirectResponse = newErrorResponse(http.StatusTooManyRequests, errorCodeRateLimitExceeded, message)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusTooManyRequests, Message: "rate limit exceeded"}
+	}
+	gw.Runtime.RateLimitRelease = d.Release
+	return StageResult{Action: ActionContinue}
+}
@@ -0,0 +1,321 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	rateLimitKeyPrefix = "ratelimit:"
+
+	// redisRateLimitTimeout bounds one admission round trip; past it the
+	// request is decided locally rather than waiting on Redis.
+	redisRateLimitTimeout = 100 * time.Millisecond
+	// redisRateLimitRetryAfter is how long Redis is skipped after a failure,
+	// so an outage costs one timeout per interval instead of one per request.
+	redisRateLimitRetryAfter = 5 * time.Second
+	// rateLimitLeaseTTL expires the concurrency lease of a replica that died
+	// mid-request. It must outlive the longest stream (the pool's default
+	// total upstream timeout is 15m).
+	rateLimitLeaseTTL = 20 * time.Minute
+)
+
+// redisAcquireScript admits one request atomically: it drops expired
+// concurrency leases, checks the concurrency cap, then runs GCRA on the
+// request rate. Time comes from Redis so replicas with skewed clocks agree.
+//
+// KEYS[1] theoretical arrival time in ms, KEYS[2] zset of in-flight leases
+// scored by expiry. ARGV: emission interval ms, burst, concurrency cap,
+// lease ttl ms, lease member.
+//
+// Returns {allowed, reason (0 none, 1 requests, 2 concurrency),
+// remaining requests, remaining concurrency, reset ms, retry-after ms}.
+var redisAcquireScript = goredis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local emission = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local concurrency = tonumber(ARGV[3])
+local lease = tonumber(ARGV[4])
+local tau = emission * burst
+
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+local inflight = redis.call('ZCARD', KEYS[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+if inflight >= concurrency then
+	return {0, 2, math.floor((now + tau - tat) / emission), 0, tat - now, 1000}
+end
+
+local new_tat = tat + emission
+local allow_at = new_tat - tau
+if allow_at > now then
+	return {0, 1, 0, concurrency - inflight, tat - now, allow_at - now}
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil(new_tat - now))
+redis.call('ZADD', KEYS[2], now + lease, ARGV[5])
+redis.call('PEXPIRE', KEYS[2], lease)
+return {1, 0, math.floor((now + tau - new_tat) / emission), concurrency - inflight - 1, new_tat - now, 0}
+`)
+
+// redisTokenScript moves one token window by a cost, GCRA-style with a
+// one-minute bucket. Unless forced, a cost that does not fit is rejected and
+// nothing changes; forced adjustments (reconciliation, refunds) always apply
+// and never refill past full.
+//
+// KEYS[1] theoretical arrival time in ms. ARGV: emission interval ms per
+// token, cost in tokens (may be negative), force (1 or 0).
+//
+// Returns {allowed, remaining tokens, reset ms, retry-after ms}.
+var redisTokenScript = goredis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local emission = tonumber(ARGV[1])
+local cost = tonumber(ARGV[2])
+local force = ARGV[3] == '1'
+local tau = 60000
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = math.max(tat + cost * emission, now)
+if not force and new_tat - now > tau then
+	return {0, math.floor((tau - (tat - now)) / emission), tat - now, new_tat - now - tau}
+end
+
+if new_tat > now then
+	redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil(new_tat - now))
+else
+	redis.call('DEL', KEYS[1])
+end
+return {1, math.floor((tau - (new_tat - now)) / emission), new_tat - now, 0}
+`)
+
+// RedisRateLimiter shares per-alias limits across every gateway replica
+// through Redis. While Redis is unreachable it decides with its in-process
+// fallback, which enforces the same limits per replica.
+type RedisRateLimiter struct {
+	client   goredis.UniversalClient
+	cfg      RateLimitConfig
+	fallback *LocalRateLimiter
+
+	skipUntil atomic.Int64 // unix nanos; Redis is not tried before this
+}
+
+// NewRedisRateLimiter builds a limiter on client. client is not pinged: a
+// Redis that is down at startup just means local decisions until it is back.
+func NewRedisRateLimiter(client goredis.UniversalClient, cfg RateLimitConfig) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client, cfg: cfg, fallback: NewLocalRateLimiter(cfg)}
+}
+
+func (l *RedisRateLimiter) Acquire(ctx context.Context, alias string) RateLimitDecision {
+	if time.Now().UnixNano() < l.skipUntil.Load() {
+		return l.fallback.Acquire(ctx, alias)
+	}
+	d, err := l.acquire(ctx, alias)
+	if err != nil {
+		if ctx.Err() == nil {
+			l.skipUntil.Store(time.Now().Add(redisRateLimitRetryAfter).UnixNano())
+		}
+		slog.WarnContext(ctx, "redis rate limiter unavailable, deciding locally", "err", err)
+		return l.fallback.Acquire(ctx, alias)
+	}
+	return d
+}
+
+func (l *RedisRateLimiter) acquire(ctx context.Context, alias string) (RateLimitDecision, error) {
+	limit := l.cfg.limitFor(alias)
+	keys := rateLimitKeys(alias)
+	member := uuid.NewString()
+	emissionMs := 60_000 / float64(limit.RequestsPerMinute)
+
+	ctx, cancel := context.WithTimeout(ctx, redisRateLimitTimeout)
+	defer cancel()
+	res, err := redisAcquireScript.Run(ctx, l.client, keys[:],
+		emissionMs, limit.RequestsPerMinute, limit.ConcurrentRequests, rateLimitLeaseTTL.Milliseconds(), member,
+	).Int64Slice()
+	if err != nil {
+		return RateLimitDecision{}, err
+	}
+	if len(res) != 6 {
+		return RateLimitDecision{}, fmt.Errorf("rate limit script returned %d values, want 6", len(res))
+	}
+
+	d := RateLimitDecision{
+		Backend:             rateLimitBackendRedis,
+		Allowed:             res[0] == 1,
+		Limit:               limit,
+		RemainingRequests:   int(max(res[2], 0)),
+		RemainingConcurrent: int(max(res[3], 0)),
+		ResetRequests:       time.Duration(max(res[4], 0)) * time.Millisecond,
+		RetryAfter:          time.Duration(max(res[5], 0)) * time.Millisecond,
+	}
+	switch res[1] {
+	case 1:
+		d.Reason = "requests"
+	case 2:
+		d.Reason = "concurrency"
+	}
+	if d.Allowed {
+		d.Release = l.releaseFunc(keys[1], member)
+	}
+	return d, nil
+}
+
+func (l *RedisRateLimiter) ChargeTokens(ctx context.Context, alias, model string, tokens int) TokenRateLimitDecision {
+	if time.Now().UnixNano() < l.skipUntil.Load() {
+		return l.fallback.ChargeTokens(ctx, alias, model, tokens)
+	}
+	d, err := l.chargeTokens(ctx, alias, model, tokens)
+	if err != nil {
+		if ctx.Err() == nil {
+			l.skipUntil.Store(time.Now().Add(redisRateLimitRetryAfter).UnixNano())
+		}
+		slog.WarnContext(ctx, "redis rate limiter unavailable, deciding locally", "err", err)
+		return l.fallback.ChargeTokens(ctx, alias, model, tokens)
+	}
+	return d
+}
+
+// redisTokenWindow is one limited window of a token charge.
+type redisTokenWindow struct {
+	scope string
+	key   string
+	limit int
+}
+
+// chargeTokens charges the alias window, then the model window. The two keys
+// live in different slots, so this is not atomic: when the model window
+// rejects, the alias charge is refunded.
+func (l *RedisRateLimiter) chargeTokens(ctx context.Context, alias, model string, tokens int) (TokenRateLimitDecision, error) {
+	limits := l.cfg.tokenLimits(alias, model)
+	keys := [2]string{rateLimitTokenKey(alias), rateLimitModelTokenKey(model)}
+	var windows []redisTokenWindow
+	for i, limit := range limits {
+		if limit > 0 {
+			windows = append(windows, redisTokenWindow{scope: tokenScopes[i], key: keys[i], limit: limit})
+		}
+	}
+	d := TokenRateLimitDecision{Backend: rateLimitBackendRedis, Allowed: true, Requested: tokens}
+	if len(windows) == 0 {
+		return d, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisRateLimitTimeout)
+	defer cancel()
+	for i, w := range windows {
+		res, err := l.moveTokens(ctx, w, tokens, false)
+		if err != nil {
+			return TokenRateLimitDecision{}, err
+		}
+		if res[0] != 1 {
+			for _, charged := range windows[:i] {
+				if _, err := l.moveTokens(ctx, charged, -tokens, true); err != nil {
+					slog.WarnContext(ctx, "redis token refund failed", "scope", charged.scope, "err", err)
+				}
+			}
+			d.Allowed = false
+			w.fill(&d, res)
+			return d, nil
+		}
+		if d.Scope == "" || int(res[1]) < d.Remaining {
+			w.fill(&d, res)
+		}
+	}
+	d.Reconcile = l.reconcileFunc(windows, tokens)
+	return d, nil
+}
+
+func (l *RedisRateLimiter) moveTokens(ctx context.Context, w redisTokenWindow, tokens int, force bool) ([]int64, error) {
+	forceArg := 0
+	if force {
+		forceArg = 1
+	}
+	res, err := redisTokenScript.Run(ctx, l.client, []string{w.key},
+		60_000/float64(w.limit), tokens, forceArg,
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 4 {
+		return nil, fmt.Errorf("token rate limit script returned %d values, want 4", len(res))
+	}
+	return res, nil
+}
+
+func (w redisTokenWindow) fill(d *TokenRateLimitDecision, res []int64) {
+	d.Scope = w.scope
+	d.Limit = w.limit
+	d.Remaining = int(max(res[1], 0))
+	d.Reset = time.Duration(max(res[2], 0)) * time.Millisecond
+	d.RetryAfter = time.Duration(max(res[3], 0)) * time.Millisecond
+}
+
+// reconcileFunc settles a charge once the real usage is known. Like release
+// it runs after the response is written; if Redis is down the estimate
+// stands.
+func (l *RedisRateLimiter) reconcileFunc(windows []redisTokenWindow, charged int) func(int) {
+	var reconciled atomic.Bool
+	return func(actual int) {
+		if reconciled.Swap(true) || actual == charged {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+		defer cancel()
+		for _, w := range windows {
+			if _, err := l.moveTokens(ctx, w, actual-charged, true); err != nil {
+				slog.Warn("redis token reconcile failed, estimate stands", "scope", w.scope, "err", err)
+			}
+		}
+	}
+}
+
+// releaseFunc drops the request's concurrency lease. It runs after the
+// response is written, so it does not use the (possibly cancelled) request
+// context; if Redis is down the lease simply expires.
+func (l *RedisRateLimiter) releaseFunc(leaseKey, member string) func() {
+	var released atomic.Bool
+	return func() {
+		if released.Swap(true) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+		defer cancel()
+		if err := l.client.ZRem(ctx, leaseKey, member).Err(); err != nil {
+			slog.Warn("redis rate limiter release failed, lease will expire", "err", err)
+		}
+	}
+}
+
+// rateLimitKeys returns the alias's GCRA and lease keys. The hash tag keeps
+// both in one slot so the script also runs on Redis Cluster.
+func rateLimitKeys(alias string) [2]string {
+	return [2]string{
+		rateLimitKeyPrefix + "{" + alias + "}:tat",
+		rateLimitKeyPrefix + "{" + alias + "}:leases",
+	}
+}
+
+// rateLimitTokenKey is the alias's token window; it shares the alias's hash
+// tag.
+func rateLimitTokenKey(alias string) string {
+	return rateLimitKeyPrefix + "{" + alias + "}:tokens"
+}
+
+// rateLimitModelTokenKey is the model's token window, shared by every alias.
+func rateLimitModelTokenKey(model string) string {
+	return rateLimitKeyPrefix + "model:{" + model + "}:tokens"
+}
@@ -0,0 +1,291 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"llm_gateway/completion"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestLocalRateLimiter_AliasesHaveSeparateBuckets(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{RequestsPerMinute: 2}})
+
+	for i := range 2 {
+		if d := l.Acquire(context.Background(), "noisy"); !d.Allowed {
+			t.Fatalf("noisy request %d rejected: %+v", i, d)
+		}
+	}
+	d := l.Acquire(context.Background(), "noisy")
+	if d.Allowed || d.Reason != "requests" || d.RetryAfter <= 0 {
+		t.Fatalf("third noisy request: got %+v, want rejected for requests with a retry-after", d)
+	}
+	if d := l.Acquire(context.Background(), "quiet"); !d.Allowed {
+		t.Fatalf("quiet alias starved by noisy one: %+v", d)
+	}
+}
+
+func TestLocalRateLimiter_ConcurrencySlotReleased(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{ConcurrentRequests: 1}})
+
+	first := l.Acquire(context.Background(), "a")
+	if !first.Allowed || first.RemainingConcurrent != 0 {
+		t.Fatalf("first: got %+v", first)
+	}
+	if d := l.Acquire(context.Background(), "a"); d.Allowed || d.Reason != "concurrency" {
+		t.Fatalf("second while first in flight: got %+v", d)
+	}
+	first.Release()
+	first.Release() // idempotent
+	if d := l.Acquire(context.Background(), "a"); !d.Allowed {
+		t.Fatalf("after release: got %+v", d)
+	}
+	if d := l.Acquire(context.Background(), "a"); d.Allowed {
+		t.Fatalf("double release freed two slots: %+v", d)
+	}
+}
+
+func TestLocalRateLimiter_AliasOverrideInheritsDefaults(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{
+		Default: RateLimit{RequestsPerMinute: 100, ConcurrentRequests: 3},
+		Aliases: map[string]RateLimit{"batch": {RequestsPerMinute: 5}},
+	})
+
+	d := l.Acquire(context.Background(), "batch")
+	if d.Limit.RequestsPerMinute != 5 || d.Limit.ConcurrentRequests != 3 {
+		t.Fatalf("limit: got %+v, want rpm from override and concurrency from default", d.Limit)
+	}
+	if d.RemainingRequests != 4 {
+		t.Errorf("remaining requests: got %d, want 4", d.RemainingRequests)
+	}
+}
+
+func TestRateLimitConfig_RejectsNegativeLimits(t *testing.T) {
+	cfg := RateLimitConfig{Aliases: map[string]RateLimit{"a": {ConcurrentRequests: -1}}}
+	if err := cfg.validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestCompletionHandler_RateLimitHeaders(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Content: "hi"}, {Done: true}}}
+	s := NewServer(Dependencies{
+		Auth:        fakeAuth{},
+		Cache:       &fakeCache{},
+		Completion:  compl,
+		RateLimiter: NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{RequestsPerMinute: 1}}),
+	})
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}]}`
+
+	rec := doChatRequest(t, s, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("x-ratelimit-limit-requests"); got != "1" {
+		t.Errorf("x-ratelimit-limit-requests: got %q", got)
+	}
+	if got := rec.Header().Get("x-ratelimit-remaining-requests"); got != "0" {
+		t.Errorf("x-ratelimit-remaining-requests: got %q", got)
+	}
+
+	rec = doChatRequest(t, s, body)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("retry-after"); got == "" || got == "0" {
+		t.Errorf("retry-after: got %q", got)
+	}
+	if got := rec.Header().Get("x-ratelimit-reset-requests"); got == "" {
+		t.Error("x-ratelimit-reset-requests missing")
+	}
+	if !strings.Contains(rec.Body.String(), `"code":"rate_limit_exceeded"`) {
+		t.Errorf("body: got %s", rec.Body.String())
+	}
+}
+
+func TestRedisRateLimiter_FallsBackWhenRedisIsDown(t *testing.T) {
+	rdb := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	l := NewRedisRateLimiter(rdb, RateLimitConfig{Default: RateLimit{RequestsPerMinute: 1}})
+
+	d := l.Acquire(context.Background(), "a")
+	if !d.Allowed || d.Backend != "local" {
+		t.Fatalf("first: got %+v, want admitted by the local fallback", d)
+	}
+	if d := l.Acquire(context.Background(), "a"); d.Allowed || d.Backend != "local" {
+		t.Fatalf("second: got %+v, want the fallback to enforce the same limit", d)
+	}
+	if l.skipUntil.Load() == 0 {
+		t.Fatal("redis not marked as down after a failed call")
+	}
+}
+
+func TestRateLimitKeys_ShareHashSlot(t *testing.T) {
+	leases := rateLimitKeys("team-a")
+	keys := append(leases[:], rateLimitTokenKey("team-a"))
+	for _, k := range keys {
+		if !strings.Contains(k, "{team-a}") {
+			t.Fatalf("key %q lacks the alias hash tag", k)
+		}
+	}
+}
+
+func TestLocalRateLimiter_TokenWindowPerAlias(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{TokensPerMinute: 100}})
+
+	d := l.ChargeTokens(context.Background(), "a", "m", 60)
+	if !d.Allowed || d.Scope != tokenScopeKey || d.Remaining != 40 {
+		t.Fatalf("first: got %+v, want 40 of 100 left", d)
+	}
+	d = l.ChargeTokens(context.Background(), "a", "m", 60)
+	if d.Allowed || d.RetryAfter <= 0 || d.tooLarge() {
+		t.Fatalf("second: got %+v, want rejected with a retry-after", d)
+	}
+	if d := l.ChargeTokens(context.Background(), "b", "m", 60); !d.Allowed {
+		t.Fatalf("other alias charged for a's tokens: %+v", d)
+	}
+	if d := l.ChargeTokens(context.Background(), "c", "m", 101); d.Allowed || !d.tooLarge() {
+		t.Fatalf("over the whole window: got %+v, want rejected as too large", d)
+	}
+}
+
+func TestLocalRateLimiter_ReconcileRefundsOverestimate(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{TokensPerMinute: 100}})
+
+	d := l.ChargeTokens(context.Background(), "a", "m", 100)
+	if !d.Allowed || d.Reconcile == nil {
+		t.Fatalf("charge: got %+v", d)
+	}
+	d.Reconcile(10)
+	d.Reconcile(0) // only the first call counts
+	if d := l.ChargeTokens(context.Background(), "a", "m", 90); !d.Allowed {
+		t.Fatalf("after reconciling to 10 used: got %+v, want 90 to fit", d)
+	}
+	if d := l.ChargeTokens(context.Background(), "a", "m", 5); d.Allowed {
+		t.Fatalf("window should be spent: got %+v", d)
+	}
+}
+
+func TestLocalRateLimiter_ModelWindowSharedAcrossAliases(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{
+		Default: RateLimit{TokensPerMinute: 1000},
+		Models:  map[string]ModelRateLimit{"big": {TokensPerMinute: 100}},
+	})
+
+	if d := l.ChargeTokens(context.Background(), "a", "big", 80); !d.Allowed || d.Scope != tokenScopeModel {
+		t.Fatalf("a: got %+v, want allowed with the model window binding", d)
+	}
+	d := l.ChargeTokens(context.Background(), "b", "big", 80)
+	if d.Allowed || d.Scope != tokenScopeModel {
+		t.Fatalf("b: got %+v, want rejected by the model window", d)
+	}
+	// The rejection must not have charged b's own window.
+	if d := l.ChargeTokens(context.Background(), "b", "other", 1000); !d.Allowed {
+		t.Fatalf("b's key window was charged by a rejected request: %+v", d)
+	}
+}
+
+func TestLocalRateLimiter_NoTokenLimitByDefault(t *testing.T) {
+	l := NewLocalRateLimiter(RateLimitConfig{})
+	d := l.ChargeTokens(context.Background(), "a", "m", 1_000_000)
+	if !d.Allowed || d.Scope != "" || d.Reconcile != nil {
+		t.Fatalf("got %+v, want allowed with no window", d)
+	}
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	maxCompletion := 50
+	req := &completion.CompletionRequest{
+		Messages: []completion.Message{
+			{Role: "system", Content: "12345678"},
+			{Role: "user", Parts: []completion.ContentPart{{Type: "text", Text: "1234"}, {Type: "image_url", ImageURL: "data:..."}}},
+		},
+		MaxTokens:           10,
+		MaxCompletionTokens: &maxCompletion,
+	}
+	if got := estimateRequestTokens(req); got != 53 {
+		t.Fatalf("estimate: got %d, want 3 prompt + 50 max_completion_tokens", got)
+	}
+	req.MaxCompletionTokens = nil
+	if got := estimateRequestTokens(req); got != 13 {
+		t.Fatalf("estimate: got %d, want 3 prompt + 10 max_tokens", got)
+	}
+}
+
+func TestCompletionHandler_TokenLimitReconcilesAgainstUsage(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "hi"},
+		{Done: true, PromptTokens: 3, CompletionTokens: 2, TokenUsage: 5},
+	}}
+	s := NewServer(Dependencies{
+		Auth:        fakeAuth{},
+		Cache:       &fakeCache{},
+		Completion:  compl,
+		RateLimiter: NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{TokensPerMinute: 100}}),
+	})
+	// One prompt token plus a 60-token completion budget: two of these only
+	// fit in 100 tokens because the first is reconciled down to 5.
+	body := `{"model":"m","max_tokens":60,"messages":[{"role":"user","content":"hi"}]}`
+
+	rec := doChatRequest(t, s, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("x-ratelimit-limit-tokens"); got != "100" {
+		t.Errorf("x-ratelimit-limit-tokens: got %q", got)
+	}
+	if got := rec.Header().Get("x-ratelimit-remaining-tokens"); got != "39" {
+		t.Errorf("x-ratelimit-remaining-tokens: got %q, want the pre-charge reflected", got)
+	}
+
+	if rec := doChatRequest(t, s, body); rec.Code != http.StatusOK {
+		t.Fatalf("second: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	// 10 tokens used; 1 + 95 no longer fits.
+	rec = doChatRequest(t, s, strings.Replace(body, `"max_tokens":60`, `"max_tokens":95`, 1))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "Rate limit reached for tokens") {
+		t.Errorf("body: got %s", rec.Body.String())
+	}
+	if got := rec.Header().Get("retry-after"); got == "" || got == "0" {
+		t.Errorf("retry-after: got %q", got)
+	}
+}
+
+func TestCompletionHandler_TokenChargeRefundedWhenUpstreamFails(t *testing.T) {
+	limiter := NewLocalRateLimiter(RateLimitConfig{Default: RateLimit{TokensPerMinute: 100}})
+	s := NewServer(Dependencies{
+		Auth:        fakeAuth{},
+		Cache:       &fakeCache{},
+		Completion:  &fakeCompletion{err: errors.New("no endpoints")},
+		RateLimiter: limiter,
+	})
+	body := `{"model":"m","max_tokens":90,"messages":[{"role":"user","content":"hi"}]}`
+
+	if rec := doChatRequest(t, s, body); rec.Code == http.StatusOK {
+		t.Fatalf("status %d, want the upstream error", rec.Code)
+	}
+	if d := limiter.ChargeTokens(context.Background(), "tester", "m", 100); !d.Allowed {
+		t.Fatalf("window not refunded after upstream failure: %+v", d)
+	}
+}
+
+func TestRedisRateLimiter_ChargeTokensFallsBackWhenRedisIsDown(t *testing.T) {
+	rdb := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	l := NewRedisRateLimiter(rdb, RateLimitConfig{Default: RateLimit{TokensPerMinute: 10}})
+
+	if d := l.ChargeTokens(context.Background(), "a", "m", 10); !d.Allowed || d.Backend != "local" {
+		t.Fatalf("first: got %+v, want admitted by the local fallback", d)
+	}
+	if d := l.ChargeTokens(context.Background(), "a", "m", 1); d.Allowed || d.Backend != "local" {
+		t.Fatalf("second: got %+v, want the fallback to enforce the same window", d)
+	}
+}
@@ -0,0 +1,272 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"llm_gateway/completion"
+	"llm_gateway/internal/metrics"
+)
+
+const (
+	tokenScopeKey   = "key"
+	tokenScopeModel = "model"
+
+	// bytesPerToken is the prompt-size heuristic used before the upstream
+	// reports real usage; about right for English text and BPE tokenizers.
+	bytesPerToken = 4
+)
+
+// tokenScopes names the windows of a tokenLimits result, in order.
+var tokenScopes = [2]string{tokenScopeKey, tokenScopeModel}
+
+// tokenLimits returns the tokens-per-minute budgets for alias and for model;
+// zero means that window is unlimited.
+func (c RateLimitConfig) tokenLimits(alias, model string) [2]int {
+	return [2]int{c.limitFor(alias).TokensPerMinute, c.Models[model].TokensPerMinute}
+}
+
+// TokenRateLimitDecision is the outcome of pre-charging a request's tokens.
+// The limit fields describe the binding window: the one that rejected, or on
+// success the one with the fewest tokens left.
+type TokenRateLimitDecision struct {
+	Backend    string // "local" or "redis"
+	Allowed    bool
+	Scope      string // "key" or "model"; empty when neither window is limited
+	Limit      int    // tokens per minute; 0 when neither window is limited
+	Requested  int    // the estimate that was charged
+	Remaining  int
+	Reset      time.Duration // until the window is full again
+	RetryAfter time.Duration // set when rejected
+
+	// Reconcile replaces the estimate with the real usage. Set when allowed
+	// and a window is limited; only the first call counts.
+	Reconcile func(actual int)
+}
+
+// tooLarge reports whether the request could never fit the window, however
+// long the caller waits.
+func (d TokenRateLimitDecision) tooLarge() bool {
+	return d.Requested > d.Limit
+}
+
+// setHeaders writes the OpenAI-style token headers, and retry-after on
+// rejection.
+func (d TokenRateLimitDecision) setHeaders(h http.Header) {
+	h.Set("x-ratelimit-limit-tokens", strconv.Itoa(d.Limit))
+	h.Set("x-ratelimit-remaining-tokens", strconv.Itoa(d.Remaining))
+	h.Set("x-ratelimit-reset-tokens", formatRateLimitReset(d.Reset))
+	if !d.Allowed {
+		h.Set("retry-after", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+	}
+}
+
+// tokenWindow is a GCRA bucket with a per-request cost: it holds a minute's
+// worth of tokens and refills continuously. Reconciling an underestimate may
+// push it past empty; the debt then delays the alias's next requests.
+type tokenWindow struct {
+	limit int       // tokens per minute
+	tat   time.Time // theoretical arrival time: when the bucket is full again
+}
+
+func (w *tokenWindow) emission() time.Duration {
+	return time.Minute / time.Duration(w.limit)
+}
+
+// backlog is how far the bucket is from full.
+func (w *tokenWindow) backlog(now time.Time) time.Duration {
+	return max(w.tat.Sub(now), 0)
+}
+
+// admits reports whether tokens fit now and, if not, how long until they
+// would.
+func (w *tokenWindow) admits(now time.Time, tokens int) (time.Duration, bool) {
+	over := w.backlog(now) + time.Duration(tokens)*w.emission() - time.Minute
+	return over, over <= 0
+}
+
+// charge takes tokens from the bucket unconditionally; a negative count
+// refunds, never past full.
+func (w *tokenWindow) charge(now time.Time, tokens int) {
+	w.tat = now.Add(max(w.backlog(now)+time.Duration(tokens)*w.emission(), 0))
+}
+
+func (w *tokenWindow) fill(d *TokenRateLimitDecision, scope string, now time.Time) {
+	d.Scope = scope
+	d.Limit = w.limit
+	d.Remaining = max(int((time.Minute-w.backlog(now))/w.emission()), 0)
+	d.Reset = w.backlog(now)
+}
+
+// ChargeTokens pre-charges tokens against alias's and model's windows. Both
+// must admit the request or neither is charged.
+func (l *LocalRateLimiter) ChargeTokens(_ context.Context, alias, model string, tokens int) TokenRateLimitDecision {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	windows := [2]*tokenWindow{l.aliasState(alias).tokens, l.modelWindow(model)}
+	d := TokenRateLimitDecision{Backend: rateLimitBackendLocal, Requested: tokens}
+	for i, w := range windows {
+		if w == nil {
+			continue
+		}
+		if retryAfter, ok := w.admits(now, tokens); !ok {
+			w.fill(&d, tokenScopes[i], now)
+			d.RetryAfter = retryAfter
+			return d
+		}
+	}
+
+	d.Allowed = true
+	for i, w := range windows {
+		if w == nil {
+			continue
+		}
+		w.charge(now, tokens)
+		var candidate TokenRateLimitDecision
+		w.fill(&candidate, tokenScopes[i], now)
+		if d.Scope == "" || candidate.Remaining < d.Remaining {
+			d.Scope, d.Limit, d.Remaining, d.Reset = candidate.Scope, candidate.Limit, candidate.Remaining, candidate.Reset
+		}
+	}
+	if d.Scope == "" {
+		return d
+	}
+	var once sync.Once
+	d.Reconcile = func(actual int) {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			now := time.Now()
+			for _, w := range windows {
+				if w != nil {
+					w.charge(now, actual-tokens)
+				}
+			}
+		})
+	}
+	return d
+}
+
+// modelWindow returns model's shared token window, or nil when the model has
+// no token limit. Only configured models get state, so arbitrary model names
+// from clients cannot grow the map. Callers hold l.mu.
+func (l *LocalRateLimiter) modelWindow(model string) *tokenWindow {
+	limit := l.cfg.Models[model].TokensPerMinute
+	if limit <= 0 {
+		return nil
+	}
+	w := l.models[model]
+	if w == nil {
+		w = &tokenWindow{limit: limit}
+		l.models[model] = w
+	}
+	return w
+}
+
+// estimateRequestTokens approximates what req will cost before the upstream
+// reports real usage: the prompt text at bytesPerToken, plus the completion
+// budget the client asked for. Image and audio parts are not counted;
+// reconciliation corrects the estimate either way.
+func estimateRequestTokens(req *completion.CompletionRequest) int {
+	size := 0
+	for _, m := range req.Messages {
+		size += len(m.Content)
+		for _, p := range m.Parts {
+			size += len(p.Text)
+		}
+		for _, tc := range m.ToolCalls {
+			size += len(tc.Name) + len(tc.Arguments)
+		}
+	}
+	for _, t := range req.Tools {
+		size += len(t.Name) + len(t.Description) + len(t.Parameters)
+	}
+	tokens := (size + bytesPerToken - 1) / bytesPerToken
+	if req.MaxCompletionTokens != nil {
+		return tokens + *req.MaxCompletionTokens
+	}
+	return tokens + req.MaxTokens
+}
+
+// handleTokenLimitStage pre-charges the request's estimated tokens against
+// the caller's and the model's token windows. It runs last in before_upstream
+// so cache hits and mock responses, which cost no upstream tokens, are never
+// charged; token_reconcile_handler settles the charge.
+func handleTokenLimitStage(gw *GatewayContext) StageResult {
+	return chargeTokenLimit(gw, estimateRequestTokens(gw.Upstream.Request))
+}
+
+// handleEmbeddingsTokenLimitStage is token_limit_handler for /v1/embeddings:
+// the estimate is the input text at bytesPerToken.
+func handleEmbeddingsTokenLimitStage(gw *GatewayContext) StageResult {
+	return chargeTokenLimit(gw, estimateEmbeddingTokens(gw.Request.Inputs))
+}
+
+func estimateEmbeddingTokens(inputs []string) int {
+	size := 0
+	for _, in := range inputs {
+		size += len(in)
+	}
+	return (size + bytesPerToken - 1) / bytesPerToken
+}
+
+// chargeTokenLimit pre-charges estimate against the caller's and the model's
+// token windows, rejecting with 429 when either is short.
+func chargeTokenLimit(gw *GatewayContext, estimate int) StageResult {
+	d := gw.Services.RateLimiter.ChargeTokens(gw.Context, gw.Auth.Subject, gw.Route.Model, estimate)
+	if d.Scope == "" {
+		return StageResult{Action: ActionContinue}
+	}
+	d.setHeaders(gw.Response.Header)
+	result := "allowed"
+	if !d.Allowed {
+		result = "rejected_" + d.Scope
+	}
+	metrics.TokenRateLimitDecisions.WithLabelValues(d.Backend, result).Inc()
+	if !d.Allowed {
+		slog.WarnContext(gw.Context, "token rate limit hit",
+			"scope", d.Scope,
+			"requested_tokens", d.Requested,
+			"retry_after_ms", d.RetryAfter.Milliseconds(),
+		)
+		subject := "tokens"
+		if d.Scope == tokenScopeModel {
+			subject = gw.Route.Model + " tokens"
+		}
+		message := fmt.Sprintf("Rate limit reached for %s: limit %d per minute, requested %d", subject, d.Limit, d.Requested)
+		if d.tooLarge() {
+			message = fmt.Sprintf("Request too large for %s per minute: limit %d, requested %d", subject, d.Limit, d.Requested)
+		}
+		gw.Response.DirectResponse = newErrorResponse(http.StatusTooManyRequests, errorCodeRateLimitExceeded, message)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusTooManyRequests, Message: "token rate limit exceeded"}
+	}
+	gw.Runtime.TokenReconcile = d.Reconcile
+	return StageResult{Action: ActionContinue}
+}
+
+// handleTokenReconcileStage replaces the pre-charged estimate with the usage
+// the upstream reported. A request that never got a stream is refunded in
+// full; one whose usage is unknown (stream cut off, provider sent no counts)
+// keeps the estimate, since the upstream still spent tokens on it.
+func handleTokenReconcileStage(gw *GatewayContext) StageResult {
+	reconcile := gw.Runtime.TokenReconcile
+	if reconcile == nil {
+		return StageResult{Action: ActionContinue}
+	}
+	gw.Runtime.TokenReconcile = nil
+	switch {
+	case gw.Stream.TokenUsage > 0:
+		reconcile(gw.Stream.TokenUsage)
+	case !gw.Upstream.Started:
+		reconcile(0)
+	}
+	return StageResult{Action: ActionContinue}
+}
@@ -13,9 +13,11 @@ import (
 )
 
 type Server struct {
-	services     Dependencies
-	pipeline     *Pipeline
-	ingestWorker *ingestWorkerPool // nil when RAG service is disabled
+	services           Dependencies
+	pipeline           *Pipeline
+	modelsPipeline     *Pipeline
+	embeddingsPipeline *Pipeline
+	ingestWorker       *ingestWorkerPool // nil when RAG service is disabled
 }
 
 const (
@@ -24,9 +26,20 @@ const (
 )
 
 func NewServer(services Dependencies) *Server {
+	if services.RateLimiter == nil {
+		services.RateLimiter = NewLocalRateLimiter(RateLimitConfig{})
+	}
+	if services.Budgets == nil {
+		services.Budgets = NewMemoryBudgetStore()
+	}
+	if services.Usage == nil {
+		services.Usage = NewMemoryUsageLedger()
+	}
 	s := &Server{
-		services: services,
-		pipeline: defaultGatewayPipeline(),
+		services:           services,
+		pipeline:           defaultGatewayPipeline(),
+		modelsPipeline:     defaultModelsPipeline(),
+		embeddingsPipeline: defaultEmbeddingsPipeline(),
 	}
 	if services.RAG != nil {
 		s.ingestWorker = newIngestWorkerPool(services.RAG, ingestWorkerBufferSize, ingestWorkerCount)
@@ -43,15 +56,18 @@ func (s *Server) Shutdown() {
 
 func (s *Server) RegisterPublicRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/v1/chat/completions", s.CompletionHandler)
+	mux.HandleFunc("/v1/models", s.ModelsHandler)
+	mux.HandleFunc("/v1/models/{id...}", s.ModelsHandler)
+	mux.HandleFunc("/v1/embeddings", s.EmbeddingsHandler)
 }
 
 func (s *Server) CompletionHandler(w http.ResponseWriter, r *http.Request) {
 	slog.DebugContext(r.Context(), "request received", "method", r.Method, "path", r.URL.Path)
 
 	gw := newGatewayContext(w, r, s.services)
-	defer s.finishGatewayRequest(gw)
+	defer s.finishGatewayRequest(gw, s.pipeline)
 
-	if result, terminal := s.runPreUpstreamStages(gw); terminal {
+	if result, terminal := s.runPreUpstreamStages(gw, s.pipeline); terminal {
 		s.writeTerminalStageResponse(gw, result)
 		return
 	}
@@ -60,25 +76,34 @@ func (s *Server) CompletionHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		slog.ErrorContext(gw.Context, "completion stream failed", "err", err)
 		gw.Upstream.Error = err
-		gw.Response.DirectResponse = newJSONDirectResponse(
-			http.StatusBadGateway,
-			map[string]string{"error": "Failed to get stream"},
-		)
+		gw.Response.DirectResponse = upstreamErrorResponse(err)
 		s.writeTerminalStageResponse(gw, StageResult{
 			Action:     ActionReject,
-			StatusCode: http.StatusBadGateway,
+			StatusCode: gw.Response.DirectResponse.StatusCode,
 			Message:    "Failed to get stream",
 			Err:        err,
 		})
 		return
 	}
 
+	if !gw.wantsStream() {
+		if err := s.bufferUpstreamResponse(gw, chunks); err != nil {
+			slog.ErrorContext(gw.Context, "upstream stream failed", "err", err)
+			gw.Upstream.Error = err
+			gw.Response.DirectResponse = upstreamErrorResponse(err)
+			s.writeTerminalStageResponse(gw, StageResult{
+				Action:     ActionReject,
+				StatusCode: gw.Response.DirectResponse.StatusCode,
+				Message:    "Upstream stream failed",
+				Err:        err,
+			})
+		}
+		return
+	}
+
 	if err := s.streamUpstreamResponse(gw, chunks); err != nil {
 		if !gw.Response.StreamStarted {
-			gw.Response.DirectResponse = newJSONDirectResponse(
-				http.StatusInternalServerError,
-				map[string]string{"error": err.Error()},
-			)
+			gw.Response.DirectResponse = newErrorResponse(http.StatusInternalServerError, "", err.Error())
 			s.writeTerminalStageResponse(gw, StageResult{
 				Action:     ActionReject,
 				StatusCode: http.StatusInternalServerError,
@@ -89,14 +114,14 @@ func (s *Server) CompletionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) runPreUpstreamStages(gw *GatewayContext) (StageResult, bool) {
+func (s *Server) runPreUpstreamStages(gw *GatewayContext, p *Pipeline) (StageResult, bool) {
 	stages := []StageName{
 		StageRequestReceived,
 		StageRequestDecoded,
 		StageBeforeUpstream,
 	}
 	for _, stage := range stages {
-		result, terminal := s.pipeline.RunStage(stage, gw)
+		result, terminal := p.RunStage(stage, gw)
 		if terminal {
 			return result, true
 		}
@@ -104,11 +129,11 @@ func (s *Server) runPreUpstreamStages(gw *GatewayContext) (StageResult, bool) {
 	return StageResult{Action: ActionContinue}, false
 }
 
-func (s *Server) finishGatewayRequest(gw *GatewayContext) {
-	s.pipeline.RunStage(StageResponseComplete, gw)
-	if gw.Runtime.ParallelSlotAcquired {
-		<-parallelSemaphore
-		gw.Runtime.ParallelSlotAcquired = false
+func (s *Server) finishGatewayRequest(gw *GatewayContext, p *Pipeline) {
+	p.RunStage(StageResponseComplete, gw)
+	if gw.Runtime.RateLimitRelease != nil {
+		gw.Runtime.RateLimitRelease()
+		gw.Runtime.RateLimitRelease = nil
 	}
 }
 
@@ -122,7 +147,7 @@ func (s *Server) writeTerminalStageResponse(gw *GatewayContext, result StageResu
 		if message == "" {
 			message = http.StatusText(statusCode)
 		}
-		gw.Response.DirectResponse = newJSONDirectResponse(statusCode, map[string]string{"error": message})
+		gw.Response.DirectResponse = newErrorResponse(statusCode, "", message)
 	}
 
 	s.writeDirectResponse(gw)
@@ -139,9 +164,17 @@ func (s *Server) writeDirectResponse(gw *GatewayContext) {
 
 	switch direct.Kind {
 	case DirectResponseCachedStream:
-		returnCachedAnswer(gw.Response.Writer, direct.CachedAnswer, direct.Model)
+		if !gw.wantsStream() {
+			writeChatCompletion(gw, direct.Model, newTextChoice(direct.CachedAnswer), Usage{})
+			return
+		}
+		writeCachedStream(gw, direct.CachedAnswer, direct.Model)
 	case DirectResponseMockStream:
-		returnMockAnswer(gw.Response.Writer)
+		if !gw.wantsStream() {
+			writeChatCompletion(gw, direct.Model, newTextChoice(strings.Repeat(mockDelta, mockChunks)), Usage{})
+			return
+		}
+		writeMockStream(gw, direct.Model)
 	default:
 		statusCode := direct.StatusCode
 		if statusCode == 0 {
@@ -155,11 +188,13 @@ func (s *Server) writeDirectResponse(gw *GatewayContext) {
 }
 
 func (s *Server) streamUpstreamResponse(gw *GatewayContext, chunks <-chan *completion.CompletionChunk) error {
-	setSSEHeaders(gw.Response.Writer, gw.Response.Header)
-
-	flusher, ok := gw.Response.Writer.(http.Flusher)
-	if !ok {
-		return fmt.Errorf("streaming not supported")
+	// The cost is only known after the last chunk, so it goes out as a trailer.
+	if gw.Services.Pricing != nil {
+		gw.Response.Writer.Header().Set("Trailer", costHeader)
+	}
+	cw, err := newChunkWriter(gw, gw.Route.Model)
+	if err != nil {
+		return err
 	}
 
 	gw.Response.StreamStarted = true
@@ -182,70 +217,32 @@ func (s *Server) streamUpstreamResponse(gw *GatewayContext, chunks <-chan *compl
 			break
 		}
 
-		if chunk.Content != "" {
-			response := ChatStreamResponse{
-				Choices: []struct {
-					Delta struct {
-						Content string `json:"content"`
-					} `json:"delta"`
-					FinishReason string `json:"finish_reason"`
-				}{
-					{
-						Delta: struct {
-							Content string `json:"content"`
-						}{Content: chunk.Content},
-						FinishReason: "",
-					},
-				},
-				Usage: nil,
-			}
-
-			jsonBytes, _ := json.Marshal(response)
-			_, _ = fmt.Fprintf(gw.Response.Writer, "data: %s\n\n", string(jsonBytes))
-			flusher.Flush()
+		if chunk.Content != "" || len(chunk.ToolCalls) > 0 {
+			cw.delta(ChatStreamDelta{
+				Content:   chunk.Content,
+				ToolCalls: toToolCallDeltas(chunk.ToolCalls),
+			})
 		}
 
 		if chunk.Done {
 			gw.Upstream.Finished = true
 
-			// OpenAI-style usage chunk: empty choices + populated usage field.
-			// Emitted before the finish chunk so clients that stop at finish_reason
-			// still see usage. Skipped when upstream did not return any counts
-			// (e.g. providers that ignore stream_options.include_usage).
+			// Usage chunk is emitted before the finish chunk so clients that
+			// stop at finish_reason still see usage. Skipped when upstream did
+			// not return any counts (e.g. providers that ignore
+			// stream_options.include_usage).
 			if chunk.PromptTokens > 0 || chunk.CompletionTokens > 0 || chunk.TokenUsage > 0 {
-				usageResponse := map[string]interface{}{
-					"id":      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
-					"object":  "chat.completion.chunk",
-					"created": time.Now().Unix(),
-					"model":   gw.Route.Model,
-					"choices": []interface{}{},
-					"usage": map[string]int{
-						"prompt_tokens":     chunk.PromptTokens,
-						"completion_tokens": chunk.CompletionTokens,
-						"total_tokens":      chunk.TokenUsage,
-					},
-				}
-				usageJSON, _ := json.Marshal(usageResponse)
-				_, _ = fmt.Fprintf(gw.Response.Writer, "data: %s\n\n", string(usageJSON))
+				cw.usage(Usage{
+					PromptTokens:     chunk.PromptTokens,
+					CompletionTokens: chunk.CompletionTokens,
+					TotalTokens:      chunk.TokenUsage,
+				})
 			}
-
-			finishResponse := map[string]interface{}{
-				"id":      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
-				"object":  "chat.completion.chunk",
-				"created": time.Now().Unix(),
-				"model":   gw.Route.Model,
-				"choices": []map[string]interface{}{
-					{
-						"index":         0,
-						"delta":         map[string]string{},
-						"finish_reason": "stop",
-					},
-				},
+			cw.finish(gw.finishReason())
+			cw.done()
+			if cost := gw.Response.Header.Get(costHeader); cost != "" {
+				gw.Response.Writer.Header().Set(costHeader, cost)
 			}
-			finishJSON, _ := json.Marshal(finishResponse)
-			_, _ = fmt.Fprintf(gw.Response.Writer, "data: %s\n\n", string(finishJSON))
-			_, _ = fmt.Fprintf(gw.Response.Writer, "data: [DONE]\n\n")
-			flusher.Flush()
 			return nil
 		}
 	}
@@ -253,6 +250,81 @@ func (s *Server) streamUpstreamResponse(gw *GatewayContext, chunks <-chan *compl
 	return gw.Upstream.Error
 }
 
+// bufferUpstreamResponse drains the upstream stream without writing anything
+// to the client, then answers with a single chat.completion object. Chunks
+// still pass through StageStreamChunk so the cache writeback and audit stages
+// see the same assembled answer as on the SSE path. Any error before the
+// final chunk is returned untouched so the caller can still send a JSON error.
+func (s *Server) bufferUpstreamResponse(gw *GatewayContext, chunks <-chan *completion.CompletionChunk) error {
+	gw.Upstream.Started = true
+
+	for chunk := range chunks {
+		select {
+		case <-gw.Context.Done():
+			slog.DebugContext(gw.Context, "client disconnected, stopping buffered read")
+			gw.Upstream.Error = gw.Context.Err()
+			return gw.Context.Err()
+		default:
+		}
+
+		gw.Stream.CurrentChunk = chunk
+		gw.Stream.ChunkIndex++
+		_, _ = s.pipeline.RunStage(StageStreamChunk, gw)
+
+		if chunk.Error != nil {
+			return chunk.Error
+		}
+
+		if chunk.Done {
+			gw.Upstream.Finished = true
+			writeChatCompletion(gw, gw.Route.Model, gw.assembledChoice(), Usage{
+				PromptTokens:     chunk.PromptTokens,
+				CompletionTokens: chunk.CompletionTokens,
+				TotalTokens:      chunk.TokenUsage,
+			})
+			return nil
+		}
+	}
+
+	if gw.Upstream.Error != nil {
+		return gw.Upstream.Error
+	}
+	return fmt.Errorf("upstream stream closed before completion")
+}
+
+// writeChatCompletion writes a complete non-streaming chat.completion body.
+// Headers already on the writer (trace id) are kept and the stage headers
+// (CORS, rate limits) are merged in.
+func writeChatCompletion(gw *GatewayContext, model string, choice ChatCompletionChoice, usage Usage) {
+	w := gw.Response.Writer
+	mergeHeaders(w.Header(), gw.Response.Header)
+	response := ChatCompletionResponse{
+		ID:      gw.completionID(),
+		Object:  "chat.completion",
+		Created: gw.StartedAt.Unix(),
+		Model:   model,
+		Choices: []ChatCompletionChoice{choice},
+		Usage:   usage,
+	}
+	body, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// newTextChoice wraps a plain-text answer (cached or mock) as choice 0.
+func newTextChoice(content string) ChatCompletionChoice {
+	return ChatCompletionChoice{
+		Index:        0,
+		Message:      AssistantMessage{Role: "assistant", Content: &content},
+		FinishReason: finishReasonStop,
+	}
+}
+
+func newChatCompletionID() string {
+	return fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+}
+
 // auditDialog records metadata about a completed dialog turn. We deliberately
 // do NOT log the prompt or answer body — both can contain sensitive user data,
 // and the SENSITIVE_FIELD_RULES in internal/logging forbid it. If you need to
@@ -269,84 +341,45 @@ func auditDialog(ctx context.Context, userText, answerText string) {
 	)
 }
 
-func returnCachedAnswer(w http.ResponseWriter, cachedAnswer string, model string) {
-	setSSEHeaders(w, nil)
-
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+// writeCachedStream replays a cached answer as a stream of small deltas.
+// Only complete answers are cached, so the finish reason is always "stop".
+func writeCachedStream(gw *GatewayContext, cachedAnswer string, model string) {
+	cw, err := newChunkWriter(gw, model)
+	if err != nil {
+		writeErrorJSON(gw.Response.Writer, http.StatusInternalServerError, "", "Streaming not supported")
 		return
 	}
 
-	chatID := fmt.Sprintf("chatcmpl-cached-%d", time.Now().UnixNano())
-	createdTime := time.Now().Unix()
 	chunkSize := 20
 	runes := []rune(cachedAnswer)
-
 	for i := 0; i < len(runes); i += chunkSize {
 		end := i + chunkSize
 		if end > len(runes) {
 			end = len(runes)
 		}
-		chunk := string(runes[i:end])
-
-		response := ChatStreamResponse{
-			Choices: []struct {
-				Delta struct {
-					Content string `json:"content"`
-				} `json:"delta"`
-				FinishReason string `json:"finish_reason"`
-			}{
-				{
-					Delta: struct {
-						Content string `json:"content"`
-					}{Content: chunk},
-					FinishReason: "",
-				},
-			},
-			Usage: nil,
-		}
-
-		jsonBytes, _ := json.Marshal(response)
-		_, _ = fmt.Fprintf(w, "data: %s\n\n", string(jsonBytes))
-		flusher.Flush()
-	}
-
-	finishResponse := map[string]interface{}{
-		"id":      chatID,
-		"object":  "chat.completion.chunk",
-		"created": createdTime,
-		"model":   model,
-		"choices": []map[string]interface{}{
-			{
-				"index":         0,
-				"delta":         map[string]string{},
-				"finish_reason": "stop",
-			},
-		},
+		cw.delta(ChatStreamDelta{Content: string(runes[i:end])})
 	}
-	finishJSON, _ := json.Marshal(finishResponse)
-	_, _ = fmt.Fprintf(w, "data: %s\n\n", string(finishJSON))
-	_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
-	flusher.Flush()
+	cw.finish(finishReasonStop)
+	cw.done()
 }
 
-func returnMockAnswer(w http.ResponseWriter) {
-	setSSEHeaders(w, nil)
+const (
+	mockDelta  = "mock"
+	mockChunks = 10
+)
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+func writeMockStream(gw *GatewayContext, model string) {
+	cw, err := newChunkWriter(gw, model)
+	if err != nil {
+		writeErrorJSON(gw.Response.Writer, http.StatusInternalServerError, "", "Streaming not supported")
 		return
 	}
 
-	mockResponse := []byte("data: {\"choices\":[{\"delta\":{\"content\":\"mock\"}}]}\n\n")
-	for i := 0; i < 10; i++ {
-		_, _ = w.Write(mockResponse)
-		flusher.Flush()
+	for i := 0; i < mockChunks; i++ {
+		cw.delta(ChatStreamDelta{Content: mockDelta})
 	}
-	_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
-	flusher.Flush()
+	cw.finish(finishReasonStop)
+	cw.done()
 }
 
 func setSSEHeaders(w http.ResponseWriter, base http.Header) {
@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+	"llm_gateway/auth/redis"
+	"llm_gateway/cache"
+	"llm_gateway/completion"
+)
+
+// fakeAuth accepts every token as alias "tester", or as info when set.
+type fakeAuth struct {
+	info *auth.TokenInfo
+}
+
+func (fakeAuth) Create(context.Context, auth.TokenInfo) (string, error) { return "", nil }
+func (f fakeAuth) Get(context.Context, string) (bool, auth.TokenInfo, error) {
+	if f.info != nil {
+		return true, *f.info, nil
+	}
+	return true, auth.TokenInfo{Alias: "tester"}, nil
+}
+func (fakeAuth) List(context.Context, auth.ListFilter) (auth.TokenPage, error) {
+	return auth.TokenPage{}, nil
+}
+func (fakeAuth) Rotate(context.Context, string, time.Duration) (string, auth.TokenInfo, error) {
+	return "", auth.TokenInfo{}, nil
+}
+func (fakeAuth) Revoke(context.Context, string) error { return nil }
+func (fakeAuth) Lookup(context.Context, string) (auth.TokenInfo, error) {
+	return auth.TokenInfo{}, auth.ErrTokenNotFound
+}
+func (fakeAuth) SetBudget(context.Context, string, *auth.Budget) (auth.TokenInfo, error) {
+	return auth.TokenInfo{}, auth.ErrTokenNotFound
+}
+func (fakeAuth) RevokeByAlias(context.Context, string) (int, error) { return 0, nil }
+func (fakeAuth) Delete(context.Context, string) error               { return nil }
+
+type fakeCache struct {
+	answer string
+	hit    bool
+	sets   []cache.Task
+}
+
+func (c *fakeCache) Get(context.Context, string, string) (string, bool, error) {
+	return c.answer, c.hit, nil
+}
+func (c *fakeCache) Set(_ context.Context, t cache.Task) error {
+	c.sets = append(c.sets, t)
+	return nil
+}
+
+// fakeCompletion replays a fixed chunk sequence and records the last request.
+type fakeCompletion struct {
+	chunks []*completion.CompletionChunk
+	err    error
+	got    *completion.CompletionRequest
+}
+
+func (f *fakeCompletion) GetStream(_ context.Context, req *completion.CompletionRequest) (<-chan *completion.CompletionChunk, error) {
+	f.got = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan *completion.CompletionChunk, len(f.chunks))
+	for _, c := range f.chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch, nil
+}
+
+func newTestToken(t *testing.T) string {
+	t.Helper()
+	token, err := redis.GenerateToken(tokenPrefix, tokenEntropyLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func doChatRequest(t *testing.T, s *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t))
+	rec := httptest.NewRecorder()
+	s.CompletionHandler(rec, req)
+	return rec
+}
+
+func TestCompletionHandler_NonStreamingReturnsChatCompletion(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "Hello"},
+		{Content: " world"},
+		{Done: true, PromptTokens: 3, CompletionTokens: 2, TokenUsage: 5},
+	}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":false,"messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("content-type: got %q", ct)
+	}
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body.String())
+	}
+	if resp.Object != "chat.completion" || resp.Model != "m" {
+		t.Errorf("object/model: got %q/%q", resp.Object, resp.Model)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content == nil || *resp.Choices[0].Message.Content != "Hello world" || resp.Choices[0].Message.Role != "assistant" {
+		t.Errorf("choices: got %+v", resp.Choices)
+	}
+	if resp.Usage != (Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}) {
+		t.Errorf("usage: got %+v", resp.Usage)
+	}
+}
+
+func TestCompletionHandler_NonStreamingCacheHit(t *testing.T) {
+	s := NewServer(Dependencies{
+		Auth:       fakeAuth{},
+		Cache:      &fakeCache{answer: "cached answer", hit: true},
+		Completion: &fakeCompletion{},
+	})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body.String())
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content == nil || *resp.Choices[0].Message.Content != "cached answer" {
+		t.Errorf("choices: got %+v", resp.Choices)
+	}
+}
+
+func TestCompletionHandler_NonStreamingMidStreamErrorIs502(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "partial"},
+		{Error: context.DeadlineExceeded, Done: true},
+	}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":false,"messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status: got %d, want 502", rec.Code)
+	}
+}
+
+func TestCompletionHandler_StreamingStillUsesSSE(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "Hello"},
+		{Done: true},
+	}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content-type: got %q", ct)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("stream not terminated with [DONE]: %q", rec.Body.String())
+	}
+}
+
+func TestCompletionHandler_ForwardsSamplingParameters(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Done: true}}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}],
+		"temperature":0,"top_p":0.9,"stop":"END","seed":7,"presence_penalty":0.5,
+		"logit_bias":{"50256":-100},"user":"u1","response_format":{"type":"json_object"},
+		"max_completion_tokens":64,"parallel_tool_calls":false}`)
+
+	got := compl.got
+	if got == nil {
+		t.Fatal("upstream not called")
+	}
+	if got.Temperature == nil || *got.Temperature != 0 {
+		t.Errorf("temperature 0 must be forwarded as set, got %v", got.Temperature)
+	}
+	if got.FrequencyPenalty != nil {
+		t.Errorf("unset frequency_penalty must stay nil, got %v", *got.FrequencyPenalty)
+	}
+	if got.TopP == nil || *got.TopP != 0.9 || got.Seed == nil || *got.Seed != 7 {
+		t.Errorf("top_p/seed: got %v / %v", got.TopP, got.Seed)
+	}
+	if len(got.Stop) != 1 || got.Stop[0] != "END" {
+		t.Errorf("stop: got %v", got.Stop)
+	}
+	if got.LogitBias["50256"] != -100 || got.User != "u1" || string(got.ResponseFormat) != `{"type":"json_object"}` {
+		t.Errorf("logit_bias/user/response_format: got %v / %q / %s", got.LogitBias, got.User, got.ResponseFormat)
+	}
+	if got.MaxCompletionTokens == nil || *got.MaxCompletionTokens != 64 {
+		t.Errorf("max_completion_tokens: got %v", got.MaxCompletionTokens)
+	}
+	if got.ParallelToolCalls == nil || *got.ParallelToolCalls {
+		t.Errorf("parallel_tool_calls false must be forwarded, got %v", got.ParallelToolCalls)
+	}
+}
+
+func TestCompletionHandler_NullOrEmptyStopIsUnset(t *testing.T) {
+	for _, stop := range []string{`null`, `""`, `["", ""]`} {
+		compl := &fakeCompletion{chunks: []*completion.CompletionChunk{{Content: "a"}, {Done: true}}}
+		s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+		rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}],"stop":`+stop+`}`)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("stop %s: status %d, body %s", stop, rec.Code, rec.Body.String())
+		}
+		if compl.got.Stop != nil {
+			t.Errorf("stop %s: forwarded %q, want unset", stop, compl.got.Stop)
+		}
+	}
+}
+
+func TestCompletionHandler_PropagatesFinishReasonAndSkipsTruncatedCache(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "cut"},
+		{Done: true, FinishReason: "length"},
+	}}
+	cache := &fakeCache{}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: cache, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+
+	if !strings.Contains(rec.Body.String(), `"finish_reason":"length"`) {
+		t.Errorf("final chunk must carry finish_reason length: %s", rec.Body.String())
+	}
+	if len(cache.sets) != 0 {
+		t.Errorf("truncated answer must not be cached, got %+v", cache.sets)
+	}
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// chunkWriter emits chat.completion.chunk events for one response. Every
+// chunk carries the same id, created and model, and the first delta carries
+// the assistant role, matching what the OpenAI SDKs expect when merging.
+type chunkWriter struct {
+	w        http.ResponseWriter
+	flusher  http.Flusher
+	id       string
+	created  int64
+	model    string
+	roleSent bool
+}
+
+// newChunkWriter sets the SSE headers and binds the writer to gw's
+// completion id. It fails when the ResponseWriter cannot flush.
+func newChunkWriter(gw *GatewayContext, model string) (*chunkWriter, error) {
+	setSSEHeaders(gw.Response.Writer, gw.Response.Header)
+	flusher, ok := gw.Response.Writer.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming not supported")
+	}
+	return &chunkWriter{
+		w:       gw.Response.Writer,
+		flusher: flusher,
+		id:      gw.completionID(),
+		created: gw.StartedAt.Unix(),
+		model:   model,
+	}, nil
+}
+
+func (c *chunkWriter) delta(d ChatStreamDelta) {
+	if !c.roleSent {
+		d.Role = "assistant"
+		c.roleSent = true
+	}
+	c.write([]ChatStreamChoice{{Index: 0, Delta: d}}, nil)
+}
+
+// usage emits the OpenAI-style usage chunk: empty choices, populated usage.
+func (c *chunkWriter) usage(u Usage) {
+	c.write([]ChatStreamChoice{}, &u)
+}
+
+func (c *chunkWriter) finish(reason string) {
+	c.write([]ChatStreamChoice{{Index: 0, FinishReason: &reason}}, nil)
+}
+
+func (c *chunkWriter) done() {
+	_, _ = fmt.Fprintf(c.w, "data: [DONE]\n\n")
+	c.flusher.Flush()
+}
+
+func (c *chunkWriter) write(choices []ChatStreamChoice, usage *Usage) {
+	jsonBytes, _ := json.Marshal(ChatCompletionChunk{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: choices,
+		Usage:   usage,
+	})
+	_, _ = fmt.Fprintf(c.w, "data: %s\n\n", jsonBytes)
+	c.flusher.Flush()
+}
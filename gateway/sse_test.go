@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"llm_gateway/completion"
+)
+
+// decodeSSEChunks parses every data line except [DONE].
+func decodeSSEChunks(t *testing.T, body string) []ChatCompletionChunk {
+	t.Helper()
+	var out []ChatCompletionChunk
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var c ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			t.Fatalf("decode chunk %q: %v", data, err)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func assertConformantStream(t *testing.T, body, model string) {
+	t.Helper()
+	chunks := decodeSSEChunks(t, body)
+	if len(chunks) < 2 {
+		t.Fatalf("expected content and finish chunks, got %d: %s", len(chunks), body)
+	}
+	first := chunks[0]
+	if !strings.HasPrefix(first.ID, "chatcmpl-") || first.Created == 0 {
+		t.Errorf("first chunk id/created: got %q / %d", first.ID, first.Created)
+	}
+	if first.Choices[0].Delta.Role != "assistant" {
+		t.Errorf("first delta must carry the assistant role, got %+v", first.Choices[0].Delta)
+	}
+	for i, c := range chunks {
+		if c.ID != first.ID || c.Created != first.Created || c.Model != model || c.Object != "chat.completion.chunk" {
+			t.Errorf("chunk %d envelope: got id=%q created=%d model=%q object=%q", i, c.ID, c.Created, c.Model, c.Object)
+		}
+		if i < len(chunks)-1 && len(c.Choices) > 0 && c.Choices[0].FinishReason != nil {
+			t.Errorf("chunk %d: finish_reason must be null before the last chunk", i)
+		}
+	}
+	last := chunks[len(chunks)-1]
+	if last.Choices[0].FinishReason == nil || *last.Choices[0].FinishReason != "stop" {
+		t.Errorf("last chunk finish_reason: got %v", last.Choices[0].FinishReason)
+	}
+}
+
+func TestStream_UpstreamChunksShareOneID(t *testing.T) {
+	compl := &fakeCompletion{chunks: []*completion.CompletionChunk{
+		{Content: "Hel"},
+		{Content: "lo"},
+		{Done: true, PromptTokens: 1, CompletionTokens: 2, TokenUsage: 3},
+	}}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+
+	assertConformantStream(t, rec.Body.String(), "m")
+}
+
+func TestStream_CachedAnswerIsConformant(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{hit: true, answer: "from the cache"}, Completion: &fakeCompletion{}})
+
+	rec := doChatRequest(t, s, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+
+	assertConformantStream(t, rec.Body.String(), "m")
+}
+
+func TestStream_MockAnswerIsConformant(t *testing.T) {
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: &fakeCompletion{}})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t))
+	req.Header.Set("x-mock", "true")
+	rec := httptest.NewRecorder()
+	s.CompletionHandler(rec, req)
+
+	assertConformantStream(t, rec.Body.String(), "m")
+}
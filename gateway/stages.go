@@ -2,12 +2,15 @@ package gateway
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"llm_gateway/auth"
 	"llm_gateway/auth/redis"
 	"llm_gateway/cache"
 	"llm_gateway/completion"
@@ -20,18 +23,39 @@ import (
 func defaultGatewayPipeline() *Pipeline {
 	return NewPipeline(
 		newStageHandler("cors_handler", []StageName{StageRequestReceived}, handleCORSStage),
-		newStageHandler("rate_limit_handler", []StageName{StageRequestReceived}, handleRateLimitStage),
 		newStageHandler("token_extract_handler", []StageName{StageRequestReceived}, handleTokenExtractStage),
 		newStageHandler("request_decode_handler", []StageName{StageRequestDecoded}, handleRequestDecodeStage),
 		newStageHandler("prompt_build_handler", []StageName{StageRequestDecoded}, handlePromptBuildStage),
 		newStageHandler("auth_validate_handler", []StageName{StageBeforeUpstream}, handleAuthValidateStage),
+		newStageHandler("rate_limit_handler", []StageName{StageBeforeUpstream}, handleRateLimitStage),
+		newStageHandler("model_access_handler", []StageName{StageBeforeUpstream}, handleModelAccessStage),
+		newStageHandler("budget_check_handler", []StageName{StageBeforeUpstream}, handleBudgetCheckStage),
 		newStageHandler("rag_retrieve_handler", []StageName{StageBeforeUpstream}, handleRAGRetrieveStage),
 		newStageHandler("mock_response_handler", []StageName{StageBeforeUpstream}, handleMockResponseStage),
 		newStageHandler("cache_lookup_handler", []StageName{StageBeforeUpstream}, handleCacheLookupStage),
 		newStageHandler("upstream_request_build_handler", []StageName{StageBeforeUpstream}, handleUpstreamBuildStage),
+		newStageHandler("token_limit_handler", []StageName{StageBeforeUpstream}, handleTokenLimitStage),
 		newStageHandler("stream_assemble_handler", []StageName{StageStreamChunk}, handleStreamChunkStage),
+		newStageHandler("pricing_handler", []StageName{StageStreamChunk}, handlePricingStage),
+		newStageHandler("token_reconcile_handler", []StageName{StageResponseComplete}, handleTokenReconcileStage),
+		newStageHandler("budget_record_handler", []StageName{StageResponseComplete}, handleBudgetRecordStage),
+		newStageHandler("usage_metrics_handler", []StageName{StageResponseComplete}, handleUsageMetricsStage),
+		newStageHandler("usage_record_handler", []StageName{StageResponseComplete}, handleUsageRecordStage),
 		newStageHandler("cache_writeback_handler", []StageName{StageResponseComplete}, handleCacheWritebackStage),
 		newStageHandler("audit_log_handler", []StageName{StageResponseComplete}, handleAuditLogStage),
+		newStageHandler("access_log_handler", []StageName{StageResponseComplete}, handleAccessLogStage),
+	)
+}
+
+// defaultModelsPipeline guards /v1/models with the same CORS, token and
+// rate-limit checks as chat completions. There is no body to decode and nothing to
+// send upstream, so only the request_received and before_upstream stages run.
+func defaultModelsPipeline() *Pipeline {
+	return NewPipeline(
+		newStageHandler("cors_handler", []StageName{StageRequestReceived}, handleCORSStage),
+		newStageHandler("token_extract_handler", []StageName{StageRequestReceived}, handleTokenExtractStage),
+		newStageHandler("auth_validate_handler", []StageName{StageBeforeUpstream}, handleAuthValidateStage),
+		newStageHandler("rate_limit_handler", []StageName{StageBeforeUpstream}, handleRateLimitStage),
 	)
 }
 
@@ -82,41 +106,6 @@ func handleCORSStage(gw *GatewayContext) StageResult {
 	return StageResult{Action: ActionContinue}
 }
 
-func handleRateLimitStage(gw *GatewayContext) StageResult {
-	if !rateLimiter.Allow() {
-		slog.WarnContext(gw.Context, "rate limit hit", "limiter", "token_bucket")
-		gw.Response.DirectResponse = newJSONDirectResponse(
-			http.StatusTooManyRequests,
-			map[string]any{
-				"error": map[string]string{
-					"message": "rate limit exceeded",
-					"type":    "server_busy",
-				},
-			},
-		)
-		return StageResult{Action: ActionReject, StatusCode: http.StatusTooManyRequests, Message: "rate limit exceeded"}
-	}
-
-	select {
-	case parallelSemaphore <- struct{}{}:
-		gw.Runtime.ParallelSlotAcquired = true
-	default:
-		slog.WarnContext(gw.Context, "rate limit hit", "limiter", "semaphore")
-		gw.Response.DirectResponse = newJSONDirectResponse(
-			http.StatusTooManyRequests,
-			map[string]any{
-				"error": map[string]string{
-					"message": "rate limit exceeded",
-					"type":    "server_busy",
-				},
-			},
-		)
-		return StageResult{Action: ActionReject, StatusCode: http.StatusTooManyRequests, Message: "rate limit exceeded"}
-	}
-
-	return StageResult{Action: ActionContinue}
-}
-
 func handleTokenExtractStage(gw *GatewayContext) StageResult {
 	authHeader := gw.Request.Header.Get("Authorization")
 	if authHeader == "" {
@@ -133,9 +122,12 @@ func handleTokenExtractStage(gw *GatewayContext) StageResult {
 	}
 
 	if !redis.CheckTokenFormat(tokenPrefix, tokenEntropyLen, token) {
-		gw.Auth.RejectReason = "Invalid token format"
-		gw.Response.DirectResponse = invalidAPIKeyResponse(gw.Auth.RejectReason)
-		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: gw.Auth.RejectReason}
+		gw.Auth.Provider = providerFor(gw.Services.AuthProviders, token)
+		if gw.Auth.Provider == nil {
+			gw.Auth.RejectReason = "Invalid token format"
+			gw.Response.DirectResponse = invalidAPIKeyResponse(gw.Auth.RejectReason)
+			return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: gw.Auth.RejectReason}
+		}
 	}
 
 	gw.Auth.BearerToken = token
@@ -146,10 +138,7 @@ func handleRequestDecodeStage(gw *GatewayContext) StageResult {
 	bodyBytes, err := io.ReadAll(gw.Request.Raw.Body)
 	if err != nil {
 		slog.ErrorContext(gw.Context, "read request body failed", "err", err)
-		gw.Response.DirectResponse = newJSONDirectResponse(
-			http.StatusBadRequest,
-			map[string]string{"error": "Failed to parse user request"},
-		)
+		gw.Response.DirectResponse = newErrorResponse(http.StatusBadRequest, "", "Failed to parse user request")
 		return StageResult{Action: ActionReject, StatusCode: http.StatusBadRequest, Message: "Failed to parse user request", Err: err}
 	}
 	defer gw.Request.Raw.Body.Close()
@@ -159,10 +148,7 @@ func handleRequestDecodeStage(gw *GatewayContext) StageResult {
 	var userReq ChatCompleteionRequest
 	if err := json.Unmarshal(bodyBytes, &userReq); err != nil {
 		slog.ErrorContext(gw.Context, "parse request body failed", "err", err)
-		gw.Response.DirectResponse = newJSONDirectResponse(
-			http.StatusBadRequest,
-			map[string]string{"error": "Failed to parse user request"},
-		)
+		gw.Response.DirectResponse = newErrorResponse(http.StatusBadRequest, "", "Failed to parse user request")
 		return StageResult{Action: ActionReject, StatusCode: http.StatusBadRequest, Message: "Failed to parse user request", Err: err}
 	}
 
@@ -175,6 +161,7 @@ func handlePromptBuildStage(gw *GatewayContext) StageResult {
 		return StageResult{Action: ActionReject, StatusCode: http.StatusBadRequest, Message: "request body not decoded"}
 	}
 
+	gw.Request.Messages = buildUpstreamMessages(gw.Request.Chat.Messages)
 	gw.Request.PromptText = buildPromptText(gw.Request.Chat.Messages)
 	gw.Request.NormalizedKey = gw.Request.PromptText
 	gw.Route.Model = gw.Request.Chat.Model
@@ -187,8 +174,28 @@ func handlePromptBuildStage(gw *GatewayContext) StageResult {
 	return StageResult{Action: ActionContinue}
 }
 
+// routeScopes names the token scope each public route requires. /v1/models
+// is open to every valid token: both chat and embeddings clients list models.
+var routeScopes = map[string]string{
+	"/v1/chat/completions": auth.ScopeChat,
+	"/v1/embeddings":       auth.ScopeEmbeddings,
+}
+
+// providerFor returns the first provider that recognizes token, or nil.
+func providerFor(providers []auth.Provider, token string) auth.Provider {
+	for _, p := range providers {
+		if p.Recognizes(token) {
+			return p
+		}
+	}
+	return nil
+}
+
 func handleAuthValidateStage(gw *GatewayContext) StageResult {
-	isValid, alias, err := gw.Services.Auth.Get(gw.Context, gw.Auth.BearerToken)
+	if gw.Auth.Provider != nil {
+		return authenticateWithProvider(gw)
+	}
+	isValid, info, err := gw.Services.Auth.Get(gw.Context, gw.Auth.BearerToken)
 	if err != nil {
 		slog.ErrorContext(gw.Context, "auth service error", "err", err)
 		gw.Response.DirectResponse = invalidAPIKeyResponse("Authentication service unavailable")
@@ -199,8 +206,46 @@ func handleAuthValidateStage(gw *GatewayContext) StageResult {
 		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: "Invalid or revoked token"}
 	}
 
+	if info.Expired(time.Now()) {
+		gw.Response.DirectResponse = tokenExpiredResponse(info)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: "Token expired"}
+	}
+	return acceptToken(gw, info)
+}
+
+// authenticateWithProvider validates a bearer token that is not an API
+// token, such as an OIDC access token, with the provider that claimed it.
+func authenticateWithProvider(gw *GatewayContext) StageResult {
+	p := gw.Auth.Provider
+	info, err := p.Authenticate(gw.Context, gw.Auth.BearerToken)
+	switch {
+	case errors.Is(err, auth.ErrCredentialExpired):
+		gw.Response.DirectResponse = newErrorResponse(http.StatusUnauthorized, errorCodeTokenExpired, "Token expired")
+		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: "Token expired"}
+	case errors.Is(err, auth.ErrInvalidCredential):
+		slog.DebugContext(gw.Context, "bearer token rejected", "provider", p.Name(), "err", err)
+		gw.Response.DirectResponse = invalidAPIKeyResponse("Invalid bearer token")
+		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: "Invalid bearer token", Err: err}
+	case err != nil:
+		slog.ErrorContext(gw.Context, "auth provider error", "provider", p.Name(), "err", err)
+		gw.Response.DirectResponse = invalidAPIKeyResponse("Authentication service unavailable")
+		return StageResult{Action: ActionReject, StatusCode: http.StatusUnauthorized, Message: "Authentication service unavailable", Err: err}
+	}
+	return acceptToken(gw, info)
+}
+
+// acceptToken applies the checks common to every kind of token and records
+// what it grants.
+func acceptToken(gw *GatewayContext, info auth.TokenInfo) StageResult {
+	if scope := routeScopes[gw.Request.Path]; scope != "" && !info.HasScope(scope) {
+		slog.WarnContext(gw.Context, "token scope missing", "alias", info.Alias, "scope", scope)
+		gw.Response.DirectResponse = missingScopeResponse(scope)
+		return StageResult{Action: ActionReject, StatusCode: http.StatusForbidden, Message: "missing scope " + scope}
+	}
+
 	gw.Auth.Valid = true
-	gw.Auth.Subject = alias
+	gw.Auth.Subject = info.Alias
+	gw.Auth.Token = info
 	return StageResult{Action: ActionContinue}
 }
 
@@ -219,6 +264,11 @@ func handleMockResponseStage(gw *GatewayContext) StageResult {
 }
 
 func handleCacheLookupStage(gw *GatewayContext) StageResult {
+	if reason := gw.cacheBypassReason(); reason != "" {
+		slog.DebugContext(gw.Context, "cache lookup skipped", "reason", reason)
+		return StageResult{Action: ActionContinue}
+	}
+
 	ctx, span := tracing.Tracer("gateway").Start(gw.Context, "gateway.cache.lookup")
 	defer span.End()
 
@@ -265,11 +315,23 @@ func handleCacheLookupStage(gw *GatewayContext) StageResult {
 func handleUpstreamBuildStage(gw *GatewayContext) StageResult {
 	gw.Route.TargetService = "completion"
 	gw.Upstream.Request = &completion.CompletionRequest{
-		Model:       gw.Request.Chat.Model,
-		Question:    gw.Request.PromptText,
-		Temperature: gw.Request.Chat.Temperature,
-		MaxTokens:   gw.Request.Chat.MaxTokens,
-		Stream:      true,
+		Model:               gw.Request.Chat.Model,
+		Messages:            gw.Request.Messages,
+		Temperature:         gw.Request.Chat.Temperature,
+		TopP:                gw.Request.Chat.TopP,
+		MaxTokens:           gw.Request.Chat.MaxTokens,
+		MaxCompletionTokens: gw.Request.Chat.MaxCompletionTokens,
+		Stop:                gw.Request.Chat.Stop,
+		PresencePenalty:     gw.Request.Chat.PresencePenalty,
+		FrequencyPenalty:    gw.Request.Chat.FrequencyPenalty,
+		Seed:                gw.Request.Chat.Seed,
+		LogitBias:           gw.Request.Chat.LogitBias,
+		User:                gw.Request.Chat.User,
+		ResponseFormat:      gw.Request.Chat.ResponseFormat,
+		Stream:              gw.Request.Chat.Stream,
+		Tools:               buildUpstreamTools(gw.Request.Chat.Tools),
+		ToolChoice:          gw.Request.Chat.ToolChoice,
+		ParallelToolCalls:   gw.Request.Chat.ParallelToolCalls,
 	}
 	return StageResult{Action: ActionContinue}
 }
@@ -289,9 +351,17 @@ func handleStreamChunkStage(gw *GatewayContext) StageResult {
 	if chunk.Content != "" {
 		gw.Stream.FullAnswer.WriteString(chunk.Content)
 	}
+	if len(chunk.ToolCalls) > 0 {
+		gw.Stream.ToolCalls = mergeToolCallDeltas(gw.Stream.ToolCalls, chunk.ToolCalls)
+	}
 
 	if chunk.Done {
 		gw.Stream.TokenUsage = chunk.TokenUsage
+		gw.Stream.PromptTokens = chunk.PromptTokens
+		gw.Stream.CachedPromptTokens = chunk.CachedPromptTokens
+		gw.Stream.CompletionTokens = chunk.CompletionTokens
+		gw.Stream.Endpoint = chunk.Endpoint
+		gw.Stream.FinishReason = chunk.FinishReason
 		slog.DebugContext(gw.Context, "upstream stream completed")
 	}
 
@@ -303,6 +373,20 @@ func handleCacheWritebackStage(gw *GatewayContext) StageResult {
 		return StageResult{Action: ActionContinue}
 	}
 
+	reason := gw.cacheBypassReason()
+	if reason == "" && len(gw.Stream.ToolCalls) > 0 {
+		reason = "tools"
+	}
+	// Only complete answers are worth replaying: "length" and "content_filter"
+	// mean the text was cut off.
+	if reason == "" && gw.finishReason() != finishReasonStop {
+		reason = "finish_reason_" + gw.finishReason()
+	}
+	if reason != "" {
+		slog.DebugContext(gw.Context, "cache write skipped", "reason", reason)
+		return StageResult{Action: ActionContinue}
+	}
+
 	// Skip caching when the response was RAG-augmented: the retrieved context
 	// depends on external documents that can be added or deleted at any time,
 	// so caching such responses would return stale answers after document changes.
@@ -334,15 +418,17 @@ func handleAuditLogStage(gw *GatewayContext) StageResult {
 }
 
 func invalidAPIKeyResponse(message string) *DirectResponse {
-	return newJSONDirectResponse(
-		http.StatusUnauthorized,
-		map[string]any{
-			"error": map[string]string{
-				"message": message,
-				"type":    "invalid_api_key",
-			},
-		},
-	)
+	return newErrorResponse(http.StatusUnauthorized, errorCodeInvalidAPIKey, message)
+}
+
+func tokenExpiredResponse(info auth.TokenInfo) *DirectResponse {
+	return newErrorResponse(http.StatusUnauthorized, errorCodeTokenExpired,
+		fmt.Sprintf("Token expired at %s", info.ExpiresAt.UTC().Format(time.RFC3339)))
+}
+
+func missingScopeResponse(scope string) *DirectResponse {
+	return newErrorResponse(http.StatusForbidden, errorCodeMissingScope,
+		fmt.Sprintf("This token does not have the '%s' scope.", scope))
 }
 
 func newJSONDirectResponse(statusCode int, payload any) *DirectResponse {
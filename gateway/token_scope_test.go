@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+)
+
+func TestCompletionHandler_ExpiredTokenRejected(t *testing.T) {
+	compl := &fakeCompletion{}
+	expired := auth.TokenInfo{Alias: "tester", ExpiresAt: time.Now().Add(-time.Minute)}
+	s := NewServer(Dependencies{Auth: fakeAuth{info: &expired}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status: got %d, want 401", rec.Code)
+	}
+	if body := decodeErrorBody(t, rec.Body.Bytes()); body.Code == nil || *body.Code != errorCodeTokenExpired {
+		t.Errorf("error: got %+v, want code %q", body, errorCodeTokenExpired)
+	}
+	if compl.got != nil {
+		t.Fatal("upstream must not be called for an expired token")
+	}
+}
+
+func TestCompletionHandler_MissingScopeForbidden(t *testing.T) {
+	compl := &fakeCompletion{}
+	embedOnly := auth.TokenInfo{Alias: "tester", Scopes: []string{auth.ScopeEmbeddings}}
+	s := NewServer(Dependencies{Auth: fakeAuth{info: &embedOnly}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status: got %d, want 403", rec.Code)
+	}
+	if body := decodeErrorBody(t, rec.Body.Bytes()); body.Code == nil || *body.Code != errorCodeMissingScope {
+		t.Errorf("error: got %+v, want code %q", body, errorCodeMissingScope)
+	}
+}
+
+func TestCompletionHandler_ModelOutsideTokenModelsRejected(t *testing.T) {
+	compl := &fakeCompletion{}
+	info := auth.TokenInfo{Alias: "tester", Models: []string{"allowed"}}
+	s := NewServer(Dependencies{Auth: fakeAuth{info: &info}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, `{"model":"other","messages":[{"role":"user","content":"hi"}]}`)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d, want 404", rec.Code)
+	}
+	if compl.got != nil {
+		t.Fatal("upstream must not be called for a model outside the token's list")
+	}
+}
+
+func TestModelsHandler_ListAppliesTokenModels(t *testing.T) {
+	info := auth.TokenInfo{Alias: "tester", Models: []string{"a"}}
+	s := NewServer(Dependencies{Auth: fakeAuth{info: &info}, CompletionModels: fakeModelLister{models: []string{"a", "b"}}})
+
+	rec := doModelsRequest(t, s, "/v1/models")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d", rec.Code)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != "a" {
+		t.Errorf("models: got %+v, want only a", list.Data)
+	}
+}
+
+func doAdminTokenRequest(t *testing.T, s *Server, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer "+newTestToken(t))
+	rec := httptest.NewRecorder()
+	s.AdminHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminCheck_AdminReadTokenAllowsGetOnly(t *testing.T) {
+	t.Setenv("ADMIN_SECRET", "s3cret")
+	info := auth.TokenInfo{Alias: "dash", Scopes: []string{auth.ScopeAdminRead}}
+	s := NewServer(Dependencies{Auth: fakeAuth{info: &info}})
+
+	if rec := doAdminTokenRequest(t, s, http.MethodGet, "/admin/usage"); rec.Code != http.StatusOK {
+		t.Fatalf("GET: got %d, want 200: %s", rec.Code, rec.Body)
+	}
+	rec := doAdminTokenRequest(t, s, http.MethodPost, "/admin/budget/reset")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("POST: got %d, want 403", rec.Code)
+	}
+}
+
+func TestAdminCheck_TokenWithoutAdminReadForbidden(t *testing.T) {
+	t.Setenv("ADMIN_SECRET", "s3cret")
+	s := NewServer(Dependencies{Auth: fakeAuth{}})
+
+	rec := doAdminTokenRequest(t, s, http.MethodGet, "/admin/usage")
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status: got %d, want 403", rec.Code)
+	}
+	if body := decodeErrorBody(t, rec.Body.Bytes()); body.Code == nil || *body.Code != errorCodeMissingScope {
+		t.Errorf("error: got %+v, want code %q", body, errorCodeMissingScope)
+	}
+}
+
+func TestAdminCreate_RejectsUnknownScope(t *testing.T) {
+	_, mux := newAdminTestServer(t, Dependencies{Auth: fakeAuth{}})
+	req := httptest.NewRequest(http.MethodPost, "/admin/create", strings.NewReader(`{"alias":"a","scopes":["root"]}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want 400", rec.Code)
+	}
+}
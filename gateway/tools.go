@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"llm_gateway/completion"
+)
+
+const (
+	finishReasonStop      = "stop"
+	finishReasonToolCalls = "tool_calls"
+)
+
+// usesTools reports whether the request defines tools or carries tool turns.
+// The cache key is the flattened text, which ignores tool definitions and
+// results, and the cache can only replay plain text answers.
+func (gw *GatewayContext) usesTools() bool {
+	if gw.Request.Chat == nil {
+		return false
+	}
+	if len(gw.Request.Chat.Tools) > 0 {
+		return true
+	}
+	for _, m := range gw.Request.Chat.Messages {
+		if m.Role == "tool" || len(m.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// finishReason is the upstream's own finish_reason when it sent one. For
+// upstreams that omit it, "tool_calls" is inferred when the turn produced
+// tool calls, "stop" otherwise.
+func (gw *GatewayContext) finishReason() string {
+	if gw.Stream.FinishReason != "" {
+		return gw.Stream.FinishReason
+	}
+	if len(gw.Stream.ToolCalls) > 0 {
+		return finishReasonToolCalls
+	}
+	return finishReasonStop
+}
+
+// assembledChoice builds the non-streaming choice from everything the
+// stream_assemble stage collected.
+func (gw *GatewayContext) assembledChoice() ChatCompletionChoice {
+	msg := AssistantMessage{Role: "assistant", ToolCalls: toToolCalls(gw.Stream.ToolCalls)}
+	if content := gw.Stream.FullAnswer.String(); content != "" || len(msg.ToolCalls) == 0 {
+		msg.Content = &content
+	}
+	return ChatCompletionChoice{Index: 0, Message: msg, FinishReason: gw.finishReason()}
+}
+
+// mergeToolCallDeltas folds streamed fragments into complete calls: the first
+// fragment for an index opens the call, later ones append to its arguments.
+func mergeToolCallDeltas(calls []completion.ToolCall, deltas []completion.ToolCall) []completion.ToolCall {
+	for _, d := range deltas {
+		pos := -1
+		for i := range calls {
+			if calls[i].Index == d.Index {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			calls = append(calls, completion.ToolCall{Index: d.Index})
+			pos = len(calls) - 1
+		}
+		c := &calls[pos]
+		if d.ID != "" {
+			c.ID = d.ID
+		}
+		if d.Type != "" {
+			c.Type = d.Type
+		}
+		if d.Name != "" {
+			c.Name = d.Name
+		}
+		c.Arguments += d.Arguments
+	}
+	return calls
+}
+
+func buildUpstreamTools(tools []Tool) []completion.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	out := make([]completion.Tool, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, completion.Tool{
+			Type:        t.Type,
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  t.Function.Parameters,
+		})
+	}
+	return out
+}
+
+func fromToolCalls(calls []ToolCall) []completion.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]completion.ToolCall, 0, len(calls))
+	for i, c := range calls {
+		out = append(out, completion.ToolCall{
+			Index:     i,
+			ID:        c.ID,
+			Type:      c.Type,
+			Name:      c.Function.Name,
+			Arguments: c.Function.Arguments,
+		})
+	}
+	return out
+}
+
+func toToolCalls(calls []completion.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, 0, len(calls))
+	for _, c := range calls {
+		typ := c.Type
+		if typ == "" {
+			typ = "function"
+		}
+		out = append(out, ToolCall{
+			ID:       c.ID,
+			Type:     typ,
+			Function: FunctionCall{Name: c.Name, Arguments: c.Arguments},
+		})
+	}
+	return out
+}
+
+func toToolCallDeltas(calls []completion.ToolCall) []ToolCallDelta {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCallDelta, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, ToolCallDelta{
+			Index:    c.Index,
+			ID:       c.ID,
+			Type:     c.Type,
+			Function: FunctionCall{Name: c.Name, Arguments: c.Arguments},
+		})
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"llm_gateway/completion"
+)
+
+// toolCallChunks streams one call split across three deltas, the way OpenAI does.
+func toolCallChunks() []*completion.CompletionChunk {
+	return []*completion.CompletionChunk{
+		{ToolCalls: []completion.ToolCall{{Index: 0, ID: "call_1", Type: "function", Name: "get_weather"}}},
+		{ToolCalls: []completion.ToolCall{{Index: 0, Arguments: `{"city":`}}},
+		{ToolCalls: []completion.ToolCall{{Index: 0, Arguments: `"Paris"}`}}},
+		{Done: true},
+	}
+}
+
+const toolRequestBody = `{"model":"m","messages":[
+	{"role":"user","content":"weather?"},
+	{"role":"assistant","content":null,"tool_calls":[{"id":"call_0","type":"function","function":{"name":"get_weather","arguments":"{}"}}]},
+	{"role":"tool","tool_call_id":"call_0","content":"sunny"}
+],"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}}],"tool_choice":"auto"`
+
+func TestCompletionHandler_ForwardsToolsAndToolTurns(t *testing.T) {
+	compl := &fakeCompletion{chunks: toolCallChunks()}
+	cache := &fakeCache{hit: true, answer: "stale"}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: cache, Completion: compl})
+
+	rec := doChatRequest(t, s, toolRequestBody+`}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, body %s", rec.Code, rec.Body.String())
+	}
+	got := compl.got
+	if got == nil {
+		t.Fatal("upstream not called; cache must be bypassed for tool requests")
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Name != "get_weather" || string(got.Tools[0].Parameters) != `{"type":"object"}` {
+		t.Errorf("tools: got %+v", got.Tools)
+	}
+	if string(got.ToolChoice) != `"auto"` {
+		t.Errorf("tool_choice: got %s", got.ToolChoice)
+	}
+	if tc := got.Messages[1].ToolCalls; len(tc) != 1 || tc[0].ID != "call_0" || tc[0].Name != "get_weather" {
+		t.Errorf("assistant tool_calls: got %+v", tc)
+	}
+	if got.Messages[2].Role != "tool" || got.Messages[2].ToolCallID != "call_0" {
+		t.Errorf("tool message: got %+v", got.Messages[2])
+	}
+	if len(cache.sets) != 0 {
+		t.Errorf("tool turn must not be cached, got %d sets", len(cache.sets))
+	}
+}
+
+func TestCompletionHandler_NonStreamingAssemblesToolCalls(t *testing.T) {
+	compl := &fakeCompletion{chunks: toolCallChunks()}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, toolRequestBody+`}`)
+
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body.String())
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("finish_reason: got %q", choice.FinishReason)
+	}
+	if choice.Message.Content != nil {
+		t.Errorf("content: want null, got %q", *choice.Message.Content)
+	}
+	want := ToolCall{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0] != want {
+		t.Errorf("tool_calls: got %+v", choice.Message.ToolCalls)
+	}
+}
+
+func TestCompletionHandler_StreamingEmitsToolCallDeltas(t *testing.T) {
+	compl := &fakeCompletion{chunks: toolCallChunks()}
+	s := NewServer(Dependencies{Auth: fakeAuth{}, Cache: &fakeCache{}, Completion: compl})
+
+	rec := doChatRequest(t, s, toolRequestBody+`,"stream":true}`)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]`) {
+		t.Errorf("first delta missing: %s", body)
+	}
+	if !strings.Contains(body, `"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]`) {
+		t.Errorf("argument delta missing: %s", body)
+	}
+	if !strings.Contains(body, `"finish_reason":"tool_calls"`) {
+		t.Errorf("finish_reason tool_calls missing: %s", body)
+	}
+}
@@ -0,0 +1,274 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envUsageRetention = "USAGE_RETENTION"
+
+	defaultUsageRetention = 30 * 24 * time.Hour
+	// memoryUsageCapacity bounds the in-process ledger; the oldest entries
+	// are dropped first.
+	memoryUsageCapacity = 100_000
+
+	usageGroupAlias = "alias"
+	usageGroupModel = "model"
+	usageGroupDay   = "day"
+
+	// usageRecordTimeout bounds writing one ledger entry. Like the budget
+	// record it runs after the response, detached from the request context.
+	usageRecordTimeout = time.Second
+)
+
+// UsageEntry is one ledger line: a request that got past authentication,
+// whatever its outcome.
+type UsageEntry struct {
+	Timestamp          time.Time `json:"timestamp"` // when the request finished
+	RequestID          string    `json:"request_id"`
+	Alias              string    `json:"alias"`
+	Model              string    `json:"model"`
+	Endpoint           string    `json:"endpoint,omitempty"` // pool endpoint; empty for cache hits, embeddings and rejections
+	Path               string    `json:"path"`
+	PromptTokens       int       `json:"prompt_tokens"`
+	CachedPromptTokens int       `json:"cached_prompt_tokens"`
+	CompletionTokens   int       `json:"completion_tokens"`
+	TotalTokens        int       `json:"total_tokens"`
+	CostUSD            float64   `json:"cost_usd"`
+	CacheHit           bool      `json:"cache_hit"`
+	RAGUsed            bool      `json:"rag_used"`
+	LatencyMS          int64     `json:"latency_ms"`
+	Status             int       `json:"status"`
+}
+
+// UsageFilter selects ledger entries with From <= Timestamp < To. Empty Alias
+// and Model match every entry.
+type UsageFilter struct {
+	From, To time.Time
+	Alias    string
+	Model    string
+}
+
+func (f UsageFilter) matches(e UsageEntry) bool {
+	return !e.Timestamp.Before(f.From) && e.Timestamp.Before(f.To) &&
+		(f.Alias == "" || e.Alias == f.Alias) &&
+		(f.Model == "" || e.Model == f.Model)
+}
+
+// UsageLedger stores one entry per request for reporting and export.
+// Implementations: MemoryUsageLedger (per process, bounded, lost on restart)
+// and RedisUsageLedger (a Redis stream shared by every gateway replica).
+type UsageLedger interface {
+	Record(ctx context.Context, e UsageEntry) error
+	// Scan calls fn for each entry matching f, oldest first, and stops at the
+	// first error fn returns.
+	Scan(ctx context.Context, f UsageFilter, fn func(UsageEntry) error) error
+}
+
+// LoadUsageRetentionFromEnv reads how long the ledger keeps entries from
+// USAGE_RETENTION (a Go duration such as "720h"). Defaults to 30 days.
+func LoadUsageRetentionFromEnv() (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv(envUsageRetention))
+	if raw == "" {
+		return defaultUsageRetention, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("usage ledger: %s must be a positive duration, got %q", envUsageRetention, raw)
+	}
+	return d, nil
+}
+
+// MemoryUsageLedger keeps the most recent entries in process. Each replica
+// sees only the requests it served, and everything is lost on restart, so it
+// is meant for tests and development; deployments use RedisUsageLedger.
+type MemoryUsageLedger struct {
+	mu       sync.Mutex
+	entries  []UsageEntry // ring buffer once full; next is the oldest
+	next     int
+	capacity int
+}
+
+func NewMemoryUsageLedger() *MemoryUsageLedger {
+	return &MemoryUsageLedger{capacity: memoryUsageCapacity}
+}
+
+func (l *MemoryUsageLedger) Record(_ context.Context, e UsageEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < l.capacity {
+		l.entries = append(l.entries, e)
+		return nil
+	}
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % l.capacity
+	return nil
+}
+
+func (l *MemoryUsageLedger) Scan(ctx context.Context, f UsageFilter, fn func(UsageEntry) error) error {
+	l.mu.Lock()
+	matched := make([]UsageEntry, 0)
+	for i := range l.entries {
+		e := l.entries[(l.next+i)%len(l.entries)]
+		if f.matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, e := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsageRow is one group of a usage report. Only the grouped-by key fields
+// are set.
+type UsageRow struct {
+	Alias              string  `json:"alias,omitempty"`
+	Model              string  `json:"model,omitempty"`
+	Day                string  `json:"day,omitempty"` // UTC, "2006-01-02"
+	Requests           int64   `json:"requests"`
+	Errors             int64   `json:"errors"` // status >= 400
+	CacheHits          int64   `json:"cache_hits"`
+	RAGRequests        int64   `json:"rag_requests"`
+	PromptTokens       int64   `json:"prompt_tokens"`
+	CachedPromptTokens int64   `json:"cached_prompt_tokens"`
+	CompletionTokens   int64   `json:"completion_tokens"`
+	TotalTokens        int64   `json:"total_tokens"`
+	CostUSD            float64 `json:"cost_usd"`
+	AvgLatencyMS       int64   `json:"avg_latency_ms"`
+
+	latencyMS int64 // sum, for AvgLatencyMS
+}
+
+func (r *UsageRow) add(e UsageEntry) {
+	r.Requests++
+	if e.Status >= 400 {
+		r.Errors++
+	}
+	if e.CacheHit {
+		r.CacheHits++
+	}
+	if e.RAGUsed {
+		r.RAGRequests++
+	}
+	r.PromptTokens += int64(e.PromptTokens)
+	r.CachedPromptTokens += int64(e.CachedPromptTokens)
+	r.CompletionTokens += int64(e.CompletionTokens)
+	r.TotalTokens += int64(e.TotalTokens)
+	r.CostUSD += e.CostUSD
+	r.latencyMS += e.LatencyMS
+}
+
+// parseUsageGroupBy validates a comma-separated group_by list. An empty list
+// sums everything into one row.
+func parseUsageGroupBy(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var groupBy []string
+	for _, g := range strings.Split(raw, ",") {
+		g = strings.TrimSpace(g)
+		switch g {
+		case usageGroupAlias, usageGroupModel, usageGroupDay:
+			groupBy = append(groupBy, g)
+		default:
+			return nil, fmt.Errorf("group_by must be a list of %q, %q and %q, got %q", usageGroupAlias, usageGroupModel, usageGroupDay, g)
+		}
+	}
+	return groupBy, nil
+}
+
+// summarizeUsage aggregates the entries matching f into one row per distinct
+// value of the groupBy fields, ordered by day, alias, then model.
+func summarizeUsage(ctx context.Context, ledger UsageLedger, f UsageFilter, groupBy []string) ([]UsageRow, error) {
+	type groupKey struct{ alias, model, day string }
+	rows := make(map[groupKey]*UsageRow)
+	err := ledger.Scan(ctx, f, func(e UsageEntry) error {
+		var key groupKey
+		for _, g := range groupBy {
+			switch g {
+			case usageGroupAlias:
+				key.alias = e.Alias
+			case usageGroupModel:
+				key.model = e.Model
+			case usageGroupDay:
+				key.day = e.Timestamp.UTC().Format("2006-01-02")
+			}
+		}
+		row := rows[key]
+		if row == nil {
+			row = &UsageRow{Alias: key.alias, Model: key.model, Day: key.day}
+			rows[key] = row
+		}
+		row.add(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]UsageRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Requests > 0 {
+			row.AvgLatencyMS = row.latencyMS / row.Requests
+		}
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Alias != b.Alias {
+			return a.Alias < b.Alias
+		}
+		return a.Model < b.Model
+	})
+	return out, nil
+}
+
+// handleUsageRecordStage writes the request's ledger entry. Requests that
+// never authenticated have no alias to bill and are not recorded.
+func handleUsageRecordStage(gw *GatewayContext) StageResult {
+	if gw.Auth.Subject == "" {
+		return StageResult{Action: ActionContinue}
+	}
+	ragChunks, _ := gw.Data["rag_chunks_count"].(int)
+	entry := UsageEntry{
+		Timestamp:          time.Now().UTC(),
+		RequestID:          gw.RequestID,
+		Alias:              gw.Auth.Subject,
+		Model:              gw.Route.Model,
+		Endpoint:           gw.Stream.Endpoint,
+		Path:               gw.Request.Path,
+		PromptTokens:       gw.Stream.PromptTokens,
+		CachedPromptTokens: gw.Stream.CachedPromptTokens,
+		CompletionTokens:   gw.Stream.CompletionTokens,
+		TotalTokens:        gw.Stream.TokenUsage,
+		CostUSD:            gw.Stream.CostUSD,
+		CacheHit:           gw.Response.FromCache,
+		RAGUsed:            ragChunks > 0,
+		LatencyMS:          time.Since(gw.StartedAt).Milliseconds(),
+		Status:             gw.responseStatus(),
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(gw.Context), usageRecordTimeout)
+	defer cancel()
+	if err := gw.Services.Usage.Record(ctx, entry); err != nil {
+		slog.ErrorContext(gw.Context, "usage ledger record failed", "err", err)
+	}
+	return StageResult{Action: ActionContinue}
+}
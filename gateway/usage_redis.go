@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	usageStreamKey   = "usage:ledger"
+	usageEntryField  = "entry"
+	usageScanPageLen = 1000
+)
+
+// RedisUsageLedger appends entries to a Redis stream shared by every gateway
+// replica. Stream IDs carry the write time in milliseconds, so a time-range
+// scan is an XRANGE. Entries older than the retention are trimmed, roughly,
+// on every write.
+type RedisUsageLedger struct {
+	client    goredis.UniversalClient
+	retention time.Duration
+}
+
+func NewRedisUsageLedger(client goredis.UniversalClient, retention time.Duration) *RedisUsageLedger {
+	return &RedisUsageLedger{client: client, retention: retention}
+}
+
+func (l *RedisUsageLedger) Record(ctx context.Context, e UsageEntry) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	err = l.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: usageStreamKey,
+		MinID:  strconv.FormatInt(time.Now().Add(-l.retention).UnixMilli(), 10),
+		Approx: true,
+		Values: []any{usageEntryField, raw},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("usage ledger: append: %w", err)
+	}
+	return nil
+}
+
+// Scan pages through the stream with XRANGE. An entry's stream ID is its
+// write time, which can trail its Timestamp by a few milliseconds, so the
+// range is widened by a second and every entry is checked against f.
+func (l *RedisUsageLedger) Scan(ctx context.Context, f UsageFilter, fn func(UsageEntry) error) error {
+	start := strconv.FormatInt(f.From.Add(-time.Second).UnixMilli(), 10)
+	end := strconv.FormatInt(f.To.Add(time.Second).UnixMilli(), 10)
+	for {
+		msgs, err := l.client.XRangeN(ctx, usageStreamKey, start, end, usageScanPageLen).Result()
+		if err != nil {
+			return fmt.Errorf("usage ledger: read: %w", err)
+		}
+		for _, msg := range msgs {
+			raw, ok := msg.Values[usageEntryField].(string)
+			if !ok {
+				continue
+			}
+			var e UsageEntry
+			if err := json.Unmarshal([]byte(raw), &e); err != nil {
+				return fmt.Errorf("usage ledger: decode %s: %w", msg.ID, err)
+			}
+			if !f.matches(e) {
+				continue
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(msgs) < usageScanPageLen {
+			return nil
+		}
+		// "(" makes the next page start after the last ID read.
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+}
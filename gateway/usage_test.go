@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm_gateway/auth"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func usageEntryAt(ts time.Time, alias, model string, tokens int) UsageEntry {
+	return UsageEntry{Timestamp: ts, Alias: alias, Model: model, TotalTokens: tokens, CompletionTokens: tokens, Status: http.StatusOK, LatencyMS: 100}
+}
+
+func TestMemoryUsageLedger_DropsOldest(t *testing.T) {
+	l := &MemoryUsageLedger{capacity: 2}
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		_ = l.Record(context.Background(), usageEntryAt(base.Add(time.Duration(i)*time.Minute), "a", "m", i+1))
+	}
+
+	var got []int
+	_ = l.Scan(context.Background(), UsageFilter{From: base, To: base.Add(time.Hour)}, func(e UsageEntry) error {
+		got = append(got, e.TotalTokens)
+		return nil
+	})
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("got %v, want the two newest entries oldest first", got)
+	}
+}
+
+func TestSummarizeUsage_GroupsAndFilters(t *testing.T) {
+	l := NewMemoryUsageLedger()
+	day1 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	for _, e := range []UsageEntry{
+		usageEntryAt(day1, "team-a", "m1", 10),
+		usageEntryAt(day1, "team-a", "m1", 5),
+		usageEntryAt(day1, "team-b", "m1", 7),
+		usageEntryAt(day2, "team-a", "m2", 3),
+		usageEntryAt(day2.Add(24*time.Hour), "team-a", "m1", 100), // outside the range
+	} {
+		_ = l.Record(context.Background(), e)
+	}
+	f := UsageFilter{From: day1.Add(-time.Hour), To: day2.Add(time.Hour)}
+
+	rows, err := summarizeUsage(context.Background(), l, f, []string{usageGroupDay, usageGroupAlias})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UsageRow{
+		{Day: "2026-10-01", Alias: "team-a", Requests: 2, TotalTokens: 15},
+		{Day: "2026-10-01", Alias: "team-b", Requests: 1, TotalTokens: 7},
+		{Day: "2026-10-02", Alias: "team-a", Requests: 1, TotalTokens: 3},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows %+v, want %d", len(rows), rows, len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Day != w.Day || r.Alias != w.Alias || r.Model != "" || r.Requests != w.Requests || r.TotalTokens != w.TotalTokens {
+			t.Errorf("row %d: got %+v, want %+v", i, r, w)
+		}
+	}
+
+	f.Alias = "team-a"
+	rows, _ = summarizeUsage(context.Background(), l, f, nil)
+	if len(rows) != 1 || rows[0].Requests != 3 || rows[0].TotalTokens != 18 || rows[0].AvgLatencyMS != 100 {
+		t.Fatalf("alias filter, no grouping: got %+v", rows)
+	}
+}
+
+func TestCompletionHandler_RecordsUsageEntry(t *testing.T) {
+	usage := NewMemoryUsageLedger()
+	s := NewServer(Dependencies{
+		Auth:       fakeAuth{},
+		Cache:      &fakeCache{},
+		Completion: newPricedCompletion(),
+		Pricing:    testPricing,
+		Usage:      usage,
+	})
+
+	if rec := doChatRequest(t, s, budgetTestBody); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	var entries []UsageEntry
+	_ = usage.Scan(context.Background(), UsageFilter{To: time.Now().Add(time.Minute)}, func(e UsageEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Alias != "tester" || e.Model != "m" || e.Endpoint != "batch" || e.Path != "/v1/chat/completions" ||
+		e.PromptTokens != 1000 || e.CompletionTokens != 500 || e.TotalTokens != 1500 || e.Status != http.StatusOK ||
+		e.CacheHit || e.RAGUsed || e.CostUSD == 0 {
+		t.Fatalf("entry: got %+v", e)
+	}
+}
+
+func TestCompletionHandler_RecordsRejectedRequest(t *testing.T) {
+	budgets := NewMemoryBudgetStore()
+	_ = budgets.RecordUsage(context.Background(), budgetTestTokenID, BudgetUsage{Tokens: 1})
+	token := &auth.TokenInfo{Alias: "tester", ID: budgetTestTokenID, Budget: &auth.Budget{HardLimitTokens: 1}}
+	usage := NewMemoryUsageLedger()
+	s := NewServer(Dependencies{Auth: fakeAuth{info: token}, Cache: &fakeCache{}, Completion: &fakeCompletion{}, Budgets: budgets, Usage: usage})
+
+	doChatRequest(t, s, budgetTestBody)
+	rows, _ := summarizeUsage(context.Background(), usage, UsageFilter{To: time.Now().Add(time.Minute)}, nil)
+	if len(rows) != 1 || rows[0].Errors != 1 || rows[0].TotalTokens != 0 {
+		t.Fatalf("got %+v, want one errored request with no tokens", rows)
+	}
+}
+
+func newUsageAdminMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	usage := NewMemoryUsageLedger()
+	day := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	_ = usage.Record(context.Background(), usageEntryAt(day, "team-a", "m1", 10))
+	_ = usage.Record(context.Background(), usageEntryAt(day, "team-b", "m1", 7))
+	_, mux := newAdminTestServer(t, Dependencies{Usage: usage})
+	return mux
+}
+
+func TestAdminUsage_Report(t *testing.T) {
+	mux := newUsageAdminMux(t)
+
+	req := httptest.NewRequest("GET", "/admin/usage?from=2026-10-01&to=2026-10-02&group_by=alias", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	var report usageReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Alias != "team-a" || report.Rows[0].TotalTokens != 10 {
+		t.Fatalf("rows: got %+v", report.Rows)
+	}
+
+	for _, q := range []string{"group_by=week", "from=yesterday", "from=2026-10-02&to=2026-10-01"} {
+		req := httptest.NewRequest("GET", "/admin/usage?"+q, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status=%d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestAdminUsage_ExportCSVAndJSONL(t *testing.T) {
+	mux := newUsageAdminMux(t)
+
+	req := httptest.NewRequest("GET", "/admin/usage/export?from=2026-10-01&to=2026-10-02&alias=team-b", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("csv: status=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][0] != "timestamp" || records[1][2] != "team-b" {
+		t.Fatalf("csv: got %v, want a header and team-b's entry", records)
+	}
+
+	req = httptest.NewRequest("GET", "/admin/usage/export?format=jsonl&from=2026-10-01&to=2026-10-02", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("jsonl: got %d lines: %s", len(lines), w.Body.String())
+	}
+	var e UsageEntry
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil || e.Alias != "team-a" {
+		t.Fatalf("jsonl: got %+v, %v", e, err)
+	}
+}
+
+func TestAdminUsage_ExportLedgerDownReturnsError(t *testing.T) {
+	rdb := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	_, mux := newAdminTestServer(t, Dependencies{Usage: NewRedisUsageLedger(rdb, time.Hour)})
+
+	req := httptest.NewRequest("GET", "/admin/usage/export", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
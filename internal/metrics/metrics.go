@@ -12,8 +12,12 @@
 //   - outcome       enum: ok|error|canceled
 //   - result        enum: hit|miss
 //   - kind          enum: pre_stream|mid_stream
-//   - path          gateway HTTP route path (whitelisted; today only /v1/chat/completions)
+//   - path          gateway HTTP route pattern as registered on the ServeMux
 //   - status        HTTP status code (small int range)
+//   - class         enum: completion/pool errorClass (timeout_idle, http_5xx, ...)
+//   - alias         API-token alias (one per tenant or service, issued by admins);
+//                   callers authenticated by an auth provider (OIDC) are
+//                   collapsed into the provider's name, never their subject
 //
 // FORBIDDEN labels (high or unbounded cardinality, or PII):
 //   - prompt / question / message body
@@ -98,6 +102,78 @@ var (
 	)
 )
 
+// Per-alias rate-limit decisions, split by the backend that made them so a
+// Redis outage (decisions falling back to "local") is visible.
+var (
+	RateLimitDecisions = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_rate_limit_decisions_total",
+			Help: "Rate-limit admission decisions made by the gateway, partitioned by backend and result.",
+		},
+		[]string{"backend", "result"}, // backend: local | redis; result: allowed | rejected_requests | rejected_concurrency
+	)
+
+	TokenRateLimitDecisions = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_token_rate_limit_decisions_total",
+			Help: "Token pre-charge decisions for requests under a tokens-per-minute limit, partitioned by backend and result.",
+		},
+		[]string{"backend", "result"}, // backend: local | redis; result: allowed | rejected_key | rejected_model
+	)
+)
+
+// Gateway auth cache lookups and the invalidations pushed to it. A falling hit
+// rate under steady traffic points at a TTL or size that is too small.
+var (
+	AuthCacheLookups = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_auth_cache_lookups_total",
+			Help: "Token lookups answered by the gateway auth cache, partitioned by result.",
+		},
+		[]string{"result"}, // hit | negative_hit | miss
+	)
+
+	AuthCacheInvalidations = promauto.With(Registry).NewCounter(
+		prometheus.CounterOpts{
+			Name: "gateway_auth_cache_invalidations_total",
+			Help: "Cached token lookups dropped because the token was revoked or changed.",
+		},
+	)
+)
+
+// Budget checks for tokens that have a budget; "error" counts store failures
+// that let the request through unchecked.
+var (
+	BudgetChecks = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_budget_checks_total",
+			Help: "Spend budget checks made by the gateway, partitioned by result.",
+		},
+		[]string{"result"}, // allowed | soft_limit | rejected | error
+	)
+)
+
+// Per-request usage and cost, by model and alias. Cost is only counted for
+// models in the gateway's price list. The alias label is bounded as described
+// in the label rules above.
+var (
+	RequestTokens = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_request_tokens_total",
+			Help: "Tokens reported by the upstream for completed requests, partitioned by model, alias and type.",
+		},
+		[]string{"model", "alias", "type"}, // type: prompt | cached_prompt | completion
+	)
+
+	RequestCostUSD = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gateway_request_cost_usd_total",
+			Help: "Cost in USD of completed requests under the gateway's price list, partitioned by model and alias.",
+		},
+		[]string{"model", "alias"},
+	)
+)
+
 // Upstream stream-level errors. Mid-stream errors are particularly interesting
 // because they cannot be auto-retried by the pool (the channel has already
 // been returned upstream).
@@ -111,6 +187,19 @@ var (
 	)
 )
 
+// Completion-service upstream failures per pool endpoint. class is the
+// closed enum from completion/pool errorClass; each upstream timeout phase
+// has its own class so a dial problem is not confused with a stalled stream.
+var (
+	CompletionUpstreamErrors = promauto.With(Registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "completion_upstream_errors_total",
+			Help: "Upstream completion failures per endpoint, split by error class and where in the lifecycle they occurred.",
+		},
+		[]string{"endpoint", "class", "kind"}, // kind: pre_stream | mid_stream
+	)
+)
+
 func init() {
 	Registry.MustRegister(GRPCServer, GRPCClient)
 	Registry.MustRegister(collectors.NewGoCollector())